@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -263,6 +264,44 @@ func TestClean_MethodBuiltin_TargetNilResultWithError(t *testing.T) {
 	assert.Contains(t, result.Errors[0], "scanner failed")
 }
 
+func TestClean_MethodCommand_DelegatesToTarget(t *testing.T) {
+	registry := target.NewRegistry()
+	cat := types.Category{
+		ID:      "go-cache",
+		Name:    "Go Cache",
+		Method:  types.MethodCommand,
+		Command: []string{"go", "clean", "-cache"},
+	}
+
+	mockTarget := newMockTargetWithCategory(cat)
+	cleanResult := types.NewCleanResult(cat)
+	cleanResult.CleanedItems = 1
+	cleanResult.FreedSpace = 500
+	cleanResult.Output = "done"
+	mockTarget.On("Clean", mock.Anything).Return(cleanResult, nil)
+	registry.Register(mockTarget)
+
+	c := NewExecutor(registry)
+	items := []types.CleanableItem{{Path: "command:go-cache", Name: "Go Cache", Size: 500}}
+
+	result := c.Command(cat, items)
+
+	mockTarget.AssertCalled(t, "Clean", items)
+	assert.Equal(t, 1, result.CleanedItems)
+	assert.Equal(t, "done", result.Output)
+}
+
+func TestClean_MethodCommand_MethodMismatch(t *testing.T) {
+	c := NewExecutor(target.NewRegistry())
+	cat := types.Category{ID: "cache", Name: "Cache", Method: types.MethodTrash}
+
+	result := c.Command(cat, []types.CleanableItem{{Path: "/tmp/a"}})
+
+	assert.Equal(t, 1, result.SkippedItems)
+	require.Len(t, result.Errors, 1)
+	assert.Contains(t, result.Errors[0], "method mismatch")
+}
+
 func TestClean_Manual_SkipsWithGuide(t *testing.T) {
 	c := NewExecutor(nil)
 
@@ -353,3 +392,58 @@ func TestClean_Trash_PartialFailure(t *testing.T) {
 	require.Len(t, result.Errors, 1)
 	assert.Contains(t, result.Errors[0], "permission denied")
 }
+
+// scanNestedCategories scans a "caches" category whose only item holds a
+// directory owned by the more specific "chrome" category.
+func scanNestedCategories(t *testing.T, method types.CleanupMethod) (string, types.Category, *types.ScanResult) {
+	t.Helper()
+	caches := t.TempDir()
+	for _, p := range []string{"Google/Chrome/Default/blob", "Google/other", "Google/Drive/state"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(caches, filepath.Dir(p)), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(caches, p), []byte("data"), 0o644))
+	}
+	cachesCat := types.Category{ID: "caches", Method: method, Safety: types.SafetyLevelSafe, Paths: []string{filepath.Join(caches, "*")}}
+	cfg := &types.Config{Categories: []types.Category{
+		cachesCat,
+		{ID: "chrome", Method: method, Safety: types.SafetyLevelSafe, Paths: []string{filepath.Join(caches, "Google", "Chrome", "*")}},
+	}}
+	registry, err := target.DefaultRegistry(cfg)
+	require.NoError(t, err)
+	tgt, _ := registry.Get("caches")
+	result, err := tgt.Scan()
+	require.NoError(t, err)
+	require.Len(t, result.Items, 1)
+	return caches, cachesCat, result
+}
+
+func TestClean_Trash_LeavesPathsOwnedByOtherCategories(t *testing.T) {
+	original := utils.MoveToTrashBatch
+	defer func() { utils.MoveToTrashBatch = original }()
+	var trashedPaths []string
+	utils.MoveToTrashBatch = func(paths []string) utils.TrashBatchResult {
+		trashedPaths = append(trashedPaths, paths...)
+		return utils.TrashBatchResult{Succeeded: paths, Failed: make(map[string]error)}
+	}
+	caches, cat, scanned := scanNestedCategories(t, types.MethodTrash)
+
+	result := NewExecutor(nil).Trash(cat, scanned.Items)
+
+	assert.Equal(t, 1, result.CleanedItems)
+	assert.Equal(t, scanned.Items[0].Size, result.FreedSpace)
+	assert.ElementsMatch(t, []string{
+		filepath.Join(caches, "Google", "other"),
+		filepath.Join(caches, "Google", "Drive"),
+	}, trashedPaths)
+}
+
+func TestClean_Permanent_LeavesPathsOwnedByOtherCategories(t *testing.T) {
+	caches, cat, scanned := scanNestedCategories(t, types.MethodPermanent)
+
+	result := NewExecutor(nil).Permanent(cat, scanned.Items)
+
+	assert.Equal(t, 1, result.CleanedItems)
+	assert.Empty(t, result.Errors)
+	assert.FileExists(t, filepath.Join(caches, "Google", "Chrome", "Default", "blob"))
+	assert.NoFileExists(t, filepath.Join(caches, "Google", "other"))
+	assert.NoFileExists(t, filepath.Join(caches, "Google", "Drive"))
+}
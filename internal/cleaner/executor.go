@@ -43,6 +43,21 @@ func (c *Executor) Builtin(cat types.Category, items []types.CleanableItem) *typ
 		return result
 	}
 
+	return c.targetClean(cat, items, result)
+}
+
+// Command runs the category's cleanup command through its registered target.
+func (c *Executor) Command(cat types.Category, items []types.CleanableItem) *types.CleanResult {
+	result := types.NewCleanResult(cat)
+	if !c.ensureMethod(cat, types.MethodCommand, result, items) {
+		return result
+	}
+
+	return c.targetClean(cat, items, result)
+}
+
+// targetClean delegates cleanup to the registered target's own Clean implementation.
+func (c *Executor) targetClean(cat types.Category, items []types.CleanableItem, result *types.CleanResult) *types.CleanResult {
 	t, ok := c.registry.Get(cat.ID)
 	if !ok {
 		result.Errors = append(result.Errors, "target not found: "+cat.ID)
@@ -102,6 +117,20 @@ func (c *Executor) moveToTrash(items []types.CleanableItem, result *types.CleanR
 	result.Merge(batchResult)
 }
 
+// removeAround deletes a directory item's contents except its KeepPaths.
+func removeAround(item types.CleanableItem) error {
+	paths, err := utils.PathsAround(item.Path, item.KeepPaths)
+	if err != nil {
+		return err
+	}
+	for _, p := range paths {
+		if err := os.RemoveAll(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (c *Executor) removePermanent(items []types.CleanableItem, result *types.CleanResult) {
 	sipSkipped := 0
 
@@ -114,9 +143,12 @@ func (c *Executor) removePermanent(items []types.CleanableItem, result *types.Cl
 		}
 
 		var err error
-		if item.IsDirectory {
+		switch {
+		case len(item.KeepPaths) > 0:
+			err = removeAround(item)
+		case item.IsDirectory:
 			err = os.RemoveAll(item.Path)
-		} else {
+		default:
 			err = os.Remove(item.Path)
 		}
 
@@ -1,9 +1,12 @@
 package cleaner
 
 import (
+	"fmt"
+	"path/filepath"
 	"strings"
 
 	"github.com/2ykwang/mac-cleanup-go/internal/logger"
+	"github.com/2ykwang/mac-cleanup-go/internal/policy"
 	"github.com/2ykwang/mac-cleanup-go/internal/target"
 	"github.com/2ykwang/mac-cleanup-go/internal/types"
 	"github.com/2ykwang/mac-cleanup-go/internal/utils"
@@ -18,6 +21,7 @@ type CleanJob struct {
 // CleanService orchestrates the cleaning process.
 type CleanService struct {
 	executor *Executor
+	policy   *policy.Policy
 }
 
 // NewCleanService creates a new CleanService.
@@ -27,34 +31,49 @@ func NewCleanService(registry *target.Registry) *CleanService {
 	}
 }
 
+// SetPolicy makes Clean keep everything under the policy's protected paths.
+// Scans already hide protected items, but builtin and command targets clean
+// more than the items they list.
+func (s *CleanService) SetPolicy(p *policy.Policy) {
+	s.policy = p
+}
+
 // Clean executes the cleaning jobs and reports progress via callbacks.
 func (s *CleanService) Clean(jobs []CleanJob, callbacks types.CleanCallbacks) *types.Report {
 	report := &types.Report{Results: make([]types.CleanResult, 0)}
 
+	kept := make([]CleanJob, len(jobs))
+	protected := make([][]string, len(jobs))
 	totalItems := 0
-	for _, job := range jobs {
-		totalItems += len(job.Items)
+	for i, job := range jobs {
+		kept[i], protected[i] = s.dropProtected(job)
+		totalItems += len(kept[i].Items)
 	}
 
 	logger.Info("clean started", "jobs", len(jobs), "totalItems", totalItems)
 
 	currentItem := 0
 
-	for _, job := range jobs {
+	for i, job := range kept {
 		logger.Debug("processing job", "category", job.Category.Name, "method", job.Category.Method, "items", len(job.Items))
 
 		var result *types.CleanResult
 
-		switch job.Category.Method {
-		case types.MethodBuiltin:
-			result = s.cleanBuiltin(job, callbacks, &currentItem, totalItems)
-		case types.MethodTrash:
+		switch {
+		case len(job.Items) == 0:
+			result = types.NewCleanResult(job.Category)
+		case job.Category.Method == types.MethodBuiltin:
+			result = s.cleanBuiltin(job, callbacks, &currentItem, totalItems, s.executor.Builtin)
+		case job.Category.Method == types.MethodCommand:
+			result = s.cleanBuiltin(job, callbacks, &currentItem, totalItems, s.executor.Command)
+		case job.Category.Method == types.MethodTrash:
 			result = s.cleanTrashBatch(job, callbacks, &currentItem, totalItems)
-		case types.MethodPermanent:
+		case job.Category.Method == types.MethodPermanent:
 			result = s.cleanByItem(job, callbacks, &currentItem, totalItems, s.executor.Permanent)
 		default:
 			result = s.cleanUnsupported(job)
 		}
+		result.Errors = append(result.Errors, protected[i]...)
 
 		if result != nil {
 			report.Results = append(report.Results, *result)
@@ -149,7 +168,7 @@ func buildJob(
 	excludedMap := excluded[id]
 	var items []types.CleanableItem
 	for _, item := range r.Items {
-		if item.Status == types.ItemStatusProcessLocked {
+		if !item.Status.Cleanable() {
 			continue
 		}
 		if excludedMap == nil || !excludedMap[item.Path] {
@@ -163,8 +182,45 @@ func buildJob(
 	return CleanJob{Category: r.Category, Items: items}, true
 }
 
-// cleanBuiltin handles builtin methods (docker, brew) with category-level progress.
-func (s *CleanService) cleanBuiltin(job CleanJob, callbacks types.CleanCallbacks, currentItem *int, totalItems int) *types.CleanResult {
+// dropProtected removes the job's items under a protected path and returns
+// an error message for each. A command job is dropped whole when any of its
+// paths is protected, since the command cleans them all.
+func (s *CleanService) dropProtected(job CleanJob) (CleanJob, []string) {
+	if s.policy == nil {
+		return job, nil
+	}
+
+	if job.Category.Method == types.MethodCommand {
+		for _, p := range target.ResolvePaths(job.Category) {
+			p = utils.ExpandPath(p)
+			for strings.ContainsAny(p, "*?[") {
+				p = filepath.Dir(p)
+			}
+			if rule := s.policy.ProtectedRule(p); rule != "" {
+				logger.Warn("command skipped: path protected by policy", "category", job.Category.ID, "path", p, "rule", rule)
+				job.Items = nil
+				return job, []string{fmt.Sprintf("protected by policy (%s), command not run: %s", rule, p)}
+			}
+		}
+	}
+
+	var kept []types.CleanableItem
+	var msgs []string
+	for _, item := range job.Items {
+		if rule := s.policy.ProtectedRule(item.Path); rule != "" {
+			logger.Warn("item skipped: protected by policy", "path", item.Path, "rule", rule)
+			msgs = append(msgs, fmt.Sprintf("protected by policy (%s), kept %s", rule, item.Path))
+			continue
+		}
+		kept = append(kept, item)
+	}
+	job.Items = kept
+	return job, msgs
+}
+
+// cleanBuiltin handles methods cleaned by their target as a whole (docker, brew, command)
+// with category-level progress.
+func (s *CleanService) cleanBuiltin(job CleanJob, callbacks types.CleanCallbacks, currentItem *int, totalItems int, exec itemCleaner) *types.CleanResult {
 	if callbacks.OnProgress != nil {
 		callbacks.OnProgress(types.CleanProgress{
 			CategoryName: job.Category.Name,
@@ -174,7 +230,7 @@ func (s *CleanService) cleanBuiltin(job CleanJob, callbacks types.CleanCallbacks
 		})
 	}
 
-	result := s.executor.Builtin(job.Category, job.Items)
+	result := exec(job.Category, job.Items)
 	*currentItem += len(job.Items)
 	return result
 }
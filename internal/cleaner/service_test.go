@@ -8,6 +8,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/2ykwang/mac-cleanup-go/internal/mocks"
+	"github.com/2ykwang/mac-cleanup-go/internal/policy"
 	"github.com/2ykwang/mac-cleanup-go/internal/target"
 	"github.com/2ykwang/mac-cleanup-go/internal/types"
 	"github.com/2ykwang/mac-cleanup-go/internal/utils"
@@ -213,6 +214,23 @@ func TestPrepareJobs_SkipsLockedItems(t *testing.T) {
 	}
 }
 
+func TestPrepareJobs_SkipsRetainedItems(t *testing.T) {
+	service := NewCleanService(target.NewRegistry())
+
+	resultMap := map[string]*types.ScanResult{
+		"cat1": newTestScanResult("cat1", "Category 1", types.MethodTrash, []types.CleanableItem{
+			{Path: "/path1", Name: "path1", Status: types.ItemStatusRetained},
+			{Path: "/path2", Name: "path2"},
+		}),
+	}
+
+	jobs := service.PrepareJobs(resultMap, map[string]bool{"cat1": true}, nil)
+
+	require.Len(t, jobs, 1)
+	require.Len(t, jobs[0].Items, 1)
+	assert.Equal(t, "/path2", jobs[0].Items[0].Path)
+}
+
 func TestPrepareJobs_SkipsWhenAllItemsLocked(t *testing.T) {
 	service := NewCleanService(target.NewRegistry())
 
@@ -1033,3 +1051,54 @@ func TestClean_ReturnsCorrectReport(t *testing.T) {
 	assert.Equal(t, int64(3000), report.Results[0].FreedSpace)
 	assert.Equal(t, 2, report.Results[0].CleanedItems)
 }
+
+func TestClean_SkipsProtectedItems(t *testing.T) {
+	registry := target.NewRegistry()
+	cat := types.Category{ID: "docker", Name: "Docker", Method: types.MethodBuiltin}
+
+	mockTarget := newMockTargetForService(cat)
+	cleanResult := types.NewCleanResult(cat)
+	cleanResult.CleanedItems = 1
+	mockTarget.On("Clean", mock.MatchedBy(func(items []types.CleanableItem) bool {
+		return len(items) == 1 && items[0].Path == "/data/tmp"
+	})).Return(cleanResult, nil)
+	registry.Register(mockTarget)
+
+	service := NewCleanService(registry)
+	service.SetPolicy(&policy.Policy{ProtectedPaths: []string{"/data/keep"}})
+
+	jobs := []CleanJob{{Category: cat, Items: newTestItems("/data/keep/a", "/data/tmp")}}
+	report := service.Clean(jobs, types.CleanCallbacks{})
+
+	mockTarget.AssertNumberOfCalls(t, "Clean", 1)
+	require.Len(t, report.Results, 1)
+	assert.Equal(t, 1, report.CleanedItems)
+	assert.Equal(t, 1, report.FailedItems)
+	assert.Contains(t, report.Results[0].Errors[0], "/data/keep/a")
+}
+
+func TestClean_SkipsCommandWithProtectedPath(t *testing.T) {
+	registry := target.NewRegistry()
+	cat := types.Category{
+		ID:      "go-cache",
+		Name:    "Go Cache",
+		Method:  types.MethodCommand,
+		Command: []string{"go", "clean", "-cache"},
+		Paths:   []string{"/data/keep/go-build/*"},
+	}
+
+	mockTarget := newMockTargetForService(cat)
+	registry.Register(mockTarget)
+
+	service := NewCleanService(registry)
+	service.SetPolicy(&policy.Policy{ProtectedPaths: []string{"/data/keep"}})
+
+	jobs := []CleanJob{{Category: cat, Items: newTestItems("command:go-cache")}}
+	report := service.Clean(jobs, types.CleanCallbacks{})
+
+	mockTarget.AssertNotCalled(t, "Clean", mock.Anything)
+	require.Len(t, report.Results, 1)
+	assert.Equal(t, 0, report.CleanedItems)
+	require.Len(t, report.Results[0].Errors, 1)
+	assert.Contains(t, report.Results[0].Errors[0], "command not run")
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/2ykwang/mac-cleanup-go/internal/cleaner"
+	"github.com/2ykwang/mac-cleanup-go/internal/policy"
 	"github.com/2ykwang/mac-cleanup-go/internal/target"
 	"github.com/2ykwang/mac-cleanup-go/internal/types"
 	"github.com/2ykwang/mac-cleanup-go/internal/userconfig"
@@ -24,18 +25,28 @@ type Runner struct {
 	cfg      *types.Config
 	registry *target.Registry
 	userCfg  *userconfig.UserConfig
+	policy   *policy.Policy
+	removals []policy.Removal
 }
 
-// NewRunner creates a Runner with a default registry.
+// NewRunner creates a Runner with a default registry. The organization
+// policy, if installed, is applied before the registry is built.
 func NewRunner(cfg *types.Config, userCfg *userconfig.UserConfig) (*Runner, error) {
 	if cfg == nil {
 		return nil, ErrNilRunnerConfig
 	}
+	pol, err := policy.Load()
+	if err != nil {
+		return nil, err
+	}
+	removals := pol.Apply(cfg)
+	userCfg.Apply(cfg)
+	cfg = target.ExpandConfig(cfg)
 	registry, err := target.DefaultRegistry(cfg)
 	if err != nil {
 		return nil, err
 	}
-	return &Runner{cfg: cfg, registry: registry, userCfg: userCfg}, nil
+	return &Runner{cfg: cfg, registry: registry, userCfg: userCfg, policy: pol, removals: removals}, nil
 }
 
 // NewRunnerWithRegistry creates a Runner with a custom registry (for tests).
@@ -56,25 +67,37 @@ func (r *Runner) Run(dryRun bool) (*types.Report, []string, error) {
 	}
 
 	selectedIDs := r.userCfg.GetSelectedTargets()
-	if len(selectedIDs) == 0 {
-		return nil, nil, ErrNoSelection
-	}
-
 	selectedSet := make(map[string]bool, len(selectedIDs))
 	for _, id := range selectedIDs {
 		selectedSet[id] = true
 	}
+	for _, id := range r.policy.MandatoryIDs() {
+		selectedSet[id] = true
+	}
+	if len(selectedSet) == 0 {
+		return nil, nil, ErrNoSelection
+	}
 
 	resultMap := make(map[string]*types.ScanResult)
 	selected := make(map[string]bool)
 	var selectedOrder []string
 	var warnings []string
 
+	for _, removal := range r.removals {
+		if selectedSet[removal.CategoryID] {
+			warnings = append(warnings, fmt.Sprintf("skipped target removed by policy: %s (%s)", removal.CategoryName, removal.Rule))
+		}
+	}
+
 	for _, cat := range r.cfg.Categories {
 		if !selectedSet[cat.ID] {
 			continue
 		}
-		if cat.Safety == types.SafetyLevelRisky {
+		if rule := r.policy.RemovalRule(cat); rule != "" {
+			warnings = append(warnings, fmt.Sprintf("skipped target removed by policy: %s (%s)", cat.Name, rule))
+			continue
+		}
+		if cat.Safety == types.SafetyLevelRisky && !r.policy.IsMandatory(cat.ID) {
 			warnings = append(warnings, fmt.Sprintf("skipped risky target: %s", cat.Name))
 			continue
 		}
@@ -82,6 +105,10 @@ func (r *Runner) Run(dryRun bool) (*types.Report, []string, error) {
 			warnings = append(warnings, fmt.Sprintf("skipped manual target: %s", cat.Name))
 			continue
 		}
+		if ceiling := r.userCfg.GetMaxSafety(); cat.Safety.Exceeds(ceiling) && !r.policy.IsMandatory(cat.ID) {
+			warnings = append(warnings, fmt.Sprintf("skipped target above %s safety ceiling: %s", ceiling, cat.Name))
+			continue
+		}
 
 		tgt, ok := r.registry.Get(cat.ID)
 		if !ok {
@@ -96,6 +123,9 @@ func (r *Runner) Run(dryRun bool) (*types.Report, []string, error) {
 		if result == nil {
 			continue
 		}
+		if n := r.policy.FilterProtected(result); n > 0 {
+			warnings = append(warnings, fmt.Sprintf("skipped %d item(s) protected by policy: %s", n, cat.Name))
+		}
 		resultMap[cat.ID] = result
 		selected[cat.ID] = true
 		selectedOrder = append(selectedOrder, cat.ID)
@@ -106,6 +136,7 @@ func (r *Runner) Run(dryRun bool) (*types.Report, []string, error) {
 	}
 
 	cleanService := cleaner.NewCleanService(r.registry)
+	cleanService.SetPolicy(r.policy)
 	jobs := cleanService.PrepareJobsWithOrder(resultMap, selected, r.userCfg.ExcludedPathsMap(), selectedOrder)
 
 	start := time.Now()
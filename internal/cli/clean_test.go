@@ -8,6 +8,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/2ykwang/mac-cleanup-go/internal/policy"
 	"github.com/2ykwang/mac-cleanup-go/internal/types"
 	"github.com/2ykwang/mac-cleanup-go/internal/userconfig"
 )
@@ -107,3 +108,106 @@ func TestRunner_Run_NoSelection(t *testing.T) {
 	assert.Nil(t, report)
 	assert.Nil(t, warnings)
 }
+
+func TestRunner_Run_Profile_SkipsTargetsAboveCeiling(t *testing.T) {
+	tmpDir := t.TempDir()
+	safeFile := filepath.Join(tmpDir, "safe.log")
+	moderateFile := filepath.Join(tmpDir, "moderate.log")
+	require.NoError(t, os.WriteFile(safeFile, []byte("safe"), 0o644))
+	require.NoError(t, os.WriteFile(moderateFile, []byte("moderate"), 0o644))
+
+	cfg := &types.Config{
+		Categories: []types.Category{
+			{ID: "logs", Name: "Logs", Safety: types.SafetyLevelSafe, Method: types.MethodTrash, Paths: []string{safeFile}},
+			{ID: "caches", Name: "Caches", Safety: types.SafetyLevelModerate, Method: types.MethodTrash, Paths: []string{moderateFile}},
+		},
+	}
+
+	userCfg := &userconfig.UserConfig{
+		SelectedTargets: []string{"caches"},
+		Profiles: map[string]*userconfig.Profile{
+			"daily": {SelectedTargets: []string{"logs", "caches"}, MaxSafety: types.SafetyLevelSafe},
+		},
+	}
+	userCfg.UseProfile("daily")
+
+	runner, err := NewRunner(cfg, userCfg)
+	require.NoError(t, err)
+
+	report, warnings, err := runner.Run(true)
+	require.NoError(t, err)
+	require.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0], "above safe safety ceiling: Caches")
+	require.Len(t, report.Results, 1)
+	assert.Equal(t, "logs", report.Results[0].Category.ID)
+}
+
+func TestRunner_Run_EnforcesPolicy(t *testing.T) {
+	tmpDir := t.TempDir()
+	logFile := filepath.Join(tmpDir, "app.log")
+	keepFile := filepath.Join(tmpDir, "keep.log")
+	cacheFile := filepath.Join(tmpDir, "cache.bin")
+	for _, f := range []string{logFile, keepFile, cacheFile} {
+		require.NoError(t, os.WriteFile(f, []byte("data"), 0o644))
+	}
+
+	policyPath := filepath.Join(tmpDir, "policy.yaml")
+	require.NoError(t, os.WriteFile(policyPath, []byte(
+		"forbidden: [caches]\nmandatory: [logs]\nprotected_paths: ["+keepFile+"]\n"), 0o644))
+	t.Setenv(policy.EnvVar, policyPath)
+
+	cfg := &types.Config{
+		Categories: []types.Category{
+			{ID: "logs", Name: "Logs", Safety: types.SafetyLevelSafe, Method: types.MethodTrash, Paths: []string{filepath.Join(tmpDir, "*.log")}},
+			{ID: "caches", Name: "Caches", Safety: types.SafetyLevelSafe, Method: types.MethodTrash, Paths: []string{cacheFile}},
+		},
+	}
+
+	userCfg := &userconfig.UserConfig{SelectedTargets: []string{"caches"}}
+
+	runner, err := NewRunner(cfg, userCfg)
+	require.NoError(t, err)
+
+	report, warnings, err := runner.Run(true)
+	require.NoError(t, err)
+	require.Len(t, warnings, 1)
+	assert.Equal(t, "skipped target removed by policy: Caches (forbidden by policy)", warnings[0])
+	require.Len(t, report.Results, 1)
+	assert.Equal(t, "logs", report.Results[0].Category.ID, "mandatory target runs without being selected")
+	assert.Equal(t, 1, report.CleanedItems, "protected path is never cleaned")
+}
+
+func TestRunner_Run_MandatoryRiskyTargetRuns(t *testing.T) {
+	tmpDir := t.TempDir()
+	dataFile := filepath.Join(tmpDir, "data.bin")
+	require.NoError(t, os.WriteFile(dataFile, []byte("data"), 0o644))
+
+	policyPath := filepath.Join(tmpDir, "policy.yaml")
+	require.NoError(t, os.WriteFile(policyPath, []byte("mandatory: [risky]\n"), 0o644))
+	t.Setenv(policy.EnvVar, policyPath)
+
+	cfg := &types.Config{
+		Categories: []types.Category{
+			{ID: "risky", Name: "Risky", Safety: types.SafetyLevelRisky, Method: types.MethodTrash, Paths: []string{dataFile}},
+		},
+	}
+
+	runner, err := NewRunner(cfg, &userconfig.UserConfig{})
+	require.NoError(t, err)
+
+	report, warnings, err := runner.Run(true)
+	require.NoError(t, err)
+	assert.Empty(t, warnings)
+	require.Len(t, report.Results, 1)
+	assert.Equal(t, "risky", report.Results[0].Category.ID)
+}
+
+func TestNewRunner_InvalidPolicy(t *testing.T) {
+	policyPath := filepath.Join(t.TempDir(), "policy.yaml")
+	require.NoError(t, os.WriteFile(policyPath, []byte("max_safety: extreme\n"), 0o644))
+	t.Setenv(policy.EnvVar, policyPath)
+
+	_, err := NewRunner(&types.Config{}, &userconfig.UserConfig{})
+
+	assert.ErrorContains(t, err, "invalid max_safety")
+}
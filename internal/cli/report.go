@@ -17,8 +17,10 @@ import (
 
 const (
 	defaultReportWidth = 90
-	minBlockWidth      = 28
-	blockGap           = 2
+	// reportOutputLines caps how much command output a failed result shows.
+	reportOutputLines = 5
+	minBlockWidth     = 28
+	blockGap          = 2
 )
 
 // FormatReport renders a plain-text report for CLI output.
@@ -174,15 +176,19 @@ func renderDetailsTable(styles reportStyles, results []types.CleanResult, width
 	for _, r := range results {
 		status := statusLabel(r)
 		row := statusCol.Render(styles.Status(status)) +
-			gap + nameCol.Render(truncateText(r.Category.Name, nameW)) +
+			gap + nameCol.Render(truncateText(categoryLabel(r.Category), nameW)) +
 			gap + itemsCol.Render(strconv.Itoa(r.CleanedItems)) +
 			gap + sizeCol.Render(utils.FormatSize(r.FreedSpace))
 		b.WriteString(row + "\n")
 
+		// Command cleanups explain their failure in their own output.
 		if len(r.Errors) > 0 {
 			for _, err := range r.Errors {
 				b.WriteString(styles.Muted("  - "+truncateError(err, width-6)) + "\n")
 			}
+			for _, line := range r.OutputTail(reportOutputLines) {
+				b.WriteString(styles.Muted("  | "+truncateText(line, width-6)) + "\n")
+			}
 		}
 	}
 
@@ -193,17 +199,28 @@ func renderDetailsStack(styles reportStyles, results []types.CleanResult) string
 	var b strings.Builder
 	for _, r := range results {
 		status := styles.Status(statusLabel(r))
-		line := fmt.Sprintf("%s %s — %s (%d items)", status, r.Category.Name, utils.FormatSize(r.FreedSpace), r.CleanedItems)
+		line := fmt.Sprintf("%s %s — %s (%d items)", status, categoryLabel(r.Category), utils.FormatSize(r.FreedSpace), r.CleanedItems)
 		b.WriteString(line + "\n")
 		if len(r.Errors) > 0 {
 			for _, err := range r.Errors {
 				b.WriteString(styles.Muted("  - "+truncateError(err, 60)) + "\n")
 			}
+			for _, line := range r.OutputTail(reportOutputLines) {
+				b.WriteString(styles.Muted("  | "+truncateText(line, 60)) + "\n")
+			}
 		}
 	}
 	return b.String()
 }
 
+// categoryLabel returns the category name, tagged with its user target file when not built-in.
+func categoryLabel(cat types.Category) string {
+	if !cat.IsUserDefined() {
+		return cat.Name
+	}
+	return fmt.Sprintf("%s [%s]", cat.Name, cat.SourceLabel())
+}
+
 func statusLabel(r types.CleanResult) string {
 	if len(r.Errors) == 0 {
 		return "OK"
@@ -60,3 +60,57 @@ func TestFormatReport_IncludesGroups(t *testing.T) {
 	assert.True(t, strings.Contains(output, "Cache") || strings.Contains(output, "Logs"))
 	assert.Contains(t, output, "failed to remove")
 }
+
+func TestFormatReport_TagsUserDefinedCategories(t *testing.T) {
+	t.Setenv("COLUMNS", "100")
+	report := &types.Report{
+		FreedSpace:   1024,
+		CleanedItems: 1,
+		Results: []types.CleanResult{
+			{
+				Category:     types.Category{Name: "Team Cache", Source: "/home/u/.config/mac-cleanup-go/targets.d/team.yaml"},
+				CleanedItems: 1,
+				FreedSpace:   1024,
+			},
+		},
+	}
+
+	output := FormatReport(report, true, styles.New(true))
+
+	assert.Contains(t, output, "Team Cache [team.yaml]")
+}
+
+func TestFormatReport_ShowsCommandOutput(t *testing.T) {
+	t.Setenv("COLUMNS", "100")
+	report := &types.Report{
+		Results: []types.CleanResult{
+			{
+				Category: types.Category{Name: "pnpm store"},
+				Errors:   []string{"command failed: exit status 1: ERR_PNPM_LOCKED"},
+				Output:   "Removing unreferenced packages\nERR_PNPM_LOCKED store is in use",
+			},
+		},
+	}
+
+	output := FormatReport(report, false, styles.New(true))
+
+	assert.Contains(t, output, "Removing unreferenced packages")
+	assert.Contains(t, output, "ERR_PNPM_LOCKED store is in use")
+}
+
+func TestFormatReport_HidesOutputOfSucceededCommand(t *testing.T) {
+	t.Setenv("COLUMNS", "100")
+	report := &types.Report{
+		Results: []types.CleanResult{
+			{
+				Category:     types.Category{Name: "pnpm store"},
+				CleanedItems: 1,
+				Output:       "Removed 120 packages",
+			},
+		},
+	}
+
+	output := FormatReport(report, true, styles.New(true))
+
+	assert.NotContains(t, output, "Removed 120 packages")
+}
@@ -7,7 +7,6 @@ import (
 	"gopkg.in/yaml.v3"
 
 	"github.com/2ykwang/mac-cleanup-go/internal/logger"
-	"github.com/2ykwang/mac-cleanup-go/internal/target"
 	"github.com/2ykwang/mac-cleanup-go/internal/types"
 )
 
@@ -19,54 +18,80 @@ func LoadEmbedded() (*types.Config, error) {
 	return loadConfig(embeddedConfig)
 }
 
+// Load loads the embedded config merged with user target files
+// from ~/.config/mac-cleanup-go/targets.d/*.yaml.
+func Load() (*types.Config, error) {
+	dir, err := OverlayDir()
+	if err != nil {
+		logger.Warn("user targets skipped: no home directory", "error", err)
+		return LoadEmbedded()
+	}
+	return loadWithOverlays(embeddedConfig, dir)
+}
+
+// LoadFiles loads the embedded config merged with the given user target files only.
+// It is used to lint target files before they are installed into targets.d.
+func LoadFiles(paths []string) (*types.Config, error) {
+	cfg, err := parseConfig(embeddedConfig)
+	if err != nil {
+		return nil, err
+	}
+	return validateOverlaid(cfg, applyOverlayFiles(cfg, paths))
+}
+
 func loadConfig(data []byte) (*types.Config, error) {
-	var cfg types.Config
-	if err := yaml.Unmarshal(data, &cfg); err != nil {
+	cfg, err := parseConfig(data)
+	if err != nil {
 		return nil, err
 	}
-	if err := validateConfig(&cfg); err != nil {
+	if err := validateConfig(cfg); err != nil {
 		return nil, err
 	}
-	return &cfg, nil
+	return cfg, nil
 }
 
-// validateConfig validates the configuration for correctness
-func validateConfig(cfg *types.Config) error {
-	validMethods := map[types.CleanupMethod]bool{
-		types.MethodTrash:     true,
-		types.MethodPermanent: true,
-		types.MethodBuiltin:   true,
-		types.MethodManual:    true,
+func loadWithOverlays(data []byte, overlayDir string) (*types.Config, error) {
+	cfg, err := parseConfig(data)
+	if err != nil {
+		return nil, err
 	}
-	validSafety := map[types.SafetyLevel]bool{
-		types.SafetyLevelSafe:     true,
-		types.SafetyLevelModerate: true,
-		types.SafetyLevelRisky:    true,
+	return validateOverlaid(cfg, applyOverlayDir(cfg, overlayDir))
+}
+
+// validateOverlaid reports the user target files that were skipped together
+// with any problems in the config merged from the rest.
+func validateOverlaid(cfg *types.Config, skipped ValidationErrors) (*types.Config, error) {
+	if errs := append(skipped, Validate(cfg)...); len(errs) > 0 {
+		return nil, errs
 	}
+	logger.Info("config validated", "categories", len(cfg.Categories))
+	return cfg, nil
+}
 
-	for _, cat := range cfg.Categories {
-		// Validate method
-		if !validMethods[cat.Method] {
-			logger.Warn("config validation failed: invalid method",
-				"category", cat.ID, "method", cat.Method)
-			return fmt.Errorf("category '%s': invalid method '%s'", cat.ID, cat.Method)
-		}
+// rawConfig keeps categories as nodes so each one can record its line number.
+type rawConfig struct {
+	Categories []yaml.Node   `yaml:"categories"`
+	Groups     []types.Group `yaml:"groups"`
+}
 
-		// Validate safety
-		if !validSafety[cat.Safety] {
-			logger.Warn("config validation failed: invalid safety",
-				"category", cat.ID, "safety", cat.Safety)
-			return fmt.Errorf("category '%s': invalid safety '%s'", cat.ID, cat.Safety)
-		}
+func parseConfig(data []byte) (*types.Config, error) {
+	var raw rawConfig
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
 
-		// Method-specific validations
-		if cat.Method == types.MethodBuiltin && !target.IsBuiltinID(cat.ID) {
-			logger.Warn("config validation failed: unknown builtin ID",
-				"category", cat.ID)
-			return fmt.Errorf("category '%s': unknown builtin ID", cat.ID)
+	cfg := &types.Config{
+		Categories: make([]types.Category, 0, len(raw.Categories)),
+		Groups:     raw.Groups,
+	}
+	for i := range raw.Categories {
+		node := &raw.Categories[i]
+		var cat types.Category
+		if err := node.Decode(&cat); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", embeddedSourceName, node.Line, err)
 		}
+		cat.Line = node.Line
+		cfg.Categories = append(cfg.Categories, cat)
 	}
-
-	logger.Info("config validated", "categories", len(cfg.Categories))
-	return nil
+	return cfg, nil
 }
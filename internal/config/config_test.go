@@ -68,6 +68,7 @@ func TestLoadEmbedded_MethodsAreValid(t *testing.T) {
 		types.MethodPermanent: true,
 		types.MethodBuiltin:   true,
 		types.MethodManual:    true,
+		types.MethodCommand:   true,
 	}
 
 	for _, cat := range cfg.Categories {
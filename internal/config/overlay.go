@@ -0,0 +1,185 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/2ykwang/mac-cleanup-go/internal/logger"
+	"github.com/2ykwang/mac-cleanup-go/internal/types"
+)
+
+const (
+	userConfigDir  = ".config/mac-cleanup-go"
+	overlayDirName = "targets.d"
+)
+
+// Function variables for testing
+var osUserHomeDir = os.UserHomeDir
+
+// overlayFile is a user target file. Categories are kept as raw nodes so that
+// an entry for an existing ID only overrides the fields it actually sets.
+type overlayFile struct {
+	Categories []yaml.Node   `yaml:"categories"`
+	Groups     []types.Group `yaml:"groups"`
+}
+
+// overlayHeader holds the fields needed to decide how an overlay entry is merged.
+type overlayHeader struct {
+	ID       string `yaml:"id"`
+	Disabled bool   `yaml:"disabled"`
+}
+
+// OverlayDir returns the directory holding user target files.
+func OverlayDir() (string, error) {
+	home, err := osUserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, userConfigDir, overlayDirName), nil
+}
+
+// applyOverlayDir merges every *.yaml file in dir into cfg, in file name order.
+// A missing directory is not an error.
+func applyOverlayDir(cfg *types.Config, dir string) ValidationErrors {
+	files, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	if err != nil {
+		return ValidationErrors{{Source: dir, Message: err.Error()}}
+	}
+	return applyOverlayFiles(cfg, files)
+}
+
+// applyOverlayFiles merges the given user target files into cfg, in order.
+// A file that cannot be read or decoded is skipped whole and reported.
+func applyOverlayFiles(cfg *types.Config, files []string) ValidationErrors {
+	var errs ValidationErrors
+	for _, path := range files {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			var pathErr *os.PathError
+			if errors.As(err, &pathErr) {
+				err = pathErr.Err
+			}
+			errs = append(errs, ValidationError{Source: filepath.Base(path), Message: err.Error()})
+			logger.Warn("user targets skipped", "file", path, "error", err)
+			continue
+		}
+
+		next := &types.Config{
+			Categories: slices.Clone(cfg.Categories),
+			Groups:     slices.Clone(cfg.Groups),
+		}
+		if err := applyOverlay(next, data, path); err != nil {
+			errs = append(errs, *err)
+			logger.Warn("user targets skipped", "file", path, "error", err)
+			continue
+		}
+		*cfg = *next
+		logger.Info("user targets applied", "file", path)
+	}
+	return errs
+}
+
+// applyOverlay merges a single user target file into cfg.
+//   - A new ID is appended as a new category.
+//   - An existing ID has only the fields present in the file overridden.
+//   - disabled: true removes the category.
+//
+// On error cfg may be partly merged.
+func applyOverlay(cfg *types.Config, data []byte, source string) *ValidationError {
+	problem := func(line int, id string, err error) *ValidationError {
+		return &ValidationError{
+			Source:     filepath.Base(source),
+			Line:       line,
+			CategoryID: id,
+			Message:    strings.TrimPrefix(err.Error(), "yaml: "),
+		}
+	}
+
+	var file overlayFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return problem(0, "", err)
+	}
+
+	seen := make(map[string]bool, len(file.Categories))
+	for i := range file.Categories {
+		node := &file.Categories[i]
+
+		var header overlayHeader
+		if err := node.Decode(&header); err != nil {
+			return problem(node.Line, "", err)
+		}
+		if header.ID == "" {
+			return problem(node.Line, "", errors.New("category has no id"))
+		}
+
+		pos := categoryIndex(cfg.Categories, header.ID)
+		if seen[header.ID] && !header.Disabled {
+			// Repeated ID within one file: keep both so validation reports the duplicate.
+			pos = -1
+		}
+		seen[header.ID] = true
+
+		if header.Disabled {
+			if pos < 0 {
+				logger.Warn("user targets: disabled unknown category", "file", source, "id", header.ID)
+				continue
+			}
+			cfg.Categories = append(cfg.Categories[:pos], cfg.Categories[pos+1:]...)
+			continue
+		}
+
+		if pos >= 0 {
+			merged := cfg.Categories[pos]
+			if err := node.Decode(&merged); err != nil {
+				return problem(node.Line, header.ID, err)
+			}
+			merged.ID = header.ID
+			merged.Source = source
+			merged.Line = node.Line
+			cfg.Categories[pos] = merged
+			continue
+		}
+
+		var cat types.Category
+		if err := node.Decode(&cat); err != nil {
+			return problem(node.Line, header.ID, err)
+		}
+		cat.Source = source
+		cat.Line = node.Line
+		cfg.Categories = append(cfg.Categories, cat)
+	}
+
+	mergeGroups(cfg, file.Groups)
+	return nil
+}
+
+func categoryIndex(categories []types.Category, id string) int {
+	for i, cat := range categories {
+		if cat.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// mergeGroups adds new groups and replaces existing ones with the same ID.
+func mergeGroups(cfg *types.Config, groups []types.Group) {
+	for _, g := range groups {
+		replaced := false
+		for i := range cfg.Groups {
+			if cfg.Groups[i].ID == g.ID {
+				cfg.Groups[i] = g
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			cfg.Groups = append(cfg.Groups, g)
+		}
+	}
+}
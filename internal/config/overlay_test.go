@@ -0,0 +1,255 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/2ykwang/mac-cleanup-go/internal/types"
+)
+
+var overlayBase = []byte(`
+categories:
+  - id: cache
+    name: Cache
+    group: system
+    safety: safe
+    method: trash
+    note: base note
+    paths:
+      - "~/Cache/*"
+  - id: logs
+    name: Logs
+    group: system
+    safety: moderate
+    method: trash
+    paths:
+      - "~/Logs/*"
+groups:
+  - id: system
+    name: System
+    order: 1
+`)
+
+func writeOverlay(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func findCategory(cfg *types.Config, id string) *types.Category {
+	for i := range cfg.Categories {
+		if cfg.Categories[i].ID == id {
+			return &cfg.Categories[i]
+		}
+	}
+	return nil
+}
+
+func TestLoadWithOverlays_MissingDir_ReturnsBase(t *testing.T) {
+	cfg, err := loadWithOverlays(overlayBase, filepath.Join(t.TempDir(), "missing"))
+
+	require.NoError(t, err)
+	assert.Len(t, cfg.Categories, 2)
+	for _, cat := range cfg.Categories {
+		assert.False(t, cat.IsUserDefined())
+	}
+}
+
+func TestLoadWithOverlays_AddsNewCategory(t *testing.T) {
+	dir := t.TempDir()
+	path := writeOverlay(t, dir, "team.yaml", `
+categories:
+  - id: sdk-cache
+    name: Internal SDK Cache
+    group: dev
+    safety: safe
+    method: trash
+    paths:
+      - "~/.sdk/cache/*"
+groups:
+  - id: dev
+    name: Development
+    order: 2
+`)
+
+	cfg, err := loadWithOverlays(overlayBase, dir)
+
+	require.NoError(t, err)
+	require.Len(t, cfg.Categories, 3)
+	cat := findCategory(cfg, "sdk-cache")
+	require.NotNil(t, cat)
+	assert.Equal(t, path, cat.Source)
+	assert.Equal(t, "team.yaml", cat.SourceLabel())
+	assert.Len(t, cfg.Groups, 2)
+}
+
+func TestLoadWithOverlays_OverridesOnlySetFields(t *testing.T) {
+	dir := t.TempDir()
+	writeOverlay(t, dir, "override.yaml", `
+categories:
+  - id: cache
+    safety: moderate
+    paths:
+      - "/tmp/other/*"
+`)
+
+	cfg, err := loadWithOverlays(overlayBase, dir)
+
+	require.NoError(t, err)
+	cat := findCategory(cfg, "cache")
+	require.NotNil(t, cat)
+	assert.Equal(t, types.SafetyLevelModerate, cat.Safety)
+	assert.Equal(t, []string{"/tmp/other/*"}, cat.Paths)
+	assert.Equal(t, "Cache", cat.Name)
+	assert.Equal(t, "base note", cat.Note)
+	assert.Equal(t, types.MethodTrash, cat.Method)
+	assert.True(t, cat.IsUserDefined())
+}
+
+func TestLoadWithOverlays_DisablesCategory(t *testing.T) {
+	dir := t.TempDir()
+	writeOverlay(t, dir, "disable.yaml", `
+categories:
+  - id: logs
+    disabled: true
+  - id: unknown
+    disabled: true
+`)
+
+	cfg, err := loadWithOverlays(overlayBase, dir)
+
+	require.NoError(t, err)
+	assert.Len(t, cfg.Categories, 1)
+	assert.Nil(t, findCategory(cfg, "logs"))
+}
+
+func TestLoadWithOverlays_AppliesFilesInNameOrder(t *testing.T) {
+	dir := t.TempDir()
+	writeOverlay(t, dir, "10-first.yaml", `
+categories:
+  - id: cache
+    name: First
+`)
+	second := writeOverlay(t, dir, "20-second.yaml", `
+categories:
+  - id: cache
+    name: Second
+`)
+
+	cfg, err := loadWithOverlays(overlayBase, dir)
+
+	require.NoError(t, err)
+	cat := findCategory(cfg, "cache")
+	require.NotNil(t, cat)
+	assert.Equal(t, "Second", cat.Name)
+	assert.Equal(t, second, cat.Source)
+}
+
+func TestLoadWithOverlays_IgnoresNonYamlFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeOverlay(t, dir, "notes.txt", "not yaml: [")
+
+	cfg, err := loadWithOverlays(overlayBase, dir)
+
+	require.NoError(t, err)
+	assert.Len(t, cfg.Categories, 2)
+}
+
+func TestLoadWithOverlays_MissingID_ReturnsError(t *testing.T) {
+	dir := t.TempDir()
+	writeOverlay(t, dir, "bad.yaml", `
+categories:
+  - name: No ID
+    method: trash
+`)
+
+	_, err := loadWithOverlays(overlayBase, dir)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "bad.yaml:3")
+	assert.Contains(t, err.Error(), "no id")
+}
+
+func TestLoadWithOverlays_InvalidMergedConfig_ReturnsError(t *testing.T) {
+	dir := t.TempDir()
+	writeOverlay(t, dir, "bad.yaml", `
+categories:
+  - id: cache
+    method: invalid
+`)
+
+	_, err := loadWithOverlays(overlayBase, dir)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid method")
+}
+
+func TestLoadWithOverlays_InvalidYaml_ReturnsError(t *testing.T) {
+	dir := t.TempDir()
+	writeOverlay(t, dir, "broken.yaml", "categories: [")
+
+	_, err := loadWithOverlays(overlayBase, dir)
+
+	var problems ValidationErrors
+	require.ErrorAs(t, err, &problems)
+	require.Len(t, problems, 1)
+	assert.Equal(t, "broken.yaml", problems[0].Source)
+	assert.NotContains(t, problems[0].Message, "yaml:")
+}
+
+func TestLoadWithOverlays_BadFileSkippedAndOthersValidated(t *testing.T) {
+	dir := t.TempDir()
+	writeOverlay(t, dir, "a-broken.yaml", `
+categories:
+  - id: team-cache
+    paths: [
+`)
+	writeOverlay(t, dir, "b-bad-method.yaml", `
+categories:
+  - id: other-cache
+    name: Other
+    group: system
+    safety: safe
+    method: invalid
+`)
+
+	_, err := loadWithOverlays(overlayBase, dir)
+
+	var problems ValidationErrors
+	require.ErrorAs(t, err, &problems)
+	require.Len(t, problems, 2)
+	assert.Equal(t, "a-broken.yaml", problems[0].Source)
+	assert.Equal(t, "other-cache", problems[1].CategoryID)
+	assert.Contains(t, problems[1].Error(), "invalid method")
+}
+
+func TestLoad_UsesHomeOverlayDir(t *testing.T) {
+	home := t.TempDir()
+	orig := osUserHomeDir
+	osUserHomeDir = func() (string, error) { return home, nil }
+	defer func() { osUserHomeDir = orig }()
+
+	dir := filepath.Join(home, userConfigDir, overlayDirName)
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	writeOverlay(t, dir, "team.yaml", `
+categories:
+  - id: team-cache
+    name: Team Cache
+    group: dev
+    safety: safe
+    method: trash
+    paths:
+      - "~/.team/*"
+`)
+
+	cfg, err := Load()
+
+	require.NoError(t, err)
+	assert.NotNil(t, findCategory(cfg, "team-cache"))
+	assert.NotNil(t, findCategory(cfg, "system-cache"))
+}
@@ -0,0 +1,221 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/2ykwang/mac-cleanup-go/internal/logger"
+	"github.com/2ykwang/mac-cleanup-go/internal/target"
+	"github.com/2ykwang/mac-cleanup-go/internal/types"
+)
+
+// embeddedSourceName is used in validation messages for categories from the embedded targets.yaml.
+const embeddedSourceName = "targets.yaml"
+
+// ValidationError describes a single problem with a category definition.
+type ValidationError struct {
+	Source     string
+	Line       int
+	CategoryID string
+	Message    string
+}
+
+func (e ValidationError) Error() string {
+	location := e.Source
+	if e.Line > 0 {
+		location = fmt.Sprintf("%s:%d", e.Source, e.Line)
+	}
+	if e.CategoryID == "" {
+		return fmt.Sprintf("%s: %s", location, e.Message)
+	}
+	return fmt.Sprintf("%s: category '%s': %s", location, e.CategoryID, e.Message)
+}
+
+// ValidationErrors collects every problem found in a config.
+type ValidationErrors []ValidationError
+
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, v := range e {
+		msgs[i] = v.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+var (
+	validMethods = map[types.CleanupMethod]bool{
+		types.MethodTrash:     true,
+		types.MethodPermanent: true,
+		types.MethodBuiltin:   true,
+		types.MethodManual:    true,
+		types.MethodCommand:   true,
+	}
+	validSafety = map[types.SafetyLevel]bool{
+		types.SafetyLevelSafe:     true,
+		types.SafetyLevelModerate: true,
+		types.SafetyLevelRisky:    true,
+	}
+)
+
+// validateConfig checks every category and returns all problems as ValidationErrors.
+func validateConfig(cfg *types.Config) error {
+	errs := Validate(cfg)
+	if len(errs) > 0 {
+		return errs
+	}
+	logger.Info("config validated", "categories", len(cfg.Categories))
+	return nil
+}
+
+// Validate returns every problem found in cfg, in category order.
+func Validate(cfg *types.Config) ValidationErrors {
+	groups := make(map[string]bool, len(cfg.Groups))
+	for _, g := range cfg.Groups {
+		groups[g.ID] = true
+	}
+
+	var errs ValidationErrors
+	seen := make(map[string]types.Category, len(cfg.Categories))
+	for _, cat := range cfg.Categories {
+		report := func(format string, args ...any) {
+			e := ValidationError{
+				Source:     sourceName(cat),
+				Line:       cat.Line,
+				CategoryID: cat.ID,
+				Message:    fmt.Sprintf(format, args...),
+			}
+			logger.Warn("config validation failed", "error", e.Error())
+			errs = append(errs, e)
+		}
+
+		if cat.ID == "" {
+			report("missing id")
+		} else if first, dup := seen[cat.ID]; dup {
+			report("duplicate id (first defined at %s)", location(first))
+		} else {
+			seen[cat.ID] = cat
+		}
+
+		if !validMethods[cat.Method] {
+			report("invalid method '%s'", cat.Method)
+		}
+		if !validSafety[cat.Safety] {
+			report("invalid safety '%s'", cat.Safety)
+		}
+		if cat.Group != "" && !groups[cat.Group] {
+			report("unknown group '%s'", cat.Group)
+		}
+
+		// Method-specific validations
+		switch cat.Method {
+		case types.MethodBuiltin:
+			if !target.IsBuiltinID(cat.ID) {
+				report("unknown builtin ID")
+			}
+		case types.MethodTrash, types.MethodPermanent:
+			if len(cat.Paths) == 0 && len(cat.PathCmd) == 0 {
+				report("method '%s' requires paths", cat.Method)
+			}
+		case types.MethodManual:
+			if strings.TrimSpace(cat.Guide) == "" {
+				report("method 'manual' requires guide")
+			}
+		case types.MethodCommand:
+			if len(cat.Command) == 0 || strings.TrimSpace(cat.Command[0]) == "" {
+				report("method 'command' requires command")
+			}
+		}
+		if len(cat.PathCmd) == 0 {
+			for _, p := range cat.Paths {
+				if !isRootedPath(p) {
+					report("relative path '%s' requires path_cmd", p)
+				}
+			}
+		} else if strings.TrimSpace(cat.PathCmd[0]) == "" {
+			report("path_cmd must name a command")
+		}
+		for _, pattern := range cat.Exclude {
+			if _, err := filepath.Match(pattern, ""); err != nil {
+				report("invalid exclude pattern '%s'", pattern)
+			}
+		}
+		if cat.MinAgeDays < 0 {
+			report("min_age_days must not be negative")
+		}
+		if cat.MaxSize > 0 && cat.MinSize > cat.MaxSize {
+			report("min_size must not exceed max_size")
+		}
+		if cat.Quota < 0 {
+			report("quota must not be negative")
+		}
+		// Quota may be combined with thresholds: files outside them stay and
+		// count toward the quota.
+		if cat.Quota > 0 {
+			if cat.Method != types.MethodTrash && cat.Method != types.MethodPermanent {
+				report("quota requires method 'trash' or 'permanent'")
+			}
+			if cat.Retain.Enabled() {
+				report("quota and retain cannot be combined")
+			}
+		}
+		if cat.Retain.Enabled() {
+			if _, err := regexp.Compile(cat.Retain.Pattern); err != nil {
+				report("invalid retain pattern '%s'", cat.Retain.Pattern)
+			}
+			if cat.Retain.Keep < 1 {
+				report("retain.keep must be at least 1")
+			}
+		} else if cat.Retain.Keep != 0 {
+			report("retain.keep requires retain.pattern")
+		}
+		if !target.IsContainerRuntime(cat.Runtime) {
+			report("invalid runtime '%s'", cat.Runtime)
+		}
+		if (cat.Runtime != "" || len(cat.Contexts) > 0) && cat.ID != "docker" {
+			report("runtime and contexts are only supported by the docker target")
+		}
+		if cat.DanglingOnly && cat.ID != "docker" {
+			report("dangling_only is only supported by the docker target")
+		}
+		if len(cat.Contexts) > 0 && cat.Runtime != "" && cat.Runtime != "auto" && cat.Runtime != "docker" {
+			report("contexts require runtime 'docker'")
+		}
+		if cat.Installers && cat.ID != "old-downloads" {
+			report("installers is only supported by the old-downloads target")
+		}
+		if cat.ID == "large-files" && cat.Method != types.MethodBuiltin {
+			report("large-files must use method 'builtin' so its files only go to the Trash")
+		}
+		if !cat.ProjectCache.IsZero() && cat.ID != "project-cache" {
+			report("project_cache is only supported by the project-cache target")
+		}
+		for _, err := range cat.ProjectCache.Validate() {
+			report("%v", err)
+		}
+		if cat.Timeout < 0 {
+			report("timeout must not be negative")
+		}
+	}
+	return errs
+}
+
+// isRootedPath reports whether p is absolute once ~ and variables are expanded.
+func isRootedPath(p string) bool {
+	return filepath.IsAbs(p) || strings.HasPrefix(p, "~/") || strings.HasPrefix(p, "$")
+}
+
+func sourceName(cat types.Category) string {
+	if cat.Source == "" {
+		return embeddedSourceName
+	}
+	return filepath.Base(cat.Source)
+}
+
+func location(cat types.Category) string {
+	if cat.Line > 0 {
+		return fmt.Sprintf("%s:%d", sourceName(cat), cat.Line)
+	}
+	return sourceName(cat)
+}
@@ -0,0 +1,424 @@
+package config
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/2ykwang/mac-cleanup-go/internal/types"
+)
+
+func TestValidate_CollectsAllErrors(t *testing.T) {
+	cfg := &types.Config{
+		Groups: []types.Group{{ID: "system", Name: "System"}},
+		Categories: []types.Category{
+			{ID: "bad-method", Method: "invalid", Safety: types.SafetyLevelSafe, Line: 3},
+			{ID: "bad-safety", Method: types.MethodTrash, Safety: "invalid", Paths: []string{"~/x"}, Line: 9},
+		},
+	}
+
+	errs := Validate(cfg)
+
+	require.Len(t, errs, 2)
+	assert.Equal(t, "bad-method", errs[0].CategoryID)
+	assert.Equal(t, 3, errs[0].Line)
+	assert.Contains(t, errs[0].Message, "invalid method")
+	assert.Equal(t, "bad-safety", errs[1].CategoryID)
+	assert.Contains(t, errs[1].Message, "invalid safety")
+}
+
+func TestValidate_DuplicateID(t *testing.T) {
+	cfg := &types.Config{
+		Categories: []types.Category{
+			{ID: "cache", Method: types.MethodTrash, Safety: types.SafetyLevelSafe, Paths: []string{"~/a"}, Line: 2},
+			{ID: "cache", Method: types.MethodTrash, Safety: types.SafetyLevelSafe, Paths: []string{"~/b"}, Line: 10},
+		},
+	}
+
+	errs := Validate(cfg)
+
+	require.Len(t, errs, 1)
+	assert.Equal(t, 10, errs[0].Line)
+	assert.Contains(t, errs[0].Message, "duplicate id")
+	assert.Contains(t, errs[0].Message, "targets.yaml:2")
+}
+
+func TestValidate_InvalidExcludePattern(t *testing.T) {
+	cfg := &types.Config{
+		Categories: []types.Category{
+			{ID: "cache", Method: types.MethodTrash, Safety: types.SafetyLevelSafe, Paths: []string{"~/a/*"}, Exclude: []string{"~/a/["}},
+		},
+	}
+
+	errs := Validate(cfg)
+
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Message, "invalid exclude pattern")
+}
+
+func TestValidate_RelativePathRequiresPathCmd(t *testing.T) {
+	cfg := &types.Config{
+		Categories: []types.Category{
+			{ID: "rel", Method: types.MethodTrash, Safety: types.SafetyLevelSafe, Paths: []string{"cache/*"}},
+			{ID: "cmd", Method: types.MethodTrash, Safety: types.SafetyLevelSafe, Paths: []string{"cache/*"}, PathCmd: []string{"npm", "config", "get", "cache"}},
+			{ID: "env", Method: types.MethodTrash, Safety: types.SafetyLevelSafe, Paths: []string{"${GOCACHE:-~/Library/Caches/go-build}/*"}},
+			{ID: "root-only", Method: types.MethodTrash, Safety: types.SafetyLevelSafe, PathCmd: []string{"brew", "--cache"}},
+		},
+	}
+
+	errs := Validate(cfg)
+
+	require.Len(t, errs, 1)
+	assert.Equal(t, "rel", errs[0].CategoryID)
+	assert.Contains(t, errs[0].Message, "requires path_cmd")
+}
+
+func TestValidate_UnknownGroup(t *testing.T) {
+	cfg := &types.Config{
+		Groups: []types.Group{{ID: "system", Name: "System"}},
+		Categories: []types.Category{
+			{ID: "cache", Group: "nope", Method: types.MethodTrash, Safety: types.SafetyLevelSafe, Paths: []string{"~/a"}},
+		},
+	}
+
+	errs := Validate(cfg)
+
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Message, "unknown group 'nope'")
+}
+
+func TestValidate_PathMethodsRequirePaths(t *testing.T) {
+	cfg := &types.Config{
+		Categories: []types.Category{
+			{ID: "trash-empty", Method: types.MethodTrash, Safety: types.SafetyLevelSafe},
+			{ID: "permanent-empty", Method: types.MethodPermanent, Safety: types.SafetyLevelSafe},
+		},
+	}
+
+	errs := Validate(cfg)
+
+	require.Len(t, errs, 2)
+	assert.Contains(t, errs[0].Message, "requires paths")
+	assert.Contains(t, errs[1].Message, "requires paths")
+}
+
+func TestValidate_ManualRequiresGuide(t *testing.T) {
+	cfg := &types.Config{
+		Categories: []types.Category{
+			{ID: "no-guide", Method: types.MethodManual, Safety: types.SafetyLevelSafe},
+			{ID: "with-guide", Method: types.MethodManual, Safety: types.SafetyLevelSafe, Guide: "Open settings"},
+		},
+	}
+
+	errs := Validate(cfg)
+
+	require.Len(t, errs, 1)
+	assert.Equal(t, "no-guide", errs[0].CategoryID)
+	assert.Contains(t, errs[0].Message, "requires guide")
+}
+
+func TestValidationError_IncludesSourceAndLine(t *testing.T) {
+	e := ValidationError{Source: "team.yaml", Line: 12, CategoryID: "cache", Message: "invalid method 'x'"}
+
+	assert.Equal(t, "team.yaml:12: category 'cache': invalid method 'x'", e.Error())
+}
+
+func TestValidateConfig_ReturnsValidationErrors(t *testing.T) {
+	cfg := &types.Config{
+		Categories: []types.Category{
+			{ID: "a", Method: "invalid", Safety: "invalid"},
+		},
+	}
+
+	err := validateConfig(cfg)
+
+	var verrs ValidationErrors
+	require.True(t, errors.As(err, &verrs))
+	assert.Len(t, verrs, 2)
+}
+
+func TestParseConfig_RecordsLineNumbers(t *testing.T) {
+	cfg, err := parseConfig([]byte(`
+categories:
+  - id: first
+    name: First
+  - id: second
+    name: Second
+`))
+
+	require.NoError(t, err)
+	require.Len(t, cfg.Categories, 2)
+	assert.Equal(t, 3, cfg.Categories[0].Line)
+	assert.Equal(t, 5, cfg.Categories[1].Line)
+}
+
+func TestParseConfig_Thresholds(t *testing.T) {
+	cfg, err := parseConfig([]byte(`
+categories:
+  - id: derived-data
+    min_age_days: 14
+    min_size: 500MB
+    max_size: 1048576
+`))
+
+	require.NoError(t, err)
+	cat := cfg.Categories[0]
+	assert.Equal(t, 14, cat.MinAgeDays)
+	assert.Equal(t, types.ByteSize(500<<20), cat.MinSize)
+	assert.Equal(t, types.ByteSize(1048576), cat.MaxSize)
+}
+
+func TestParseConfig_Quota(t *testing.T) {
+	cfg, err := parseConfig([]byte(`
+categories:
+  - id: pip
+    quota: 5GB
+`))
+
+	require.NoError(t, err)
+	assert.Equal(t, types.ByteSize(5<<30), cfg.Categories[0].Quota)
+}
+
+func TestParseConfig_InvalidSize(t *testing.T) {
+	_, err := parseConfig([]byte(`
+categories:
+  - id: derived-data
+    min_size: lots
+`))
+
+	assert.Error(t, err)
+}
+
+func TestValidate_Quota(t *testing.T) {
+	cfg := &types.Config{
+		Categories: []types.Category{
+			{ID: "ok", Method: types.MethodTrash, Safety: types.SafetyLevelSafe, Paths: []string{"~/a"}, Quota: 5 << 30},
+			{ID: "command", Method: types.MethodCommand, Safety: types.SafetyLevelSafe, Command: []string{"true"}, Quota: 1},
+			{ID: "combined", Method: types.MethodTrash, Safety: types.SafetyLevelSafe, Paths: []string{"~/a"}, Quota: 1, Retain: types.Retention{Pattern: `\d+`, Keep: 1}},
+		},
+	}
+
+	errs := Validate(cfg)
+
+	require.Len(t, errs, 2)
+	assert.Equal(t, "command", errs[0].CategoryID)
+	assert.Contains(t, errs[0].Message, "quota requires method")
+	assert.Contains(t, errs[1].Message, "quota and retain cannot be combined")
+}
+
+func TestValidate_Runtime(t *testing.T) {
+	cfg := &types.Config{
+		Categories: []types.Category{
+			{ID: "docker", Method: types.MethodBuiltin, Safety: types.SafetyLevelModerate, Runtime: "lima"},
+			{ID: "cache", Method: types.MethodTrash, Safety: types.SafetyLevelSafe, Paths: []string{"~/a"}, Runtime: "podman"},
+			{ID: "logs", Method: types.MethodTrash, Safety: types.SafetyLevelSafe, Paths: []string{"~/a"}, DanglingOnly: true},
+		},
+	}
+
+	errs := Validate(cfg)
+
+	require.Len(t, errs, 3)
+	assert.Contains(t, errs[0].Message, "invalid runtime 'lima'")
+	assert.Equal(t, "cache", errs[1].CategoryID)
+	assert.Contains(t, errs[1].Message, "only supported by the docker target")
+	assert.Contains(t, errs[2].Message, "dangling_only is only supported by the docker target")
+}
+
+func TestValidate_Installers(t *testing.T) {
+	cfg := &types.Config{
+		Categories: []types.Category{
+			{ID: "old-downloads", Method: types.MethodBuiltin, Safety: types.SafetyLevelRisky, Installers: true},
+			{ID: "logs", Method: types.MethodTrash, Safety: types.SafetyLevelSafe, Paths: []string{"~/a"}, Installers: true},
+		},
+	}
+
+	errs := Validate(cfg)
+
+	require.Len(t, errs, 1)
+	assert.Equal(t, "logs", errs[0].CategoryID)
+	assert.Contains(t, errs[0].Message, "installers is only supported by the old-downloads target")
+}
+
+func TestValidate_LargeFilesNeverPermanent(t *testing.T) {
+	cfg := &types.Config{
+		Categories: []types.Category{
+			{ID: "large-files", Method: types.MethodPermanent, Safety: types.SafetyLevelRisky, Paths: []string{"~/Documents/*"}},
+		},
+	}
+
+	errs := Validate(cfg)
+
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Message, "large-files must use method 'builtin'")
+}
+
+func TestValidate_Contexts(t *testing.T) {
+	valid := &types.Config{Categories: []types.Category{
+		{ID: "docker", Method: types.MethodBuiltin, Safety: types.SafetyLevelModerate, Contexts: []string{"default", "colima"}},
+	}}
+	assert.Empty(t, Validate(valid))
+
+	podman := &types.Config{Categories: []types.Category{
+		{ID: "docker", Method: types.MethodBuiltin, Safety: types.SafetyLevelModerate, Runtime: "podman", Contexts: []string{"colima"}},
+	}}
+	errs := Validate(podman)
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Message, "contexts require runtime 'docker'")
+}
+
+func TestValidate_ProjectCache(t *testing.T) {
+	valid := &types.Config{Categories: []types.Category{
+		{ID: "project-cache", Method: types.MethodBuiltin, Safety: types.SafetyLevelModerate, ProjectCache: types.ProjectCacheOptions{
+			Roots:       []string{"/Volumes/Work", "~/src"},
+			Patterns:    []types.CachePattern{{Dir: "_build", Markers: []string{"mix.exs"}}},
+			ExcludeDirs: []string{"third_party", "~/src/vendored"},
+			MaxDepth:    10,
+			StaleDays:   14,
+		}},
+	}}
+	assert.Empty(t, Validate(valid))
+
+	invalid := &types.Config{Categories: []types.Category{
+		{ID: "project-cache", Method: types.MethodBuiltin, Safety: types.SafetyLevelModerate, ProjectCache: types.ProjectCacheOptions{
+			Roots: []string{"Work"},
+			Patterns: []types.CachePattern{
+				{Dir: "out/bazel", Markers: []string{"WORKSPACE"}},
+				{Dir: ".turbo"},
+				{Dir: "_build", Markers: []string{"../mix.exs"}},
+			},
+			MaxDepth: -1,
+		}},
+		{ID: "logs", Method: types.MethodTrash, Safety: types.SafetyLevelSafe, Paths: []string{"~/a"}, ProjectCache: types.ProjectCacheOptions{StaleDays: 3}},
+	}}
+	errs := Validate(invalid)
+
+	require.Len(t, errs, 6)
+	assert.Contains(t, errs[0].Message, "root 'Work' must be absolute")
+	assert.Contains(t, errs[1].Message, "pattern 1: dir 'out/bazel' must be a single directory name")
+	assert.Contains(t, errs[2].Message, "pattern 2: markers must not be empty")
+	assert.Contains(t, errs[3].Message, "pattern 3: marker '../mix.exs' must be a single file name")
+	assert.Contains(t, errs[4].Message, "max_depth must not be negative")
+	assert.Equal(t, "logs", errs[5].CategoryID)
+	assert.Contains(t, errs[5].Message, "only supported by the project-cache target")
+}
+
+func TestValidate_Retain(t *testing.T) {
+	cfg := &types.Config{
+		Categories: []types.Category{
+			{ID: "ok", Method: types.MethodTrash, Safety: types.SafetyLevelSafe, Paths: []string{"~/a"}, Retain: types.Retention{Pattern: `(\d+)`, Keep: 2}},
+			{ID: "bad-regex", Method: types.MethodTrash, Safety: types.SafetyLevelSafe, Paths: []string{"~/a"}, Retain: types.Retention{Pattern: `(\d+`, Keep: 1}},
+			{ID: "no-keep", Method: types.MethodTrash, Safety: types.SafetyLevelSafe, Paths: []string{"~/a"}, Retain: types.Retention{Pattern: `\d+`}},
+			{ID: "no-pattern", Method: types.MethodTrash, Safety: types.SafetyLevelSafe, Paths: []string{"~/a"}, Retain: types.Retention{Keep: 1}},
+		},
+	}
+
+	errs := Validate(cfg)
+
+	require.Len(t, errs, 3)
+	assert.Contains(t, errs[0].Message, "invalid retain pattern")
+	assert.Equal(t, "no-keep", errs[1].CategoryID)
+	assert.Contains(t, errs[2].Message, "requires retain.pattern")
+}
+
+func TestValidate_Thresholds(t *testing.T) {
+	cfg := &types.Config{
+		Categories: []types.Category{
+			{ID: "cache", Method: types.MethodTrash, Safety: types.SafetyLevelSafe, Paths: []string{"~/a"}, MinAgeDays: -1, MinSize: 10, MaxSize: 5},
+		},
+	}
+
+	errs := Validate(cfg)
+
+	require.Len(t, errs, 2)
+	assert.Contains(t, errs[0].Message, "min_age_days")
+	assert.Contains(t, errs[1].Message, "min_size")
+}
+
+func TestLoadFiles_ReportsOverlayLocation(t *testing.T) {
+	dir := t.TempDir()
+	path := writeOverlay(t, dir, "team.yaml", `
+categories:
+  - id: team-cache
+    name: Team Cache
+    group: dev
+    safety: safe
+    method: trash
+`)
+
+	_, err := LoadFiles([]string{path})
+
+	var verrs ValidationErrors
+	require.True(t, errors.As(err, &verrs))
+	require.Len(t, verrs, 1)
+	assert.Equal(t, "team.yaml", verrs[0].Source)
+	assert.Equal(t, 3, verrs[0].Line)
+}
+
+func TestLoadFiles_MissingFile_ReturnsError(t *testing.T) {
+	_, err := LoadFiles([]string{filepath.Join(t.TempDir(), "missing.yaml")})
+
+	assert.Error(t, err)
+}
+
+func TestApplyOverlay_DuplicateIDInSameFile_IsReported(t *testing.T) {
+	dir := t.TempDir()
+	writeOverlay(t, dir, "dup.yaml", `
+categories:
+  - id: team
+    name: Team
+    safety: safe
+    method: trash
+    paths: ["~/a"]
+  - id: team
+    name: Team Again
+    safety: safe
+    method: trash
+    paths: ["~/b"]
+`)
+
+	_, err := loadWithOverlays(overlayBase, dir)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "dup.yaml:8")
+	assert.Contains(t, err.Error(), "duplicate id")
+}
+
+func TestValidate_CommandMethod(t *testing.T) {
+	cfg := &types.Config{
+		Categories: []types.Category{
+			{ID: "no-command", Method: types.MethodCommand, Safety: types.SafetyLevelSafe},
+			{ID: "go-cache", Method: types.MethodCommand, Safety: types.SafetyLevelSafe, Command: []string{"go", "clean", "-cache"}},
+			{ID: "bad-timeout", Method: types.MethodCommand, Safety: types.SafetyLevelSafe, Command: []string{"true"}, Timeout: -1},
+		},
+	}
+
+	errs := Validate(cfg)
+
+	require.Len(t, errs, 2)
+	assert.Equal(t, "no-command", errs[0].CategoryID)
+	assert.Contains(t, errs[0].Message, "requires command")
+	assert.Equal(t, "bad-timeout", errs[1].CategoryID)
+}
+
+func TestParseConfig_CommandFields(t *testing.T) {
+	cfg, err := parseConfig([]byte(`
+categories:
+  - id: pnpm-store
+    name: pnpm Store
+    safety: safe
+    method: command
+    command: ["pnpm", "store", "prune"]
+    size_cmd: ["sh", "-c", "echo 1GB"]
+    timeout: 2m
+`))
+
+	require.NoError(t, err)
+	cat := cfg.Categories[0]
+	assert.Equal(t, []string{"pnpm", "store", "prune"}, cat.Command)
+	assert.Len(t, cat.SizeCmd, 3)
+	assert.Equal(t, 2*time.Minute, cat.Timeout)
+}
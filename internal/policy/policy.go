@@ -0,0 +1,215 @@
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/2ykwang/mac-cleanup-go/internal/logger"
+	"github.com/2ykwang/mac-cleanup-go/internal/types"
+	"github.com/2ykwang/mac-cleanup-go/internal/utils"
+)
+
+const (
+	// DefaultPath is where administrators install the organization policy.
+	DefaultPath = "/Library/Application Support/mac-cleanup-go/policy.yaml"
+	// EnvVar overrides DefaultPath.
+	EnvVar = "MAC_CLEANUP_POLICY"
+)
+
+// Function variables for testing
+var (
+	osReadFile = os.ReadFile
+	osGetenv   = os.Getenv
+)
+
+// Policy is an administrator-supplied set of rules that users cannot override.
+type Policy struct {
+	// Forbidden category IDs are hidden and never cleaned.
+	Forbidden []string `yaml:"forbidden,omitempty"`
+	// Mandatory category IDs are always selected and cannot be deselected.
+	Mandatory []string `yaml:"mandatory,omitempty"`
+	// MaxSafety hides categories above this safety level. Empty means no cap.
+	MaxSafety types.SafetyLevel `yaml:"max_safety,omitempty"`
+	// ProtectedPaths are glob patterns that are never collected or cleaned.
+	ProtectedPaths []string `yaml:"protected_paths,omitempty"`
+
+	// Source is the file the policy was loaded from.
+	Source string `yaml:"-"`
+}
+
+// Removal records a category hidden by a policy rule.
+type Removal struct {
+	CategoryID   string
+	CategoryName string
+	Rule         string
+}
+
+// Path returns the policy file location, honouring EnvVar.
+func Path() string {
+	if p := osGetenv(EnvVar); p != "" {
+		return p
+	}
+	return DefaultPath
+}
+
+// Load reads the policy from Path. It returns nil without error when no
+// policy is installed.
+func Load() (*Policy, error) {
+	return LoadFile(Path())
+}
+
+// LoadFile reads and validates a policy file. A missing file yields nil.
+func LoadFile(path string) (*Policy, error) {
+	data, err := osReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read policy %s: %w", path, err)
+	}
+
+	var p Policy
+	if err := yaml.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("parse policy %s: %w", path, err)
+	}
+	p.Source = path
+	if err := p.validate(); err != nil {
+		return nil, fmt.Errorf("invalid policy %s: %w", path, err)
+	}
+
+	logger.Info("policy loaded",
+		"path", path,
+		"forbidden", len(p.Forbidden),
+		"mandatory", len(p.Mandatory),
+		"maxSafety", p.MaxSafety,
+		"protectedPaths", len(p.ProtectedPaths))
+	return &p, nil
+}
+
+func (p *Policy) validate() error {
+	switch p.MaxSafety {
+	case "", types.SafetyLevelSafe, types.SafetyLevelModerate, types.SafetyLevelRisky:
+	default:
+		return fmt.Errorf("invalid max_safety '%s'", p.MaxSafety)
+	}
+	for _, id := range p.Mandatory {
+		if slices.Contains(p.Forbidden, id) {
+			return fmt.Errorf("category '%s' is both mandatory and forbidden", id)
+		}
+	}
+	return nil
+}
+
+// Apply removes categories the policy hides and adds protected paths to every
+// remaining category's exclude list. It returns the hidden categories with
+// the rule that removed each one.
+func (p *Policy) Apply(cfg *types.Config) []Removal {
+	if p == nil || cfg == nil {
+		return nil
+	}
+
+	var removed []Removal
+	kept := cfg.Categories[:0]
+	for _, cat := range cfg.Categories {
+		if rule := p.RemovalRule(cat); rule != "" {
+			removed = append(removed, Removal{CategoryID: cat.ID, CategoryName: cat.Name, Rule: rule})
+			logger.Info("category hidden by policy", "id", cat.ID, "rule", rule)
+			continue
+		}
+		for _, pattern := range p.ProtectedPaths {
+			if !slices.Contains(cat.Exclude, pattern) {
+				cat.Exclude = append(cat.Exclude, pattern)
+			}
+		}
+		kept = append(kept, cat)
+	}
+	cfg.Categories = kept
+	return removed
+}
+
+// RemovalRule returns the rule that hides cat, or "" if the policy allows it.
+func (p *Policy) RemovalRule(cat types.Category) string {
+	if p == nil {
+		return ""
+	}
+	if slices.Contains(p.Forbidden, cat.ID) {
+		return "forbidden by policy"
+	}
+	if cat.Safety.Exceeds(p.MaxSafety) && !p.IsMandatory(cat.ID) {
+		return fmt.Sprintf("above policy max safety (%s)", p.MaxSafety)
+	}
+	return ""
+}
+
+// IsMandatory reports whether the policy requires the category to be selected.
+func (p *Policy) IsMandatory(id string) bool {
+	return p != nil && slices.Contains(p.Mandatory, id)
+}
+
+// MandatoryRule returns the rule that keeps the category selected, or "".
+func (p *Policy) MandatoryRule(id string) string {
+	if !p.IsMandatory(id) {
+		return ""
+	}
+	return "required by policy"
+}
+
+// MandatoryIDs returns the category IDs the policy requires to be selected.
+func (p *Policy) MandatoryIDs() []string {
+	if p == nil {
+		return nil
+	}
+	return p.Mandatory
+}
+
+// ProtectedRule returns the protected path pattern that covers path, or "".
+// A path is covered when it lies inside a protected path or contains one.
+func (p *Policy) ProtectedRule(path string) string {
+	if p == nil || path == "" {
+		return ""
+	}
+	for _, pattern := range p.ProtectedPaths {
+		expanded := utils.ExpandPath(pattern)
+		if utils.MatchPathOrAncestor(expanded, path) ||
+			strings.HasPrefix(expanded, strings.TrimSuffix(path, "/")+"/") {
+			return pattern
+		}
+	}
+	return ""
+}
+
+// FilterProtected drops items under protected paths from result, recomputing
+// its totals from the items still offered for deletion. It returns how many
+// items were dropped.
+func (p *Policy) FilterProtected(result *types.ScanResult) int {
+	if p == nil || result == nil || len(p.ProtectedPaths) == 0 {
+		return 0
+	}
+
+	kept := result.Items[:0]
+	var size, count int64
+	dropped := 0
+	for _, item := range result.Items {
+		if rule := p.ProtectedRule(item.Path); rule != "" {
+			logger.Info("item protected by policy", "path", item.Path, "rule", rule)
+			dropped++
+			continue
+		}
+		kept = append(kept, item)
+		if item.Status.Cleanable() {
+			size += item.Size
+			count += item.FileCount
+		}
+	}
+	if dropped > 0 {
+		result.Items = kept
+		result.TotalSize = size
+		result.TotalFileCount = count
+	}
+	return dropped
+}
@@ -0,0 +1,161 @@
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/2ykwang/mac-cleanup-go/internal/types"
+)
+
+func writePolicy(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func TestPath_EnvOverride(t *testing.T) {
+	t.Setenv(EnvVar, "")
+	assert.Equal(t, DefaultPath, Path())
+
+	t.Setenv(EnvVar, "/etc/cleanup-policy.yaml")
+	assert.Equal(t, "/etc/cleanup-policy.yaml", Path())
+}
+
+func TestLoad_MissingFile_ReturnsNil(t *testing.T) {
+	t.Setenv(EnvVar, filepath.Join(t.TempDir(), "missing.yaml"))
+
+	p, err := Load()
+
+	assert.NoError(t, err)
+	assert.Nil(t, p)
+}
+
+func TestLoadFile_ParsesRules(t *testing.T) {
+	path := writePolicy(t, `
+forbidden: [docker]
+mandatory: [logs]
+max_safety: moderate
+protected_paths:
+  - ~/Library/Caches/com.corp.agent
+`)
+
+	p, err := LoadFile(path)
+
+	require.NoError(t, err)
+	assert.Equal(t, path, p.Source)
+	assert.Equal(t, []string{"docker"}, p.Forbidden)
+	assert.Equal(t, []string{"logs"}, p.Mandatory)
+	assert.Equal(t, types.SafetyLevelModerate, p.MaxSafety)
+	assert.Equal(t, []string{"~/Library/Caches/com.corp.agent"}, p.ProtectedPaths)
+}
+
+func TestLoadFile_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"bad yaml", "forbidden: [", "parse policy"},
+		{"bad safety", "max_safety: extreme", "invalid max_safety 'extreme'"},
+		{"conflict", "forbidden: [logs]\nmandatory: [logs]", "'logs' is both mandatory and forbidden"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadFile(writePolicy(t, tt.content))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
+func TestApply_RemovesCategoriesAndAddsProtectedPaths(t *testing.T) {
+	p := &Policy{
+		Forbidden:      []string{"docker"},
+		Mandatory:      []string{"downloads"},
+		MaxSafety:      types.SafetyLevelSafe,
+		ProtectedPaths: []string{"/Users/me/keep"},
+	}
+	cfg := &types.Config{Categories: []types.Category{
+		{ID: "logs", Name: "Logs", Safety: types.SafetyLevelSafe},
+		{ID: "docker", Name: "Docker", Safety: types.SafetyLevelSafe},
+		{ID: "npm", Name: "npm", Safety: types.SafetyLevelModerate},
+		{ID: "downloads", Name: "Downloads", Safety: types.SafetyLevelModerate},
+	}}
+
+	removed := p.Apply(cfg)
+
+	require.Len(t, cfg.Categories, 2)
+	assert.Equal(t, "logs", cfg.Categories[0].ID)
+	assert.Equal(t, "downloads", cfg.Categories[1].ID, "mandatory targets are exempt from the safety cap")
+	assert.Equal(t, []string{"/Users/me/keep"}, cfg.Categories[0].Exclude)
+	assert.Equal(t, []Removal{
+		{CategoryID: "docker", CategoryName: "Docker", Rule: "forbidden by policy"},
+		{CategoryID: "npm", CategoryName: "npm", Rule: "above policy max safety (safe)"},
+	}, removed)
+}
+
+func TestApply_NilPolicy(t *testing.T) {
+	var p *Policy
+	cfg := &types.Config{Categories: []types.Category{{ID: "logs"}}}
+
+	assert.Nil(t, p.Apply(cfg))
+	assert.Len(t, cfg.Categories, 1)
+	assert.False(t, p.IsMandatory("logs"))
+	assert.Empty(t, p.ProtectedRule("/tmp"))
+}
+
+func TestProtectedRule_InsideAndContaining(t *testing.T) {
+	p := &Policy{ProtectedPaths: []string{"/data/keep/*.db"}}
+
+	assert.Equal(t, "/data/keep/*.db", p.ProtectedRule("/data/keep/app.db"))
+	assert.Equal(t, "/data/keep/*.db", p.ProtectedRule("/data/keep/app.db/wal"))
+	assert.Equal(t, "/data/keep/*.db", p.ProtectedRule("/data"), "deleting a parent would delete the protected path")
+	assert.Empty(t, p.ProtectedRule("/data/other"))
+	assert.Empty(t, p.ProtectedRule("docker:image:abc"))
+}
+
+func TestFilterProtected_RecomputesTotals(t *testing.T) {
+	p := &Policy{ProtectedPaths: []string{"/data/keep"}}
+	result := &types.ScanResult{
+		Items: []types.CleanableItem{
+			{Path: "/data/keep", Size: 100, FileCount: 3},
+			{Path: "/data/tmp", Size: 10, FileCount: 1},
+		},
+		TotalSize:      110,
+		TotalFileCount: 4,
+	}
+
+	dropped := p.FilterProtected(result)
+
+	assert.Equal(t, 1, dropped)
+	require.Len(t, result.Items, 1)
+	assert.Equal(t, "/data/tmp", result.Items[0].Path)
+	assert.Equal(t, int64(10), result.TotalSize)
+	assert.Equal(t, int64(1), result.TotalFileCount)
+}
+
+func TestFilterProtected_SkipsHeldBackItemsInTotals(t *testing.T) {
+	p := &Policy{ProtectedPaths: []string{"/data/keep"}}
+	result := &types.ScanResult{
+		Items: []types.CleanableItem{
+			{Path: "/data/keep", Size: 100, FileCount: 3},
+			{Path: "/data/v1", Size: 50, FileCount: 5, Status: types.ItemStatusRetained},
+			{Path: "/data/tmp", Size: 10, FileCount: 1},
+		},
+		TotalSize:      110,
+		TotalFileCount: 4,
+	}
+
+	dropped := p.FilterProtected(result)
+
+	assert.Equal(t, 1, dropped)
+	require.Len(t, result.Items, 2)
+	assert.Equal(t, int64(10), result.TotalSize)
+	assert.Equal(t, int64(1), result.TotalFileCount)
+}
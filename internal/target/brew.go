@@ -3,7 +3,11 @@ package target
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/2ykwang/mac-cleanup-go/internal/logger"
 	"github.com/2ykwang/mac-cleanup-go/internal/types"
@@ -13,8 +17,25 @@ import (
 type BrewTarget struct {
 	category  types.Category
 	cachePath string
+	prefix    string
+
+	mu       sync.Mutex
+	reported map[string]struct{} // paths the last cleanup dry run listed
 }
 
+// brewCleanupArgs are the `brew cleanup` flags whose dry run is itemized.
+var brewCleanupArgs = []string{"cleanup", "--prune=all", "-s"}
+
+// brewWouldRemove matches a `brew cleanup --dry-run` line, e.g.
+// "Would remove: /opt/homebrew/Cellar/node/20.1.0 (2,345 files, 58.2MB)".
+var brewWouldRemove = regexp.MustCompile(`^Would remove: (/.+?)(?: \(([^()]*)\))?$`)
+
+// brewDetails matches the "(N files, SIZE)" details of a dry-run directory.
+var brewDetails = regexp.MustCompile(`^([\d,]+) files?, (.+)$`)
+
+// brewDownloadHash is the checksum prefix Homebrew gives cached downloads.
+var brewDownloadHash = regexp.MustCompile(`^[0-9a-f]{64}--`)
+
 func NewBrewTarget(cat types.Category) *BrewTarget {
 	return &BrewTarget{category: cat}
 }
@@ -45,6 +66,80 @@ func (s *BrewTarget) getBrewCachePath() string {
 	return s.cachePath
 }
 
+// getBrewPrefix returns the Homebrew installation prefix.
+func (s *BrewTarget) getBrewPrefix() string {
+	if s.prefix != "" {
+		return s.prefix
+	}
+
+	output, err := execCommand("brew", "--prefix").Output()
+	if err != nil {
+		logger.Warn("brew --prefix failed", "error", err)
+		return ""
+	}
+
+	s.prefix = strings.TrimSpace(string(output))
+	return s.prefix
+}
+
+// scanCleanupDryRun lists what `brew cleanup` would remove, one item per
+// outdated keg, cached download or stale lock file.
+func (s *BrewTarget) scanCleanupDryRun() ([]types.CleanableItem, error) {
+	args := append(append([]string{}, brewCleanupArgs...), "--dry-run")
+	output, err := execCommand("brew", args...).Output()
+	if err != nil {
+		logger.Warn("brew cleanup --dry-run failed", "error", err)
+		return nil, err
+	}
+
+	var items []types.CleanableItem
+	reported := make(map[string]struct{})
+	for _, line := range strings.Split(string(output), "\n") {
+		m := brewWouldRemove.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil {
+			continue
+		}
+		item := s.cleanupItem(m[1], m[2])
+		if info, err := os.Lstat(item.Path); err == nil {
+			item.ModifiedAt = info.ModTime()
+			item.IsDirectory = info.IsDir()
+		}
+		items = append(items, item)
+		reported[item.Path] = struct{}{}
+	}
+
+	s.mu.Lock()
+	s.reported = reported
+	s.mu.Unlock()
+	return items, nil
+}
+
+// cleanupItem builds an item from a dry-run path and its "(N files, SIZE)"
+// details, naming it after what it is.
+func (s *BrewTarget) cleanupItem(path, details string) types.CleanableItem {
+	item := types.CleanableItem{Path: path, FileCount: 1}
+	if m := brewDetails.FindStringSubmatch(details); m != nil {
+		item.FileCount, _ = strconv.ParseInt(strings.ReplaceAll(m[1], ",", ""), 10, 64)
+		item.Size = parseDockerSize(m[2])
+	} else {
+		item.Size = parseDockerSize(details)
+	}
+
+	base := filepath.Base(path)
+	cachePath := s.getBrewCachePath()
+	switch {
+	case strings.Contains(path, "/Cellar/"):
+		item.Name = fmt.Sprintf("Outdated keg: %s %s", filepath.Base(filepath.Dir(path)), base)
+	case strings.HasSuffix(base, ".lock"):
+		item.Name = "Stale lock: " + base
+	case cachePath != "" && strings.HasPrefix(path, cachePath+"/"):
+		item.Name = "Cached download: " + brewDownloadHash.ReplaceAllString(base, "")
+	default:
+		item.Name = "Leftover: " + base
+	}
+	return item
+}
+
 func (s *BrewTarget) Scan() (*types.ScanResult, error) {
 	result := types.NewScanResult(s.category)
 
@@ -53,6 +148,19 @@ func (s *BrewTarget) Scan() (*types.ScanResult, error) {
 		return result, nil
 	}
 
+	// Itemize what brew cleanup would remove so single formulae can be kept.
+	// When brew reports nothing to remove, nothing is offered; the whole
+	// cache is only a fallback for when the dry run itself fails.
+	if items, err := s.scanCleanupDryRun(); err == nil {
+		for _, item := range items {
+			result.Items = append(result.Items, item)
+			result.TotalSize += item.Size
+			result.TotalFileCount += item.FileCount
+		}
+		logger.Info("brew scan completed", "items", len(result.Items), "totalSize", result.TotalSize)
+		return result, nil
+	}
+
 	cachePath := s.getBrewCachePath()
 	if cachePath == "" {
 		return result, nil
@@ -95,23 +203,44 @@ func (s *BrewTarget) Clean(items []types.CleanableItem) (*types.CleanResult, err
 		return result, nil
 	}
 
-	// Run brew cleanup first
-	cmd := execCommand("brew", "cleanup", "--prune=all", "-s")
-	if err := cmd.Run(); err != nil {
-		logger.Warn("brew cleanup command failed", "error", err)
-	} else {
-		logger.Debug("brew cleanup command completed")
+	cachePath := s.getBrewCachePath()
+
+	// The whole cache is offered when cleanup could not be itemized; let brew
+	// clean up first, as before. Itemized entries are trashed one by one so
+	// deselected formulae are kept.
+	var prefix string
+	for _, item := range items {
+		if item.Path == cachePath {
+			cmd := execCommand("brew", brewCleanupArgs...)
+			if err := cmd.Run(); err != nil {
+				logger.Warn("brew cleanup command failed", "error", err)
+			} else {
+				logger.Debug("brew cleanup command completed")
+			}
+		} else if prefix == "" {
+			prefix = s.getBrewPrefix()
+		}
 	}
 
-	cachePath := s.getBrewCachePath()
+	// Leftovers such as ~/Library/Logs/Homebrew live outside the cache and
+	// prefix; accept them when the last dry run listed them.
+	s.mu.Lock()
+	reported := s.reported
+	s.mu.Unlock()
 
 	batchResult := utils.BatchTrash(items, types.BatchTrashOptions{
 		Category: result.Category,
 		Validate: func(item types.CleanableItem) error {
-			if cachePath == "" || !strings.HasPrefix(item.Path, cachePath) {
-				return fmt.Errorf("invalid path: %s", item.Path)
+			if _, ok := reported[item.Path]; ok {
+				return nil
+			}
+			if cachePath != "" && (item.Path == cachePath || strings.HasPrefix(item.Path, cachePath+"/")) {
+				return nil
+			}
+			if prefix != "" && strings.HasPrefix(item.Path, prefix+"/") {
+				return nil
 			}
-			return nil
+			return fmt.Errorf("invalid path: %s", item.Path)
 		},
 	})
 
@@ -0,0 +1,184 @@
+package target
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/2ykwang/mac-cleanup-go/internal/logger"
+	"github.com/2ykwang/mac-cleanup-go/internal/types"
+	"github.com/2ykwang/mac-cleanup-go/internal/utils"
+)
+
+// BrewAutoremoveTarget offers formulae that were installed only as
+// dependencies of formulae that have since been uninstalled.
+type BrewAutoremoveTarget struct {
+	category types.Category
+	cellar   string
+}
+
+func NewBrewAutoremoveTarget(cat types.Category) *BrewAutoremoveTarget {
+	return &BrewAutoremoveTarget{category: cat}
+}
+
+func (s *BrewAutoremoveTarget) Category() types.Category {
+	return s.category
+}
+
+func (s *BrewAutoremoveTarget) IsAvailable() bool {
+	return utils.CommandExists("brew")
+}
+
+// getCellar returns the directory formulae are installed in.
+func (s *BrewAutoremoveTarget) getCellar() string {
+	if s.cellar != "" {
+		return s.cellar
+	}
+	output, err := execCommand("brew", "--cellar").Output()
+	if err != nil {
+		logger.Warn("brew --cellar failed", "error", err)
+		return ""
+	}
+	s.cellar = strings.TrimSpace(string(output))
+	return s.cellar
+}
+
+func (s *BrewAutoremoveTarget) Scan() (*types.ScanResult, error) {
+	result := types.NewScanResult(s.category)
+
+	if !s.IsAvailable() {
+		logger.Debug("brew not available, skipping autoremove scan")
+		return result, nil
+	}
+
+	output, err := execCommand("brew", "autoremove", "--dry-run").Output()
+	if err != nil {
+		logger.Warn("brew autoremove --dry-run failed", "error", err)
+		result.Error = err
+		return result, nil
+	}
+	formulae := parseAutoremoveDryRun(string(output))
+	if len(formulae) == 0 {
+		return result, nil
+	}
+
+	cellar := s.getCellar()
+	if cellar == "" {
+		return result, nil
+	}
+	pinned := s.pinnedFormulae()
+
+	for _, formula := range formulae {
+		dir := filepath.Join(cellar, path.Base(formula))
+		info, err := os.Stat(dir)
+		if err != nil {
+			logger.Debug("orphaned formula not in cellar", "formula", formula, "error", err)
+			continue
+		}
+		size, fileCount, _ := utils.GetDirSizeWithCount(dir)
+		item := types.CleanableItem{
+			Path:        dir,
+			Size:        size,
+			FileCount:   fileCount,
+			Name:        formula,
+			IsDirectory: true,
+			ModifiedAt:  info.ModTime(),
+		}
+		if _, ok := pinned[formula]; ok {
+			item.Status = types.ItemStatusPinned
+		} else {
+			result.TotalSize += size
+			result.TotalFileCount += fileCount
+		}
+		result.Items = append(result.Items, item)
+	}
+
+	logger.Info("brew autoremove scan completed",
+		"formulae", len(result.Items),
+		"pinned", len(pinned),
+		"totalSize", result.TotalSize)
+
+	return result, nil
+}
+
+// pinnedFormulae returns the formulae pinned in the category (from user
+// config) or with `brew pin`.
+func (s *BrewAutoremoveTarget) pinnedFormulae() map[string]struct{} {
+	pinned := make(map[string]struct{})
+	for _, name := range s.category.Pinned {
+		pinned[name] = struct{}{}
+	}
+	output, err := execCommand("brew", "list", "--pinned").Output()
+	if err != nil {
+		logger.Debug("brew list --pinned failed", "error", err)
+		return pinned
+	}
+	for _, name := range strings.Fields(string(output)) {
+		pinned[name] = struct{}{}
+	}
+	return pinned
+}
+
+// parseAutoremoveDryRun returns the formulae listed by `brew autoremove
+// --dry-run` below its "==> Would autoremove ..." header.
+func parseAutoremoveDryRun(output string) []string {
+	var formulae []string
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "==>") {
+			continue
+		}
+		formulae = append(formulae, strings.Fields(line)...)
+	}
+	return formulae
+}
+
+// Clean uninstalls the selected formulae in one brew call, so orphans that
+// depend on each other go together. An item counts as cleaned once its keg
+// is gone, even if brew fails partway.
+func (s *BrewAutoremoveTarget) Clean(items []types.CleanableItem) (*types.CleanResult, error) {
+	result := types.NewCleanResult(s.category)
+
+	if len(items) == 0 {
+		return result, nil
+	}
+
+	cellar := s.getCellar()
+	var targets []types.CleanableItem
+	for _, item := range items {
+		if cellar == "" || filepath.Dir(item.Path) != cellar {
+			result.Errors = append(result.Errors, fmt.Sprintf("invalid path: %s", item.Path))
+			continue
+		}
+		targets = append(targets, item)
+	}
+	if len(targets) == 0 {
+		return result, nil
+	}
+
+	args := []string{"uninstall", "--formula"}
+	for _, item := range targets {
+		args = append(args, item.Name)
+	}
+	if err := execCommand("brew", args...).Run(); err != nil {
+		logger.Warn("brew uninstall failed", "error", err)
+		result.Errors = append(result.Errors, fmt.Sprintf("brew uninstall: %v", err))
+	}
+
+	for _, item := range targets {
+		if _, err := os.Stat(item.Path); errors.Is(err, os.ErrNotExist) {
+			result.CleanedItems++
+			result.FreedSpace += item.Size
+		}
+	}
+
+	logger.Info("brew autoremove clean completed",
+		"cleanedItems", result.CleanedItems,
+		"freedSpace", result.FreedSpace,
+		"errors", len(result.Errors))
+
+	return result, nil
+}
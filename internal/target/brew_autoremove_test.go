@@ -0,0 +1,123 @@
+package target
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/2ykwang/mac-cleanup-go/internal/types"
+	"github.com/2ykwang/mac-cleanup-go/internal/utils"
+)
+
+// stubBrewAutoremove creates a cellar with the given formulae and makes brew
+// report them as orphans. Uninstall removes the named kegs except those in
+// failing. It returns the cellar and the brew commands run.
+func stubBrewAutoremove(t *testing.T, orphans, pinned, failing []string) (string, *[]string) {
+	t.Helper()
+	cellar := t.TempDir()
+	for _, name := range orphans {
+		dir := filepath.Join(cellar, filepath.Base(name), "1.0")
+		require.NoError(t, os.MkdirAll(dir, 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "lib"), []byte(name), 0o644))
+	}
+
+	originalCommandExists := utils.CommandExists
+	originalExec := execCommand
+	t.Cleanup(func() {
+		utils.CommandExists = originalCommandExists
+		execCommand = originalExec
+	})
+	utils.CommandExists = func(name string) bool { return name == "brew" }
+
+	var calls []string
+	execCommand = func(_ string, args ...string) *exec.Cmd {
+		calls = append(calls, strings.Join(args, " "))
+		switch {
+		case len(args) == 1 && args[0] == "--cellar":
+			return exec.Command("echo", cellar)
+		case len(args) == 2 && args[0] == "autoremove":
+			return exec.Command("printf", "%s", "==> Would autoremove 2 unneeded formulae:\n"+strings.Join(orphans, "\n")+"\n")
+		case len(args) == 2 && args[0] == "list":
+			return exec.Command("echo", strings.Join(pinned, " "))
+		case args[0] == "uninstall":
+			var dirs []string
+			for _, name := range args[2:] {
+				if !slices.Contains(failing, name) {
+					dirs = append(dirs, filepath.Join(cellar, filepath.Base(name)))
+				}
+			}
+			script := "rm -rf \"$@\""
+			if len(failing) > 0 {
+				script += "; exit 1"
+			}
+			return exec.Command("sh", append([]string{"-c", script, "sh"}, dirs...)...)
+		}
+		return exec.Command("true")
+	}
+	return cellar, &calls
+}
+
+func TestParseAutoremoveDryRun(t *testing.T) {
+	output := "==> Would autoremove 3 unneeded formulae:\nlibyaml\npython@3.10\nuser/tap/tool\n"
+
+	assert.Equal(t, []string{"libyaml", "python@3.10", "user/tap/tool"}, parseAutoremoveDryRun(output))
+	assert.Empty(t, parseAutoremoveDryRun(""))
+}
+
+func TestBrewAutoremoveTarget_Scan(t *testing.T) {
+	cellar, _ := stubBrewAutoremove(t, []string{"libyaml", "python@3.10", "user/tap/tool"}, []string{"python@3.10"}, nil)
+	cat := types.Category{ID: "homebrew-autoremove", Pinned: []string{"libyaml"}}
+
+	result, err := NewBrewAutoremoveTarget(cat).Scan()
+
+	require.NoError(t, err)
+	require.Len(t, result.Items, 3)
+	assert.Equal(t, filepath.Join(cellar, "libyaml"), result.Items[0].Path)
+	assert.Equal(t, types.ItemStatusPinned, result.Items[0].Status, "pinned in user config")
+	assert.Equal(t, types.ItemStatusPinned, result.Items[1].Status, "pinned with brew pin")
+
+	tool := result.Items[2]
+	assert.Equal(t, "user/tap/tool", tool.Name)
+	assert.Equal(t, filepath.Join(cellar, "tool"), tool.Path)
+	assert.True(t, tool.Status.Cleanable())
+	assert.Equal(t, tool.Size, result.TotalSize, "pinned formulae are not counted")
+}
+
+func TestBrewAutoremoveTarget_Clean(t *testing.T) {
+	cellar, calls := stubBrewAutoremove(t, []string{"libyaml", "gettext"}, nil, nil)
+	s := NewBrewAutoremoveTarget(types.Category{ID: "homebrew-autoremove"})
+
+	result, err := s.Clean([]types.CleanableItem{
+		{Path: filepath.Join(cellar, "libyaml"), Name: "libyaml", Size: 10},
+		{Path: filepath.Join(cellar, "gettext"), Name: "gettext", Size: 20},
+		{Path: "/usr/local/bin", Name: "bin", Size: 1},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.CleanedItems)
+	assert.Equal(t, int64(30), result.FreedSpace)
+	require.Len(t, result.Errors, 1)
+	assert.Contains(t, result.Errors[0], "invalid path: /usr/local/bin")
+	assert.Contains(t, *calls, "uninstall --formula libyaml gettext")
+}
+
+func TestBrewAutoremoveTarget_Clean_CountsOnlyRemovedKegs(t *testing.T) {
+	cellar, _ := stubBrewAutoremove(t, []string{"libyaml", "gettext"}, nil, []string{"gettext"})
+	s := NewBrewAutoremoveTarget(types.Category{ID: "homebrew-autoremove"})
+
+	result, err := s.Clean([]types.CleanableItem{
+		{Path: filepath.Join(cellar, "libyaml"), Name: "libyaml", Size: 10},
+		{Path: filepath.Join(cellar, "gettext"), Name: "gettext", Size: 20},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.CleanedItems)
+	assert.Equal(t, int64(10), result.FreedSpace)
+	assert.Len(t, result.Errors, 1)
+}
@@ -4,6 +4,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -99,6 +100,16 @@ func TestBrewTarget_Scan_WithMockCachePath(t *testing.T) {
 	testFile := filepath.Join(cacheDir, "test-package.tar.gz")
 	require.NoError(t, os.WriteFile(testFile, []byte("test content for brew cache"), 0o644))
 
+	// The whole cache is offered only when the dry run fails.
+	stubBrew(t, "")
+	execDryRun := execCommand
+	execCommand = func(name string, args ...string) *exec.Cmd {
+		if len(args) > 0 && args[len(args)-1] == "--dry-run" {
+			return exec.Command("false")
+		}
+		return execDryRun(name, args...)
+	}
+
 	cat := types.Category{ID: "homebrew", Name: "Homebrew"}
 	s := NewBrewTarget(cat)
 	s.cachePath = cacheDir
@@ -107,7 +118,7 @@ func TestBrewTarget_Scan_WithMockCachePath(t *testing.T) {
 
 	assert.NoError(t, err)
 	assert.NotNil(t, result)
-	assert.Len(t, result.Items, 1)
+	require.Len(t, result.Items, 1)
 	assert.Equal(t, "Homebrew Cache", result.Items[0].Name)
 	assert.True(t, result.Items[0].IsDirectory)
 	assert.Greater(t, result.TotalSize, int64(0))
@@ -242,3 +253,125 @@ func TestBrewTarget_Clean_Success_WithMock(t *testing.T) {
 	assert.Equal(t, int64(1000), result.FreedSpace)
 	assert.Equal(t, 1, result.CleanedItems)
 }
+
+const brewDryRunOutput = `Would remove: /opt/homebrew/Cellar/node/20.1.0 (2,345 files, 58.2MB)
+Would remove: /Users/me/Library/Caches/Homebrew/downloads/0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef--firefox-120.0.dmg (130MB)
+Would remove: /opt/homebrew/var/homebrew/locks/node.formula.lock (0B)
+==> This operation would free approximately 188.2MB of disk space.
+`
+
+// stubBrew makes brew available and answers --cache, --prefix and the
+// cleanup dry run. It returns the brew commands run.
+func stubBrew(t *testing.T, dryRun string) *[]string {
+	t.Helper()
+	originalCommandExists := utils.CommandExists
+	originalExec := execCommand
+	t.Cleanup(func() {
+		utils.CommandExists = originalCommandExists
+		execCommand = originalExec
+	})
+
+	utils.CommandExists = func(name string) bool { return name == "brew" }
+	var calls []string
+	execCommand = func(_ string, args ...string) *exec.Cmd {
+		calls = append(calls, strings.Join(args, " "))
+		switch {
+		case len(args) == 1 && args[0] == "--cache":
+			return exec.Command("echo", "/Users/me/Library/Caches/Homebrew")
+		case len(args) == 1 && args[0] == "--prefix":
+			return exec.Command("echo", "/opt/homebrew")
+		case len(args) > 0 && args[len(args)-1] == "--dry-run":
+			return exec.Command("printf", "%s", dryRun)
+		}
+		return exec.Command("true")
+	}
+	return &calls
+}
+
+func TestBrewTarget_Scan_ItemizesCleanupDryRun(t *testing.T) {
+	stubBrew(t, brewDryRunOutput)
+
+	result, err := NewBrewTarget(types.Category{ID: "homebrew", Name: "Homebrew"}).Scan()
+
+	require.NoError(t, err)
+	require.Len(t, result.Items, 3)
+
+	keg := result.Items[0]
+	assert.Equal(t, "/opt/homebrew/Cellar/node/20.1.0", keg.Path)
+	assert.Equal(t, "Outdated keg: node 20.1.0", keg.Name)
+	assert.Equal(t, int64(2345), keg.FileCount)
+	assert.Equal(t, parseDockerSize("58.2MB"), keg.Size)
+
+	assert.Equal(t, "Cached download: firefox-120.0.dmg", result.Items[1].Name)
+	assert.Equal(t, parseDockerSize("130MB"), result.Items[1].Size)
+	assert.Equal(t, "Stale lock: node.formula.lock", result.Items[2].Name)
+
+	assert.Equal(t, keg.Size+result.Items[1].Size, result.TotalSize)
+	assert.Equal(t, int64(2347), result.TotalFileCount)
+}
+
+func TestBrewTarget_Scan_EmptyDryRunOffersNothing(t *testing.T) {
+	stubBrew(t, "")
+	s := NewBrewTarget(types.Category{ID: "homebrew", Name: "Homebrew"})
+	s.cachePath = t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(s.cachePath, "bottle.tar.gz"), []byte("data"), 0o644))
+
+	result, err := s.Scan()
+
+	require.NoError(t, err)
+	assert.Empty(t, result.Items, "brew has nothing to remove")
+	assert.Zero(t, result.TotalSize)
+}
+
+func TestBrewTarget_Clean_TrashesSelectedEntries(t *testing.T) {
+	calls := stubBrew(t, brewDryRunOutput)
+	originalMoveToTrashBatch := utils.MoveToTrashBatch
+	defer func() { utils.MoveToTrashBatch = originalMoveToTrashBatch }()
+	var trashed []string
+	utils.MoveToTrashBatch = func(paths []string) utils.TrashBatchResult {
+		trashed = append(trashed, paths...)
+		return utils.TrashBatchResult{Succeeded: paths, Failed: make(map[string]error)}
+	}
+
+	result, err := NewBrewTarget(types.Category{ID: "homebrew", Name: "Homebrew"}).Clean([]types.CleanableItem{
+		{Path: "/opt/homebrew/Cellar/node/20.1.0", Size: 100},
+		{Path: "/Users/me/Library/Caches/Homebrew/downloads/x--wget.bottle.tar.gz", Size: 10},
+		{Path: "/etc/hosts", Size: 1},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.CleanedItems)
+	assert.Equal(t, int64(110), result.FreedSpace)
+	require.Len(t, result.Errors, 1)
+	assert.Contains(t, result.Errors[0], "invalid path: /etc/hosts")
+	assert.NotContains(t, trashed, "/etc/hosts")
+	assert.NotContains(t, *calls, "cleanup --prune=all -s", "itemized entries don't run a full cleanup")
+}
+
+func TestBrewTarget_Clean_AcceptsLeftoversFromDryRun(t *testing.T) {
+	stubBrew(t, brewDryRunOutput+"Would remove: /Users/me/Library/Logs/Homebrew/wget (3 files, 12KB)\n")
+	originalMoveToTrashBatch := utils.MoveToTrashBatch
+	defer func() { utils.MoveToTrashBatch = originalMoveToTrashBatch }()
+	var trashed []string
+	utils.MoveToTrashBatch = func(paths []string) utils.TrashBatchResult {
+		trashed = append(trashed, paths...)
+		return utils.TrashBatchResult{Succeeded: paths, Failed: make(map[string]error)}
+	}
+
+	target := NewBrewTarget(types.Category{ID: "homebrew", Name: "Homebrew"})
+	scanned, err := target.Scan()
+	require.NoError(t, err)
+	leftover := scanned.Items[len(scanned.Items)-1]
+	require.Equal(t, "Leftover: wget", leftover.Name)
+
+	result, err := target.Clean([]types.CleanableItem{
+		leftover,
+		{Path: "/Users/me/Library/Logs/other", Size: 1},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.CleanedItems)
+	assert.Equal(t, []string{"/Users/me/Library/Logs/Homebrew/wget"}, trashed)
+	require.Len(t, result.Errors, 1)
+	assert.Contains(t, result.Errors[0], "invalid path: /Users/me/Library/Logs/other")
+}
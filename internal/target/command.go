@@ -0,0 +1,195 @@
+package target
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/2ykwang/mac-cleanup-go/internal/logger"
+	"github.com/2ykwang/mac-cleanup-go/internal/types"
+	"github.com/2ykwang/mac-cleanup-go/internal/utils"
+)
+
+const (
+	defaultCommandTimeout = 10 * time.Minute
+	sizeCommandTimeout    = 30 * time.Second
+
+	commandPathPrefix = "command:"
+)
+
+// IsCommandPath reports whether path is the pseudo-path of a command item,
+// which names no file on disk.
+func IsCommandPath(path string) bool {
+	return strings.HasPrefix(path, commandPathPrefix)
+}
+
+// CommandTarget cleans by running a user-declared command (e.g. `go clean -cache`).
+// The scan produces a single item whose size is estimated from size_cmd or paths,
+// or left unknown when neither is configured.
+type CommandTarget struct {
+	*PathTarget
+}
+
+func NewCommandTarget(cat types.Category) *CommandTarget {
+	return &CommandTarget{PathTarget: NewPathTarget(cat)}
+}
+
+// matchPaths returns nothing: command paths only estimate size, so they
+// never take ownership away from path-based categories.
+func (s *CommandTarget) matchPaths() []pathMatch {
+	return nil
+}
+
+func (s *CommandTarget) IsAvailable() bool {
+	for _, proc := range s.category.BlockedByProcesses {
+		if utils.IsProcessRunning(proc) {
+			return false
+		}
+	}
+	if s.category.CheckCmd != "" {
+		return utils.CommandExists(s.category.CheckCmd)
+	}
+	return len(s.category.Command) > 0 && utils.CommandExists(s.category.Command[0])
+}
+
+func (s *CommandTarget) Scan() (*types.ScanResult, error) {
+	result := types.NewScanResult(s.category)
+
+	if !s.IsAvailable() {
+		return result, nil
+	}
+
+	var size, fileCount int64
+	var modifiedAt time.Time
+	sizeUnknown := false
+	switch {
+	case len(s.category.SizeCmd) > 0:
+		estimated, err := s.estimateSize()
+		if err != nil {
+			result.Error = err
+			return result, nil
+		}
+		size = estimated
+	case len(s.category.Paths) == 0 && len(s.category.PathCmd) == 0:
+		// Nothing to estimate from; the command still runs when selected.
+		sizeUnknown = true
+	default:
+		items, total, count := s.scanPathsParallel(s.collectPaths())
+		size, fileCount = total, count
+		for _, item := range items {
+			if item.ModifiedAt.After(modifiedAt) {
+				modifiedAt = item.ModifiedAt
+			}
+		}
+	}
+
+	if size <= 0 && !sizeUnknown {
+		return result, nil
+	}
+
+	commandLine := strings.Join(s.category.Command, " ")
+	result.Items = append(result.Items, types.CleanableItem{
+		Path:        commandPathPrefix + s.category.ID,
+		Size:        size,
+		FileCount:   fileCount,
+		Name:        s.category.Name,
+		DisplayName: "$ " + commandLine,
+		ModifiedAt:  modifiedAt,
+		SizeUnknown: sizeUnknown,
+	})
+	result.TotalSize = size
+	result.TotalFileCount = fileCount
+
+	logger.Info("command scan completed",
+		"category", s.category.ID,
+		"command", commandLine,
+		"totalSize", size)
+
+	return result, nil
+}
+
+// estimateSize runs size_cmd and parses the first field of its output.
+// Accepts plain byte counts or sizes with a unit suffix (e.g. "1.2GB").
+func (s *CommandTarget) estimateSize() (int64, error) {
+	output, err := runCommand(s.category.SizeCmd, sizeCommandTimeout)
+	if err != nil {
+		logger.Warn("size command failed", "category", s.category.ID, "error", err)
+		return 0, fmt.Errorf("size_cmd: %w", err)
+	}
+	fields := strings.Fields(output)
+	if len(fields) == 0 {
+		return 0, nil
+	}
+	return parseDockerSize(fields[0]), nil
+}
+
+// Clean runs the command once for the whole category.
+func (s *CommandTarget) Clean(items []types.CleanableItem) (*types.CleanResult, error) {
+	result := types.NewCleanResult(s.category)
+
+	if len(items) == 0 {
+		return result, nil
+	}
+
+	timeout := s.category.Timeout
+	if timeout <= 0 {
+		timeout = defaultCommandTimeout
+	}
+
+	output, err := runCommand(s.category.Command, timeout)
+	result.Output = output
+	if err != nil {
+		logger.Warn("cleanup command failed", "category", s.category.ID, "error", err, "output", output)
+		msg := "command failed: " + err.Error()
+		if last := lastLine(output); last != "" {
+			msg += ": " + last
+		}
+		result.Errors = append(result.Errors, msg)
+		return result, nil
+	}
+
+	for _, item := range items {
+		result.FreedSpace += item.Size
+		result.CleanedItems++
+	}
+
+	logger.Info("command clean completed",
+		"category", s.category.ID,
+		"freedSpace", result.FreedSpace,
+		"output", output)
+
+	return result, nil
+}
+
+// runCommand executes argv with a timeout and returns its combined, trimmed output.
+func runCommand(argv []string, timeout time.Duration) (string, error) {
+	if len(argv) == 0 {
+		return "", errors.New("empty command")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var out bytes.Buffer
+	cmd := execCommandContext(ctx, argv[0], argv[1:]...)
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+
+	err := cmd.Run()
+	output := strings.TrimSpace(out.String())
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return output, fmt.Errorf("timeout after %v", timeout)
+	}
+	return output, err
+}
+
+func lastLine(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.LastIndex(s, "\n"); i >= 0 {
+		return strings.TrimSpace(s[i+1:])
+	}
+	return s
+}
@@ -0,0 +1,174 @@
+package target
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/2ykwang/mac-cleanup-go/internal/types"
+)
+
+func newCommandCategory(command, sizeCmd []string, paths []string) types.Category {
+	return types.Category{
+		ID:      "go-cache",
+		Name:    "Go Cache",
+		Method:  types.MethodCommand,
+		Safety:  types.SafetyLevelSafe,
+		Command: command,
+		SizeCmd: sizeCmd,
+		Paths:   paths,
+	}
+}
+
+func TestCommandTarget_IsAvailable_RequiresCommandBinary(t *testing.T) {
+	assert.True(t, NewCommandTarget(newCommandCategory([]string{"sh", "-c", "true"}, nil, nil)).IsAvailable())
+	assert.False(t, NewCommandTarget(newCommandCategory([]string{"nonexistent-command-xyz-123"}, nil, nil)).IsAvailable())
+	assert.False(t, NewCommandTarget(newCommandCategory(nil, nil, nil)).IsAvailable())
+}
+
+func TestCommandTarget_Scan_UsesSizeCmd(t *testing.T) {
+	cat := newCommandCategory([]string{"true"}, []string{"echo", "2048\t/some/path"}, nil)
+	s := NewCommandTarget(cat)
+
+	result, err := s.Scan()
+
+	require.NoError(t, err)
+	require.Len(t, result.Items, 1)
+	item := result.Items[0]
+	assert.Equal(t, "command:go-cache", item.Path)
+	assert.Equal(t, int64(2048), item.Size)
+	assert.Equal(t, "$ true", item.DisplayName)
+	assert.Equal(t, int64(2048), result.TotalSize)
+}
+
+func TestCommandTarget_Scan_ParsesSizeUnits(t *testing.T) {
+	cat := newCommandCategory([]string{"true"}, []string{"echo", "1MB"}, nil)
+
+	result, err := NewCommandTarget(cat).Scan()
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(1024*1024), result.TotalSize)
+}
+
+func TestCommandTarget_Scan_SizeCmdFailure_SetsError(t *testing.T) {
+	cat := newCommandCategory([]string{"true"}, []string{"false"}, nil)
+
+	result, err := NewCommandTarget(cat).Scan()
+
+	require.NoError(t, err)
+	assert.Error(t, result.Error)
+	assert.Empty(t, result.Items)
+}
+
+func TestCommandTarget_Scan_FallsBackToPaths(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "a.bin"), []byte("12345"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "b.bin"), []byte("678"), 0o644))
+
+	cat := newCommandCategory([]string{"true"}, nil, []string{filepath.Join(tmpDir, "*")})
+
+	result, err := NewCommandTarget(cat).Scan()
+
+	require.NoError(t, err)
+	require.Len(t, result.Items, 1)
+	assert.Equal(t, int64(8), result.TotalSize)
+	assert.Equal(t, int64(2), result.TotalFileCount)
+	assert.False(t, result.Items[0].ModifiedAt.IsZero())
+}
+
+func TestCommandTarget_Scan_ZeroSize_NoItems(t *testing.T) {
+	cat := newCommandCategory([]string{"true"}, []string{"echo", "0"}, nil)
+
+	result, err := NewCommandTarget(cat).Scan()
+
+	require.NoError(t, err)
+	assert.Empty(t, result.Items)
+}
+
+func TestCommandTarget_Scan_NoEstimator_EmitsUnknownSize(t *testing.T) {
+	cat := newCommandCategory([]string{"true"}, nil, nil)
+
+	result, err := NewCommandTarget(cat).Scan()
+
+	require.NoError(t, err)
+	require.Len(t, result.Items, 1)
+	assert.True(t, result.Items[0].SizeUnknown)
+	assert.Zero(t, result.Items[0].Size)
+	assert.True(t, result.HasUnknownSize())
+	assert.Zero(t, result.TotalSize)
+}
+
+func TestCommandTarget_Scan_EmptyPaths_NoItems(t *testing.T) {
+	cat := newCommandCategory([]string{"true"}, nil, []string{filepath.Join(t.TempDir(), "*")})
+
+	result, err := NewCommandTarget(cat).Scan()
+
+	require.NoError(t, err)
+	assert.Empty(t, result.Items)
+}
+
+func TestCommandTarget_Clean_CapturesOutput(t *testing.T) {
+	cat := newCommandCategory([]string{"sh", "-c", "echo pruned 3 packages"}, nil, nil)
+	s := NewCommandTarget(cat)
+
+	result, err := s.Clean([]types.CleanableItem{{Path: "command:go-cache", Size: 500}})
+
+	require.NoError(t, err)
+	assert.Empty(t, result.Errors)
+	assert.Equal(t, 1, result.CleanedItems)
+	assert.Equal(t, int64(500), result.FreedSpace)
+	assert.Equal(t, "pruned 3 packages", result.Output)
+}
+
+func TestCommandTarget_Clean_Failure_ReportsLastOutputLine(t *testing.T) {
+	cat := newCommandCategory([]string{"sh", "-c", "echo step one; echo disk busy >&2; exit 3"}, nil, nil)
+
+	result, err := NewCommandTarget(cat).Clean([]types.CleanableItem{{Path: "command:go-cache", Size: 500}})
+
+	require.NoError(t, err)
+	require.Len(t, result.Errors, 1)
+	assert.Contains(t, result.Errors[0], "exit status 3")
+	assert.Contains(t, result.Errors[0], "disk busy")
+	assert.Equal(t, 0, result.CleanedItems)
+	assert.Contains(t, result.Output, "step one")
+}
+
+func TestCommandTarget_Clean_Timeout(t *testing.T) {
+	cat := newCommandCategory([]string{"sleep", "5"}, nil, nil)
+	cat.Timeout = 50 * time.Millisecond
+
+	result, err := NewCommandTarget(cat).Clean([]types.CleanableItem{{Path: "command:go-cache", Size: 500}})
+
+	require.NoError(t, err)
+	require.Len(t, result.Errors, 1)
+	assert.Contains(t, result.Errors[0], "timeout")
+}
+
+func TestCommandTarget_Clean_NoItems_DoesNotRun(t *testing.T) {
+	marker := filepath.Join(t.TempDir(), "ran")
+	cat := newCommandCategory([]string{"touch", marker}, nil, nil)
+
+	result, err := NewCommandTarget(cat).Clean(nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.CleanedItems)
+	assert.NoFileExists(t, marker)
+}
+
+func TestDefaultRegistry_CommandMethod_UsesCommandTarget(t *testing.T) {
+	cfg := &types.Config{
+		Categories: []types.Category{newCommandCategory([]string{"true"}, nil, nil)},
+	}
+
+	registry, err := DefaultRegistry(cfg)
+
+	require.NoError(t, err)
+	tgt, ok := registry.Get("go-cache")
+	require.True(t, ok)
+	_, isCommand := tgt.(*CommandTarget)
+	assert.True(t, isCommand)
+}
@@ -0,0 +1,479 @@
+package target
+
+import (
+	"bytes"
+	"encoding/json"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/2ykwang/mac-cleanup-go/internal/logger"
+	"github.com/2ykwang/mac-cleanup-go/internal/types"
+	"github.com/2ykwang/mac-cleanup-go/internal/utils"
+)
+
+const (
+	runtimeAuto    = "auto"
+	runtimeDocker  = "docker"
+	runtimePodman  = "podman"
+	runtimeNerdctl = "nerdctl"
+)
+
+// runtimeDetectOrder is the order in which installed runtimes are preferred.
+var runtimeDetectOrder = []string{runtimeDocker, runtimePodman, runtimeNerdctl}
+
+// containerRuntime drives one container engine's CLI. Listings are
+// normalized to the shape of `docker system df -v` so the docker target can
+// build its items the same way for every engine.
+type containerRuntime interface {
+	// Name identifies the runtime (and context, if any) in logs.
+	Name() string
+	IsAvailable() bool
+	SystemDf() (*dockerDfVerbose, error)
+	// BuildCacheSize returns the reclaimable build cache, or 0 if unknown.
+	BuildCacheSize() int64
+	// BuildCacheRecords lists the build cache record by record. Runtimes
+	// that cannot return nil, and BuildCacheSize is used instead.
+	BuildCacheRecords() ([]buildCacheRecord, error)
+	// ImageLastTagged returns when each image was last tagged by a pull or
+	// build, where the runtime records it.
+	ImageLastTagged(ids []string) map[string]time.Time
+
+	// The remove commands return nil when the runtime cannot remove the resource.
+	RemoveContainer(id string) *exec.Cmd
+	RemoveImage(id string) *exec.Cmd
+	RemoveVolume(name string) *exec.Cmd
+	PruneBuildCache() *exec.Cmd
+	// RemoveBuildCacheRecord prunes one record; with unusedFor > 0, only
+	// while it has gone unused that long.
+	RemoveBuildCacheRecord(id string, unusedFor time.Duration) *exec.Cmd
+}
+
+// IsContainerRuntime reports whether name is a valid value for a category's runtime.
+func IsContainerRuntime(name string) bool {
+	switch name {
+	case "", runtimeAuto, runtimeDocker, runtimePodman, runtimeNerdctl:
+		return true
+	}
+	return false
+}
+
+// resolveRuntimeName returns the runtime configured for cat, or the first
+// installed one when it is unset or "auto". Contexts imply Docker, which is
+// also the fallback.
+func resolveRuntimeName(cat types.Category) string {
+	if cat.Runtime != "" && cat.Runtime != runtimeAuto {
+		return cat.Runtime
+	}
+	if len(cat.Contexts) > 0 {
+		return runtimeDocker
+	}
+	for _, name := range runtimeDetectOrder {
+		if utils.CommandExists(name) {
+			return name
+		}
+	}
+	return runtimeDocker
+}
+
+// newContainerRuntime returns the runtime for cat. A docker category with a
+// single context targets that context.
+func newContainerRuntime(cat types.Category) containerRuntime {
+	switch resolveRuntimeName(cat) {
+	case runtimePodman:
+		return &podmanRuntime{cli: containerCLI{binary: runtimePodman}}
+	case runtimeNerdctl:
+		return &nerdctlRuntime{cli: containerCLI{binary: runtimeNerdctl}}
+	default:
+		cli := containerCLI{binary: runtimeDocker}
+		if len(cat.Contexts) == 1 {
+			cli.globalArgs = []string{"--context", cat.Contexts[0]}
+		}
+		return &dockerRuntime{cli: cli}
+	}
+}
+
+// containerCLI runs a runtime binary with its global flags.
+type containerCLI struct {
+	binary     string
+	globalArgs []string
+}
+
+func (c containerCLI) command(args ...string) *exec.Cmd {
+	return execCommand(c.binary, append(append([]string{}, c.globalArgs...), args...)...)
+}
+
+func (c containerCLI) name() string {
+	if len(c.globalArgs) == 0 {
+		return c.binary
+	}
+	return c.binary + " " + strings.Join(c.globalArgs, " ")
+}
+
+func (c containerCLI) isAvailable() bool {
+	if !utils.CommandExists(c.binary) {
+		logger.Debug("container runtime not found", "runtime", c.binary)
+		return false
+	}
+	if err := c.command("version").Run(); err != nil {
+		logger.Warn("container runtime not running", "runtime", c.name(), "error", err)
+		return false
+	}
+	return true
+}
+
+// dockerRuntime is the Docker CLI, optionally pinned to a context.
+type dockerRuntime struct {
+	cli containerCLI
+}
+
+func (r *dockerRuntime) Name() string      { return r.cli.name() }
+func (r *dockerRuntime) IsAvailable() bool { return r.cli.isAvailable() }
+
+func (r *dockerRuntime) SystemDf() (*dockerDfVerbose, error) {
+	output, err := r.cli.command("system", "df", "-v", "--format", "{{json .}}").Output()
+	if err != nil {
+		logger.Warn("docker system df -v failed", "error", err)
+		return nil, err
+	}
+
+	line := strings.TrimSpace(string(output))
+	if line == "" {
+		return &dockerDfVerbose{}, nil
+	}
+
+	var df dockerDfVerbose
+	if err := json.Unmarshal([]byte(line), &df); err != nil {
+		logger.Warn("docker df -v json parse failed", "error", err)
+		return nil, err
+	}
+	return &df, nil
+}
+
+func (r *dockerRuntime) BuildCacheSize() int64 {
+	output, err := r.cli.command("system", "df", "--format", "{{json .}}").Output()
+	if err != nil {
+		logger.Warn("docker system df failed", "error", err)
+		return 0
+	}
+
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if line == "" {
+			continue
+		}
+		var df dockerDfOutput
+		if err := json.Unmarshal([]byte(line), &df); err != nil {
+			continue
+		}
+		if strings.EqualFold(df.Type, "Build Cache") {
+			return parseDockerSize(df.Reclaimable)
+		}
+	}
+	return 0
+}
+
+func (r *dockerRuntime) BuildCacheRecords() ([]buildCacheRecord, error) {
+	output, err := r.cli.command("buildx", "du", "--verbose").Output()
+	if err != nil {
+		logger.Debug("docker buildx du failed", "error", err)
+		return nil, err
+	}
+	return parseBuildxDu(string(output), time.Now()), nil
+}
+
+func (r *dockerRuntime) ImageLastTagged(ids []string) map[string]time.Time {
+	if len(ids) == 0 {
+		return nil
+	}
+	args := append([]string{"image", "inspect", "--format", `{{.Id}} {{.Metadata.LastTagTime.Format "2006-01-02T15:04:05Z07:00"}}`}, ids...)
+	// Inspect fails if an image vanished since the listing, but still prints the rest.
+	output, err := r.cli.command(args...).Output()
+	if err != nil {
+		logger.Debug("docker image inspect failed", "error", err)
+	}
+
+	tagged := make(map[string]time.Time, len(ids))
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		id, stamp, ok := strings.Cut(strings.TrimSpace(line), " ")
+		if !ok {
+			continue
+		}
+		if t, err := time.Parse(time.RFC3339, stamp); err == nil && t.Year() > 1 {
+			tagged[id] = t
+		}
+	}
+	return tagged
+}
+
+func (r *dockerRuntime) RemoveContainer(id string) *exec.Cmd {
+	return r.cli.command("rm", id)
+}
+
+func (r *dockerRuntime) RemoveImage(id string) *exec.Cmd {
+	return r.cli.command("image", "rm", id)
+}
+
+func (r *dockerRuntime) RemoveVolume(name string) *exec.Cmd {
+	return r.cli.command("volume", "rm", name)
+}
+
+func (r *dockerRuntime) PruneBuildCache() *exec.Cmd {
+	return r.cli.command("builder", "prune", "-af")
+}
+
+func (r *dockerRuntime) RemoveBuildCacheRecord(id string, unusedFor time.Duration) *exec.Cmd {
+	args := []string{"buildx", "prune", "--force", "--filter", "id=" + id}
+	if hours := int(unusedFor.Hours()); hours > 0 {
+		// A record used again since the scan is kept.
+		args = append(args, "--filter", "until="+strconv.Itoa(hours)+"h")
+	}
+	return r.cli.command(args...)
+}
+
+// podmanRuntime is the Podman CLI. Podman cannot print `system df -v` as
+// JSON, so images and containers are listed separately. Volume sizes and
+// build cache are not reported, so neither is offered.
+type podmanRuntime struct {
+	cli containerCLI
+}
+
+type podmanImage struct {
+	ID      string   `json:"Id"`
+	Names   []string `json:"Names"`
+	Size    int64    `json:"Size"`
+	Created int64    `json:"Created"`
+}
+
+type podmanContainer struct {
+	ID      string   `json:"Id"`
+	Image   string   `json:"Image"`
+	Names   []string `json:"Names"`
+	State   string   `json:"State"`
+	Status  string   `json:"Status"`
+	Created int64    `json:"Created"`
+	Size    *struct {
+		RwSize int64 `json:"rwSize"`
+	} `json:"Size"`
+}
+
+func (r *podmanRuntime) Name() string      { return r.cli.name() }
+func (r *podmanRuntime) IsAvailable() bool { return r.cli.isAvailable() }
+
+func (r *podmanRuntime) SystemDf() (*dockerDfVerbose, error) {
+	var images []podmanImage
+	if err := r.listJSON(&images, "images", "--format", "json"); err != nil {
+		return nil, err
+	}
+	var containers []podmanContainer
+	if err := r.listJSON(&containers, "ps", "-a", "--size", "--format", "json"); err != nil {
+		return nil, err
+	}
+
+	df := &dockerDfVerbose{}
+	for _, img := range images {
+		base := dockerDfImage{ID: img.ID, Size: strconv.FormatInt(img.Size, 10) + "B"}
+		if img.Created > 0 {
+			base.CreatedAt = time.Unix(img.Created, 0).Format(dockerTimeLayout)
+		}
+		if len(img.Names) == 0 {
+			df.Images = append(df.Images, base)
+			continue
+		}
+		for _, ref := range img.Names {
+			named := base
+			named.Repository, named.Tag = splitImageRef(ref)
+			df.Images = append(df.Images, named)
+		}
+	}
+	for _, c := range containers {
+		dc := dockerDfContainer{
+			ID:     c.ID,
+			Image:  c.Image,
+			State:  c.State,
+			Status: c.Status,
+		}
+		if len(c.Names) > 0 {
+			dc.Names = c.Names[0]
+		}
+		if c.Size != nil {
+			dc.Size = strconv.FormatInt(c.Size.RwSize, 10) + "B"
+		}
+		if c.Created > 0 {
+			dc.CreatedAt = time.Unix(c.Created, 0).Format(dockerTimeLayout)
+		}
+		df.Containers = append(df.Containers, dc)
+	}
+	return df, nil
+}
+
+func (r *podmanRuntime) listJSON(v any, args ...string) error {
+	output, err := r.cli.command(args...).Output()
+	if err != nil {
+		logger.Warn("podman list failed", "command", args[0], "error", err)
+		return err
+	}
+	if len(bytes.TrimSpace(output)) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(output, v); err != nil {
+		logger.Warn("podman json parse failed", "command", args[0], "error", err)
+		return err
+	}
+	return nil
+}
+
+func (r *podmanRuntime) BuildCacheSize() int64 { return 0 }
+
+func (r *podmanRuntime) BuildCacheRecords() ([]buildCacheRecord, error) { return nil, nil }
+
+func (r *podmanRuntime) ImageLastTagged([]string) map[string]time.Time { return nil }
+
+func (r *podmanRuntime) RemoveContainer(id string) *exec.Cmd {
+	return r.cli.command("rm", id)
+}
+
+func (r *podmanRuntime) RemoveImage(id string) *exec.Cmd {
+	return r.cli.command("rmi", id)
+}
+
+func (r *podmanRuntime) RemoveVolume(name string) *exec.Cmd {
+	return r.cli.command("volume", "rm", name)
+}
+
+func (r *podmanRuntime) PruneBuildCache() *exec.Cmd {
+	return nil
+}
+
+func (r *podmanRuntime) RemoveBuildCacheRecord(string, time.Duration) *exec.Cmd {
+	return nil
+}
+
+// nerdctlRuntime is the containerd CLI used by Colima and Rancher Desktop.
+// Its listings print docker-compatible JSON lines. The build cache lives in
+// BuildKit and has no size report, so it is not offered.
+type nerdctlRuntime struct {
+	cli containerCLI
+}
+
+// nerdctlVolume tolerates volume sizes printed as byte counts or strings.
+type nerdctlVolume struct {
+	Name string          `json:"Name"`
+	Size json.RawMessage `json:"Size"`
+}
+
+func (r *nerdctlRuntime) Name() string      { return r.cli.name() }
+func (r *nerdctlRuntime) IsAvailable() bool { return r.cli.isAvailable() }
+
+func (r *nerdctlRuntime) SystemDf() (*dockerDfVerbose, error) {
+	df := &dockerDfVerbose{}
+	if err := listLines(r.cli, "images", &df.Images, "images", "--format", "{{json .}}"); err != nil {
+		return nil, err
+	}
+	if err := listLines(r.cli, "containers", &df.Containers, "ps", "-a", "--size", "--format", "{{json .}}"); err != nil {
+		return nil, err
+	}
+	var volumes []nerdctlVolume
+	if err := listLines(r.cli, "volumes", &volumes, "volume", "ls", "--size", "--format", "{{json .}}"); err != nil {
+		return nil, err
+	}
+
+	for i := range df.Containers {
+		c := &df.Containers[i]
+		if c.State == "" {
+			c.State = stateFromStatus(c.Status)
+		}
+	}
+	for _, v := range volumes {
+		df.Volumes = append(df.Volumes, dockerDfVolume{Name: v.Name, Size: rawSize(v.Size)})
+	}
+	return df, nil
+}
+
+func (r *nerdctlRuntime) BuildCacheSize() int64 { return 0 }
+
+func (r *nerdctlRuntime) BuildCacheRecords() ([]buildCacheRecord, error) { return nil, nil }
+
+func (r *nerdctlRuntime) ImageLastTagged([]string) map[string]time.Time { return nil }
+
+func (r *nerdctlRuntime) RemoveContainer(id string) *exec.Cmd {
+	return r.cli.command("rm", id)
+}
+
+func (r *nerdctlRuntime) RemoveImage(id string) *exec.Cmd {
+	return r.cli.command("rmi", id)
+}
+
+func (r *nerdctlRuntime) RemoveVolume(name string) *exec.Cmd {
+	return r.cli.command("volume", "rm", name)
+}
+
+func (r *nerdctlRuntime) PruneBuildCache() *exec.Cmd {
+	return r.cli.command("builder", "prune", "-af")
+}
+
+func (r *nerdctlRuntime) RemoveBuildCacheRecord(string, time.Duration) *exec.Cmd {
+	return nil
+}
+
+// listLines runs a listing that prints one JSON object per line and appends
+// each decoded line to out. Lines that fail to parse are skipped.
+func listLines[T any](cli containerCLI, what string, out *[]T, args ...string) error {
+	output, err := cli.command(args...).Output()
+	if err != nil {
+		logger.Warn("container list failed", "runtime", cli.name(), "list", what, "error", err)
+		return err
+	}
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if line == "" {
+			continue
+		}
+		var v T
+		if err := json.Unmarshal([]byte(line), &v); err != nil {
+			logger.Debug("container list line skipped", "list", what, "error", err)
+			continue
+		}
+		*out = append(*out, v)
+	}
+	return nil
+}
+
+// stateFromStatus derives a container state from a status line such as
+// "Exited (0) 2 hours ago" for runtimes that do not print the state.
+func stateFromStatus(status string) string {
+	fields := strings.Fields(status)
+	if len(fields) == 0 {
+		return ""
+	}
+	switch strings.ToLower(fields[0]) {
+	case "up":
+		return "running"
+	case "exited", "created", "dead", "paused":
+		return strings.ToLower(fields[0])
+	}
+	return ""
+}
+
+// rawSize renders a JSON size value, either a byte count or a string, in
+// the form parseDockerSize accepts.
+func rawSize(raw json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	var n int64
+	if err := json.Unmarshal(raw, &n); err == nil {
+		return strconv.FormatInt(n, 10) + "B"
+	}
+	return ""
+}
+
+// splitImageRef splits "registry/repo:tag" into repository and tag. A
+// reference without a tag, or with a digest, gets an empty tag.
+func splitImageRef(ref string) (string, string) {
+	if strings.Contains(ref, "@") || !dockerHasTag(ref) {
+		return ref, ""
+	}
+	i := strings.LastIndex(ref, ":")
+	return ref[:i], ref[i+1:]
+}
@@ -0,0 +1,247 @@
+package target
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/2ykwang/mac-cleanup-go/internal/types"
+	"github.com/2ykwang/mac-cleanup-go/internal/utils"
+)
+
+// stubRuntimeCLI makes the listed binaries installed and answers commands by
+// their joined argv; unknown commands succeed with no output. It returns the
+// commands run, as "binary args...".
+func stubRuntimeCLI(t *testing.T, installed []string, outputs map[string]string) *[]string {
+	t.Helper()
+
+	originalCommandExists := utils.CommandExists
+	originalExec := execCommand
+	t.Cleanup(func() {
+		utils.CommandExists = originalCommandExists
+		execCommand = originalExec
+	})
+
+	utils.CommandExists = func(name string) bool {
+		for _, n := range installed {
+			if n == name {
+				return true
+			}
+		}
+		return false
+	}
+	var calls []string
+	execCommand = func(name string, args ...string) *exec.Cmd {
+		call := strings.Join(append([]string{name}, args...), " ")
+		calls = append(calls, call)
+		if out, ok := outputs[call]; ok {
+			return exec.Command("printf", "%s", out)
+		}
+		return exec.Command("true")
+	}
+	return &calls
+}
+
+func TestResolveRuntimeName(t *testing.T) {
+	tests := []struct {
+		name      string
+		cat       types.Category
+		installed []string
+		want      string
+	}{
+		{"configured", types.Category{Runtime: "nerdctl"}, []string{"docker"}, runtimeNerdctl},
+		{"auto prefers docker", types.Category{Runtime: "auto"}, []string{"podman", "docker"}, runtimeDocker},
+		{"detects podman", types.Category{}, []string{"podman"}, runtimePodman},
+		{"detects nerdctl", types.Category{}, []string{"nerdctl"}, runtimeNerdctl},
+		{"contexts imply docker", types.Category{Contexts: []string{"colima"}}, []string{"podman"}, runtimeDocker},
+		{"falls back to docker", types.Category{}, nil, runtimeDocker},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stubRuntimeCLI(t, tt.installed, nil)
+			assert.Equal(t, tt.want, resolveRuntimeName(tt.cat))
+		})
+	}
+}
+
+func TestIsContainerRuntime(t *testing.T) {
+	for _, name := range []string{"", "auto", "docker", "podman", "nerdctl"} {
+		assert.True(t, IsContainerRuntime(name), name)
+	}
+	assert.False(t, IsContainerRuntime("containerd"))
+}
+
+func TestDockerTarget_Context_PassesContextFlag(t *testing.T) {
+	calls := stubRuntimeCLI(t, []string{"docker"}, nil)
+
+	s := NewDockerTarget(types.Category{ID: "docker@colima", Contexts: []string{"colima"}})
+	assert.True(t, s.IsAvailable())
+	_, err := s.Clean([]types.CleanableItem{{Path: dockerPathPrefixVolume + "data"}})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"docker --context colima version",
+		"docker --context colima volume rm data",
+	}, *calls)
+}
+
+func TestPodmanRuntime_Scan(t *testing.T) {
+	stubRuntimeCLI(t, []string{"podman"}, map[string]string{
+		"podman images --format json": `[
+			{"Id":"aaa111","Names":["docker.io/library/nginx:latest","localhost/web:v1"],"Size":2048},
+			{"Id":"bbb222","Names":null,"Size":1024}
+		]`,
+		"podman ps -a --size --format json": `[
+			{"Id":"c1","Image":"docker.io/library/nginx:latest","Names":["web"],"State":"exited",
+			 "Status":"Exited (0) 2 days ago","Created":1700000000,"Size":{"rootFsSize":9000,"rwSize":300}}
+		]`,
+	})
+
+	s := NewDockerTarget(types.Category{ID: "docker", Name: "Docker"})
+	result, err := s.Scan()
+
+	require.NoError(t, err)
+	require.NoError(t, result.Error)
+	require.Len(t, result.Items, 3)
+
+	container := result.Items[0]
+	assert.Equal(t, dockerPathPrefixContainer+"c1", container.Path)
+	assert.Equal(t, int64(300), container.Size)
+	assert.Equal(t, int64(1700000000), container.ModifiedAt.Unix())
+
+	image := result.Items[1]
+	assert.Equal(t, dockerPathPrefixImage+"aaa111", image.Path)
+	assert.Equal(t, "Image: docker.io/library/nginx:latest (+1 tags)", image.Name)
+	assert.Contains(t, image.DisplayName, "web (stopped)")
+	assert.True(t, image.Status.Cleanable())
+
+	assert.Equal(t, "Image: untagged@bbb222", result.Items[2].Name)
+}
+
+func TestPodmanRuntime_Clean(t *testing.T) {
+	calls := stubRuntimeCLI(t, []string{"podman"}, nil)
+
+	s := NewDockerTarget(types.Category{ID: "docker", Runtime: "podman"})
+	result, err := s.Clean([]types.CleanableItem{
+		{Path: dockerPathPrefixImage + "aaa111", Size: 10},
+		{Path: dockerPathPrefixContainer + "c1", Size: 1},
+		{Path: dockerPathBuildCache, Size: 5},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.CleanedItems)
+	assert.Equal(t, []string{"podman rm c1", "podman rmi aaa111"}, *calls)
+}
+
+func TestNerdctlRuntime_Scan(t *testing.T) {
+	stubRuntimeCLI(t, []string{"nerdctl"}, map[string]string{
+		"nerdctl images --format {{json .}}": `{"ID":"img1","Repository":"alpine","Tag":"3.19","Size":"7.5 MiB"}` + "\n" +
+			`not json`,
+		"nerdctl ps -a --size --format {{json .}}": `{"ID":"c1","Image":"alpine:3.19","Names":"job","Status":"Exited (0) 3 hours ago","Size":"1.0 KiB"}` + "\n" +
+			`{"ID":"c2","Image":"redis:7","Names":"cache","Status":"Up 2 hours","Mounts":"redis-data"}`,
+		"nerdctl volume ls --size --format {{json .}}": `{"Name":"redis-data","Size":4096}` + "\n" +
+			`{"Name":"old","Size":"2 MiB"}`,
+	})
+
+	s := NewDockerTarget(types.Category{ID: "docker", Name: "Docker", Runtime: "nerdctl"})
+	result, err := s.Scan()
+
+	require.NoError(t, err)
+	require.NoError(t, result.Error)
+	byPath := make(map[string]types.CleanableItem)
+	for _, item := range result.Items {
+		byPath[item.Path] = item
+	}
+	require.Len(t, byPath, 4)
+
+	assert.Equal(t, int64(1024), byPath[dockerPathPrefixContainer+"c1"].Size)
+	assert.NotContains(t, byPath, dockerPathPrefixContainer+"c2")
+	assert.Equal(t, int64(7.5*1024*1024), byPath[dockerPathPrefixImage+"img1"].Size)
+	assert.Equal(t, types.ItemStatusProcessLocked, byPath[dockerPathPrefixVolume+"redis-data"].Status)
+	assert.Equal(t, int64(4096), byPath[dockerPathPrefixVolume+"redis-data"].Size)
+	assert.Equal(t, int64(2*1024*1024), byPath[dockerPathPrefixVolume+"old"].Size)
+}
+
+func TestStateFromStatus(t *testing.T) {
+	assert.Equal(t, "running", stateFromStatus("Up 5 minutes"))
+	assert.Equal(t, "exited", stateFromStatus("Exited (137) 1 day ago"))
+	assert.Equal(t, "created", stateFromStatus("Created"))
+	assert.Equal(t, "", stateFromStatus(""))
+}
+
+func TestSplitImageRef(t *testing.T) {
+	tests := []struct{ ref, repo, tag string }{
+		{"docker.io/library/nginx:latest", "docker.io/library/nginx", "latest"},
+		{"localhost:5000/app", "localhost:5000/app", ""},
+		{"alpine@sha256:abc", "alpine@sha256:abc", ""},
+	}
+	for _, tt := range tests {
+		repo, tag := splitImageRef(tt.ref)
+		assert.Equal(t, tt.repo, repo, tt.ref)
+		assert.Equal(t, tt.tag, tag, tt.ref)
+	}
+}
+
+func TestExpandDockerContexts_DefaultsToCurrentContext(t *testing.T) {
+	calls := stubRuntimeCLI(t, []string{"docker"}, nil)
+
+	cats := expandDockerContexts([]types.Category{
+		{ID: "trash", Name: "Trash"},
+		{ID: "docker", Name: "Docker", Method: types.MethodBuiltin},
+	})
+
+	require.Len(t, cats, 2)
+	assert.Equal(t, "docker", cats[1].ID)
+	assert.Equal(t, "Docker", cats[1].Name)
+	assert.Empty(t, cats[1].Contexts)
+	assert.Empty(t, *calls, "building the registry runs no commands")
+}
+
+func TestExpandDockerContexts_LeavesSingleContext(t *testing.T) {
+	cats := expandDockerContexts([]types.Category{{ID: "docker", Name: "Docker", Contexts: []string{"colima"}}})
+
+	require.Len(t, cats, 1)
+	assert.Equal(t, "Docker", cats[0].Name)
+	assert.Equal(t, []string{"colima"}, cats[0].Contexts)
+}
+
+func TestDefaultRegistry_RegistersConfiguredContexts(t *testing.T) {
+	stubRuntimeCLI(t, nil, nil)
+
+	cfg := &types.Config{Categories: []types.Category{{
+		ID:       "docker",
+		Name:     "Docker",
+		Method:   types.MethodBuiltin,
+		Contexts: []string{"desktop-linux", "ci"},
+	}}}
+	r, err := DefaultRegistry(cfg)
+
+	require.NoError(t, err)
+	require.Len(t, cfg.Categories, 1, "caller's config is left as written")
+	assert.Equal(t, []string{"desktop-linux", "ci"}, cfg.Categories[0].Contexts)
+	ctx, ok := r.Get("docker@ci")
+	require.True(t, ok)
+	assert.IsType(t, &DockerTarget{}, ctx)
+	assert.Equal(t, "Docker (ci)", ctx.Category().Name)
+	_, ok = r.Get("docker")
+	assert.True(t, ok)
+}
+
+func TestExpandConfig_CopiesCategories(t *testing.T) {
+	cfg := &types.Config{Categories: []types.Category{{
+		ID:       "docker",
+		Name:     "Docker",
+		Contexts: []string{"desktop-linux", "ci"},
+	}}}
+
+	expanded := ExpandConfig(cfg)
+
+	require.Len(t, expanded.Categories, 2)
+	assert.Equal(t, "docker@ci", expanded.Categories[1].ID)
+	require.Len(t, cfg.Categories, 1)
+	assert.Equal(t, "Docker", cfg.Categories[0].Name)
+	assert.Len(t, ExpandConfig(expanded).Categories, 2, "expanding twice changes nothing")
+}
@@ -3,4 +3,7 @@ package target
 import "os/exec"
 
 // Function variables for testing
-var execCommand = exec.Command
+var (
+	execCommand        = exec.Command
+	execCommandContext = exec.CommandContext
+)
@@ -1,33 +1,46 @@
 package target
 
 import (
-	"encoding/json"
 	"fmt"
 	"os/exec"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/2ykwang/mac-cleanup-go/internal/logger"
 	"github.com/2ykwang/mac-cleanup-go/internal/types"
-	"github.com/2ykwang/mac-cleanup-go/internal/utils"
 )
 
 type DockerTarget struct {
 	category types.Category
+	runtime  containerRuntime
+
+	mu sync.Mutex
+	// heldBy maps image and volume items to the stopped containers still
+	// holding them, from the last scan. Clean only removes such an item
+	// once all of those containers were removed in the same run.
+	heldBy map[string][]string
 }
 
 const (
 	dockerShortIDLength = 12
 	dockerNameLimit     = 50
 
-	dockerPathPrefixImage  = "docker:image:"
-	dockerPathPrefixVolume = "docker:volume:"
-	dockerPathBuildCache   = "docker:build-cache"
+	dockerPathPrefixImage     = "docker:image:"
+	dockerPathPrefixVolume    = "docker:volume:"
+	dockerPathPrefixContainer = "docker:container:"
+	dockerPathBuildCache      = "docker:build-cache"
+	// dockerPathPrefixBuildCache marks a single BuildKit cache record.
+	dockerPathPrefixBuildCache = "docker:build-cache:"
+
+	// dockerTimeLayout is the CreatedAt format printed by docker's JSON templates.
+	dockerTimeLayout = "2006-01-02 15:04:05 -0700 MST"
 )
 
 func NewDockerTarget(cat types.Category) *DockerTarget {
-	return &DockerTarget{category: cat}
+	return &DockerTarget{category: cat, runtime: newContainerRuntime(cat)}
 }
 
 func (s *DockerTarget) Category() types.Category {
@@ -35,16 +48,7 @@ func (s *DockerTarget) Category() types.Category {
 }
 
 func (s *DockerTarget) IsAvailable() bool {
-	if !utils.CommandExists("docker") {
-		logger.Debug("docker command not found")
-		return false
-	}
-	cmd := execCommand("docker", "version")
-	if err := cmd.Run(); err != nil {
-		logger.Warn("docker daemon not running", "error", err)
-		return false
-	}
-	return true
+	return s.runtime.IsAvailable()
 }
 
 type dockerDfOutput struct {
@@ -62,14 +66,67 @@ type dockerDfVerbose struct {
 }
 
 type dockerImageAggregate struct {
-	ID   string
-	Size int64
-	Tags map[string]struct{}
+	ID        string
+	Size      int64
+	Tags      map[string]struct{}
+	CreatedAt time.Time
 }
 
 type containerUsage struct {
 	imageUsedBy  map[string]map[string]struct{}
 	volumeUsedBy map[string]map[string]struct{}
+	// stopped holds the names of containers that are not running. Resources
+	// used only by stopped containers can be cleaned once those are removed.
+	stopped map[string]struct{}
+	// created maps container names to their creation time, the last time
+	// their image was put to use.
+	created map[string]time.Time
+	// ids maps container names to container IDs.
+	ids map[string]string
+	// heldBy collects, per image or volume item path, the IDs of the stopped
+	// containers holding it.
+	heldBy map[string][]string
+}
+
+// lockedBy reports whether any of the named containers is still running.
+func (u containerUsage) lockedBy(names map[string]struct{}) bool {
+	for name := range names {
+		if _, ok := u.stopped[name]; !ok {
+			return true
+		}
+	}
+	return false
+}
+
+// hold records that the item at path can only be removed after the named
+// (stopped) containers. Items held by a running container are locked instead.
+func (u containerUsage) hold(path string, names map[string]struct{}) {
+	for _, name := range usedByList(names) {
+		if id := u.ids[name]; id != "" {
+			u.heldBy[path] = append(u.heldBy[path], id)
+		}
+	}
+}
+
+// lastCreated returns the newest creation time among the named containers.
+func (u containerUsage) lastCreated(names map[string]struct{}) time.Time {
+	var latest time.Time
+	for name := range names {
+		if t := u.created[name]; t.After(latest) {
+			latest = t
+		}
+	}
+	return latest
+}
+
+// labels marks stopped containers in a list of container names for display.
+func (u containerUsage) labels(names []string) []string {
+	for i, name := range names {
+		if _, ok := u.stopped[name]; ok {
+			names[i] = name + " (stopped)"
+		}
+	}
+	return names
 }
 
 type dockerDfImage struct {
@@ -78,12 +135,28 @@ type dockerDfImage struct {
 	Tag        string `json:"Tag"`
 	Size       string `json:"Size"`
 	UniqueSize string `json:"UniqueSize"`
+	CreatedAt  string `json:"CreatedAt"`
 }
 
 type dockerDfContainer struct {
-	Image  string `json:"Image"`
-	Names  string `json:"Names"`
-	Mounts string `json:"Mounts"`
+	ID        string `json:"ID"`
+	Image     string `json:"Image"`
+	Names     string `json:"Names"`
+	Mounts    string `json:"Mounts"`
+	Size      string `json:"Size"`
+	State     string `json:"State"`
+	Status    string `json:"Status"`
+	CreatedAt string `json:"CreatedAt"`
+}
+
+// isStopped reports whether the container has exited or never started.
+// Containers with an unknown state are treated as running.
+func (c dockerDfContainer) isStopped() bool {
+	switch strings.ToLower(strings.TrimSpace(c.State)) {
+	case "exited", "created", "dead":
+		return true
+	}
+	return false
 }
 
 type dockerDfVolume struct {
@@ -98,18 +171,24 @@ func (s *DockerTarget) Scan() (*types.ScanResult, error) {
 		return result, nil
 	}
 
-	verbose, err := s.fetchVerboseDf()
+	verbose, err := s.runtime.SystemDf()
 	if err != nil {
 		result.Error = err
 		return result, nil
 	}
 
 	usage := buildContainerUsage(verbose)
-	appendImageItems(result, verbose.Images, usage.imageUsedBy)
-	appendVolumeItems(result, verbose.Volumes, usage.volumeUsedBy)
+	appendContainerItems(result, verbose.Containers)
+	appendImageItems(result, verbose.Images, usage, s.runtime.ImageLastTagged(imageIDs(verbose.Images)), newImageFilter(s.category))
+	appendVolumeItems(result, verbose.Volumes, usage)
 	s.appendBuildCache(result)
 
+	s.mu.Lock()
+	s.heldBy = usage.heldBy
+	s.mu.Unlock()
+
 	logger.Info("docker scan completed",
+		"runtime", s.runtime.Name(),
 		"resourceTypes", len(result.Items),
 		"totalSize", result.TotalSize)
 
@@ -127,13 +206,24 @@ func buildContainerUsage(verbose *dockerDfVerbose) containerUsage {
 	usage := containerUsage{
 		imageUsedBy:  make(map[string]map[string]struct{}),
 		volumeUsedBy: make(map[string]map[string]struct{}),
+		stopped:      make(map[string]struct{}),
+		created:      make(map[string]time.Time),
+		ids:          make(map[string]string),
+		heldBy:       make(map[string][]string),
 	}
 
 	for _, c := range verbose.Containers {
-		name := strings.TrimPrefix(strings.TrimSpace(c.Names), "/")
+		name := containerName(c)
 		if name == "" {
 			continue
 		}
+		if c.isStopped() {
+			usage.stopped[name] = struct{}{}
+		}
+		usage.ids[name] = c.ID
+		if created, ok := parseDockerTime(c.CreatedAt); ok {
+			usage.created[name] = created
+		}
 		imageRef := strings.TrimSpace(c.Image)
 		if imageRef != "" {
 			addUsedBy(usage.imageUsedBy, imageRef, name)
@@ -186,6 +276,9 @@ func aggregateImages(images []dockerDfImage) map[string]*dockerImageAggregate {
 		} else if size > agg.Size {
 			agg.Size = size
 		}
+		if created, ok := parseDockerTime(img.CreatedAt); ok && created.After(agg.CreatedAt) {
+			agg.CreatedAt = created
+		}
 
 		repo := strings.TrimSpace(img.Repository)
 		tag := strings.TrimSpace(img.Tag)
@@ -196,7 +289,55 @@ func aggregateImages(images []dockerDfImage) map[string]*dockerImageAggregate {
 	return aggregates
 }
 
-func appendImageItems(result *types.ScanResult, images []dockerDfImage, imageUsedBy map[string]map[string]struct{}) {
+// appendContainerItems offers stopped containers. Removing them releases
+// the images and volumes they hold.
+func appendContainerItems(result *types.ScanResult, containers []dockerDfContainer) {
+	for _, c := range containers {
+		if c.ID == "" || !c.isStopped() {
+			continue
+		}
+		name := containerName(c)
+		if name == "" {
+			name = shortDockerID(c.ID)
+		}
+		baseName := "Container: " + truncateName(name, dockerNameLimit)
+		displayName := baseName
+		if status := strings.TrimSpace(c.Status); status != "" {
+			displayName = fmt.Sprintf("%s (%s)", baseName, status)
+		}
+
+		item := appendDockerItem(result, dockerPathPrefixContainer+c.ID, parseDockerSize(c.Size), baseName, displayName, false)
+		if created, ok := parseDockerTime(c.CreatedAt); ok {
+			item.ModifiedAt = created
+		}
+	}
+}
+
+// imageFilter limits which images are offered, for unattended cleanups.
+type imageFilter struct {
+	// danglingOnly offers only untagged images.
+	danglingOnly bool
+	// olderThan skips images created, tagged or used by a container after
+	// it, and images whose age is unknown. Zero disables.
+	olderThan time.Time
+}
+
+func newImageFilter(cat types.Category) imageFilter {
+	f := imageFilter{danglingOnly: cat.DanglingOnly}
+	if cat.MinAgeDays > 0 {
+		f.olderThan = time.Now().AddDate(0, 0, -cat.MinAgeDays)
+	}
+	return f
+}
+
+func (f imageFilter) skip(tags []string, lastUsed time.Time) bool {
+	if f.danglingOnly && len(tags) > 0 {
+		return true
+	}
+	return !f.olderThan.IsZero() && (lastUsed.IsZero() || lastUsed.After(f.olderThan))
+}
+
+func appendImageItems(result *types.ScanResult, images []dockerDfImage, usage containerUsage, lastTagged map[string]time.Time, filter imageFilter) {
 	imageAggregates := aggregateImages(images)
 
 	imageIDs := make([]string, 0, len(imageAggregates))
@@ -217,14 +358,7 @@ func appendImageItems(result *types.ScanResult, images []dockerDfImage, imageUse
 		if len(tags) > 0 {
 			label = tags[0]
 		} else {
-			shortID := strings.TrimPrefix(imageID, "sha256:")
-			if shortID == "" {
-				shortID = "unknown"
-			}
-			if len(shortID) > dockerShortIDLength {
-				shortID = shortID[:dockerShortIDLength]
-			}
-			label = "untagged@" + shortID
+			label = "untagged@" + shortDockerID(imageID)
 		}
 
 		baseName := "Image: " + truncateName(label, dockerNameLimit)
@@ -234,23 +368,40 @@ func appendImageItems(result *types.ScanResult, images []dockerDfImage, imageUse
 
 		combined := make(map[string]struct{})
 		for _, key := range []string{imageID, strings.TrimPrefix(imageID, "sha256:")} {
-			for name := range imageUsedBy[key] {
+			for name := range usage.imageUsedBy[key] {
 				combined[name] = struct{}{}
 			}
 		}
 		for _, tag := range tags {
-			for name := range imageUsedBy[tag] {
+			for name := range usage.imageUsedBy[tag] {
 				combined[name] = struct{}{}
 			}
 		}
-		usedBy := usedByList(combined)
+
+		lastUsed := agg.CreatedAt
+		for _, t := range []time.Time{lastTagged[imageID], usage.lastCreated(combined)} {
+			if t.After(lastUsed) {
+				lastUsed = t
+			}
+		}
+		if filter.skip(tags, lastUsed) {
+			logger.Debug("docker image filtered", "id", imageID, "tags", len(tags), "lastUsed", lastUsed)
+			continue
+		}
+
+		usedBy := usage.labels(usedByList(combined))
 		displayName := appendUsedBy(baseName, usedBy)
 
-		appendDockerItem(result, dockerPathPrefixImage+imageID, agg.Size, baseName, displayName, usedBy)
+		locked := usage.lockedBy(combined)
+		item := appendDockerItem(result, dockerPathPrefixImage+imageID, agg.Size, baseName, displayName, locked)
+		item.ModifiedAt = lastUsed
+		if !locked {
+			usage.hold(item.Path, combined)
+		}
 	}
 }
 
-func appendVolumeItems(result *types.ScanResult, volumes []dockerDfVolume, volumeUsedBy map[string]map[string]struct{}) {
+func appendVolumeItems(result *types.ScanResult, volumes []dockerDfVolume, usage containerUsage) {
 	for _, v := range volumes {
 		if v.Name == "" {
 			continue
@@ -260,43 +411,97 @@ func appendVolumeItems(result *types.ScanResult, volumes []dockerDfVolume, volum
 			continue
 		}
 		baseName := "Volume: " + truncateName(v.Name, dockerNameLimit)
-		usedBy := usedByList(volumeUsedBy[v.Name])
+		usedBy := usage.labels(usedByList(usage.volumeUsedBy[v.Name]))
 		displayName := appendUsedBy(baseName, usedBy)
 
-		appendDockerItem(result, dockerPathPrefixVolume+v.Name, size, baseName, displayName, usedBy)
+		path := dockerPathPrefixVolume + v.Name
+		locked := usage.lockedBy(usage.volumeUsedBy[v.Name])
+		appendDockerItem(result, path, size, baseName, displayName, locked)
+		if !locked {
+			usage.hold(path, usage.volumeUsedBy[v.Name])
+		}
 	}
 }
 
+// appendBuildCache offers the build cache record by record when the runtime
+// lists records, and as a single item otherwise.
 func (s *DockerTarget) appendBuildCache(result *types.ScanResult) {
-	buildCacheSize := s.fetchBuildCacheSize()
+	if records, err := s.runtime.BuildCacheRecords(); err == nil && len(records) > 0 {
+		appendBuildCacheRecords(result, records, s.category.MinAgeDays)
+		return
+	}
+	if s.category.MinAgeDays > 0 {
+		// The total has no age, so it can't honour the threshold.
+		return
+	}
+
+	buildCacheSize := s.runtime.BuildCacheSize()
 	if buildCacheSize > 0 {
 		name := "Docker Build Cache"
-		appendDockerItem(result, dockerPathBuildCache, buildCacheSize, name, name, nil)
+		appendDockerItem(result, dockerPathBuildCache, buildCacheSize, name, name, false)
 	}
 }
 
 func (s *DockerTarget) Clean(items []types.CleanableItem) (*types.CleanResult, error) {
 	result := types.NewCleanResult(s.category)
 
-	for _, item := range items {
+	// Remove containers first so the images and volumes they held can go too.
+	ordered := make([]types.CleanableItem, len(items))
+	copy(ordered, items)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return strings.HasPrefix(ordered[i].Path, dockerPathPrefixContainer) &&
+			!strings.HasPrefix(ordered[j].Path, dockerPathPrefixContainer)
+	})
+
+	s.mu.Lock()
+	heldBy := s.heldBy
+	s.mu.Unlock()
+	removed := make(map[string]bool)
+
+	for _, item := range ordered {
+		if holder := firstRemaining(heldBy[item.Path], removed); holder != "" {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: still used by stopped container %s; select the container too",
+				item.Name, shortDockerID(holder)))
+			continue
+		}
+
 		var cmd *exec.Cmd
 		switch {
+		case strings.HasPrefix(item.Path, dockerPathPrefixContainer):
+			containerID := strings.TrimPrefix(item.Path, dockerPathPrefixContainer)
+			if containerID != "" {
+				cmd = s.runtime.RemoveContainer(containerID)
+			} else {
+				logger.Debug("docker prune skipped empty container id", "path", item.Path)
+			}
 		case strings.HasPrefix(item.Path, dockerPathPrefixImage):
 			imageID := strings.TrimPrefix(item.Path, dockerPathPrefixImage)
 			if imageID != "" {
-				cmd = execCommand("docker", "image", "rm", imageID)
+				cmd = s.runtime.RemoveImage(imageID)
 			} else {
 				logger.Debug("docker prune skipped empty image id", "path", item.Path)
 			}
 		case strings.HasPrefix(item.Path, dockerPathPrefixVolume):
 			volumeName := strings.TrimPrefix(item.Path, dockerPathPrefixVolume)
 			if volumeName != "" {
-				cmd = execCommand("docker", "volume", "rm", volumeName)
+				cmd = s.runtime.RemoveVolume(volumeName)
 			} else {
 				logger.Debug("docker prune skipped empty volume name", "path", item.Path)
 			}
 		case item.Path == dockerPathBuildCache:
-			cmd = execCommand("docker", "builder", "prune", "-af")
+			cmd = s.runtime.PruneBuildCache()
+			if cmd == nil {
+				logger.Debug("docker prune skipped unsupported build cache", "runtime", s.runtime.Name())
+			}
+		case strings.HasPrefix(item.Path, dockerPathPrefixBuildCache):
+			recordID := strings.TrimPrefix(item.Path, dockerPathPrefixBuildCache)
+			if recordID != "" {
+				unusedFor := time.Duration(s.category.MinAgeDays) * 24 * time.Hour
+				cmd = s.runtime.RemoveBuildCacheRecord(recordID, unusedFor)
+			}
+			if cmd == nil {
+				logger.Debug("docker prune skipped build cache record", "path", item.Path)
+			}
 		default:
 			logger.Debug("docker prune skipped unknown path", "path", item.Path)
 		}
@@ -309,6 +514,9 @@ func (s *DockerTarget) Clean(items []types.CleanableItem) (*types.CleanResult, e
 				logger.Debug("docker prune succeeded", "resourceType", item.Path, "size", item.Size)
 				result.FreedSpace += item.Size
 				result.CleanedItems++
+				if strings.HasPrefix(item.Path, dockerPathPrefixContainer) {
+					removed[strings.TrimPrefix(item.Path, dockerPathPrefixContainer)] = true
+				}
 			}
 		}
 	}
@@ -321,49 +529,14 @@ func (s *DockerTarget) Clean(items []types.CleanableItem) (*types.CleanResult, e
 	return result, nil
 }
 
-func (s *DockerTarget) fetchVerboseDf() (*dockerDfVerbose, error) {
-	cmd := execCommand("docker", "system", "df", "-v", "--format", "{{json .}}")
-	output, err := cmd.Output()
-	if err != nil {
-		logger.Warn("docker system df -v failed", "error", err)
-		return nil, err
-	}
-
-	line := strings.TrimSpace(string(output))
-	if line == "" {
-		return &dockerDfVerbose{}, nil
-	}
-
-	var df dockerDfVerbose
-	if err := json.Unmarshal([]byte(line), &df); err != nil {
-		logger.Warn("docker df -v json parse failed", "error", err)
-		return nil, err
-	}
-	return &df, nil
-}
-
-func (s *DockerTarget) fetchBuildCacheSize() int64 {
-	cmd := execCommand("docker", "system", "df", "--format", "{{json .}}")
-	output, err := cmd.Output()
-	if err != nil {
-		logger.Warn("docker system df failed", "error", err)
-		return 0
-	}
-
-	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
-	for _, line := range lines {
-		if line == "" {
-			continue
-		}
-		var df dockerDfOutput
-		if err := json.Unmarshal([]byte(line), &df); err != nil {
-			continue
-		}
-		if strings.EqualFold(df.Type, "Build Cache") {
-			return parseDockerSize(df.Reclaimable)
+// firstRemaining returns the first container ID not yet removed, or "".
+func firstRemaining(ids []string, removed map[string]bool) string {
+	for _, id := range ids {
+		if !removed[id] {
+			return id
 		}
 	}
-	return 0
+	return ""
 }
 
 func addUsedBy(store map[string]map[string]struct{}, key, name string) {
@@ -388,7 +561,9 @@ func usedByList(set map[string]struct{}) []string {
 	return names
 }
 
-func appendDockerItem(result *types.ScanResult, path string, size int64, name, displayName string, usedBy []string) {
+// appendDockerItem adds an item to result and returns it for further
+// annotation. Locked items are used by a running container.
+func appendDockerItem(result *types.ScanResult, path string, size int64, name, displayName string, locked bool) *types.CleanableItem {
 	item := types.CleanableItem{
 		Path:        path,
 		Size:        size,
@@ -397,12 +572,53 @@ func appendDockerItem(result *types.ScanResult, path string, size int64, name, d
 		DisplayName: displayName,
 		IsDirectory: false,
 	}
-	if len(usedBy) > 0 {
+	if locked {
 		item.Status = types.ItemStatusProcessLocked
 	}
 	result.Items = append(result.Items, item)
 	result.TotalSize += size
 	result.TotalFileCount++
+	return &result.Items[len(result.Items)-1]
+}
+
+// imageIDs returns the distinct image IDs in a listing.
+func imageIDs(images []dockerDfImage) []string {
+	seen := make(map[string]struct{}, len(images))
+	var ids []string
+	for _, img := range images {
+		if _, ok := seen[img.ID]; img.ID != "" && !ok {
+			seen[img.ID] = struct{}{}
+			ids = append(ids, img.ID)
+		}
+	}
+	return ids
+}
+
+// parseDockerTime parses a CreatedAt timestamp as printed by docker, or in
+// RFC 3339 form.
+func parseDockerTime(s string) (time.Time, bool) {
+	s = strings.TrimSpace(s)
+	for _, layout := range []string{dockerTimeLayout, time.RFC3339Nano} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func containerName(c dockerDfContainer) string {
+	return strings.TrimPrefix(strings.TrimSpace(c.Names), "/")
+}
+
+func shortDockerID(id string) string {
+	short := strings.TrimPrefix(id, "sha256:")
+	if short == "" {
+		return "unknown"
+	}
+	if len(short) > dockerShortIDLength {
+		short = short[:dockerShortIDLength]
+	}
+	return short
 }
 
 func appendUsedBy(name string, usedBy []string) string {
@@ -452,6 +668,18 @@ func parseDockerSize(s string) int64 {
 
 	var multiplier int64 = 1
 	switch {
+	case strings.HasSuffix(s, "KIB"):
+		multiplier = 1024
+		s = strings.TrimSuffix(s, "KIB")
+	case strings.HasSuffix(s, "MIB"):
+		multiplier = 1024 * 1024
+		s = strings.TrimSuffix(s, "MIB")
+	case strings.HasSuffix(s, "GIB"):
+		multiplier = 1024 * 1024 * 1024
+		s = strings.TrimSuffix(s, "GIB")
+	case strings.HasSuffix(s, "TIB"):
+		multiplier = 1024 * 1024 * 1024 * 1024
+		s = strings.TrimSuffix(s, "TIB")
 	case strings.HasSuffix(s, "KB"):
 		multiplier = 1024
 		s = strings.TrimSuffix(s, "KB")
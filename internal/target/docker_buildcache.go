@@ -0,0 +1,167 @@
+package target
+
+import (
+	"bufio"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/2ykwang/mac-cleanup-go/internal/types"
+)
+
+// buildCacheTimeLayout is the "Created at" format printed by `buildx du`.
+const buildCacheTimeLayout = "2006-01-02 15:04:05.999999999 -0700 MST"
+
+// buildCacheRecord is one BuildKit cache record from `docker buildx du --verbose`.
+type buildCacheRecord struct {
+	ID          string
+	Description string
+	Size        int64
+	CreatedAt   time.Time
+	LastUsed    time.Time
+	UsageCount  int
+	Shared      bool
+	Reclaimable bool
+}
+
+// lastActive returns when the record was last used, or created if never used.
+func (r buildCacheRecord) lastActive() time.Time {
+	if r.LastUsed.After(r.CreatedAt) {
+		return r.LastUsed
+	}
+	return r.CreatedAt
+}
+
+// parseBuildxDu parses the "Key: value" blocks of `buildx du --verbose`,
+// one block per record separated by blank lines. Records without an ID
+// are dropped.
+func parseBuildxDu(output string, now time.Time) []buildCacheRecord {
+	var records []buildCacheRecord
+	var cur buildCacheRecord
+	flush := func() {
+		if cur.ID != "" {
+			records = append(records, cur)
+		}
+		cur = buildCacheRecord{}
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			flush()
+			continue
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "id":
+			if cur.ID != "" {
+				flush()
+			}
+			cur.ID = value
+		case "description":
+			cur.Description = value
+		case "size":
+			cur.Size = parseDockerSize(value)
+		case "created at":
+			if t, err := time.Parse(buildCacheTimeLayout, value); err == nil {
+				cur.CreatedAt = t
+			}
+		case "last used":
+			if t, ok := parseTimeAgo(value, now); ok {
+				cur.LastUsed = t
+			}
+		case "usage count":
+			cur.UsageCount, _ = strconv.Atoi(value)
+		case "shared":
+			cur.Shared = value == "true"
+		case "reclaimable":
+			cur.Reclaimable = value == "true"
+		}
+	}
+	flush()
+	return records
+}
+
+// parseTimeAgo converts a human duration such as "2 days ago" or
+// "About an hour ago", as printed by the docker CLI, to a time before now.
+func parseTimeAgo(s string, now time.Time) (time.Time, bool) {
+	fields := strings.Fields(strings.ToLower(s))
+	if len(fields) < 3 || fields[len(fields)-1] != "ago" {
+		return time.Time{}, false
+	}
+	fields = fields[:len(fields)-1]
+	if fields[0] == "less" {
+		return now, true
+	}
+	if fields[0] == "about" {
+		fields = fields[1:]
+	}
+	if len(fields) != 2 {
+		return time.Time{}, false
+	}
+
+	n := 1
+	if fields[0] != "a" && fields[0] != "an" {
+		var err error
+		if n, err = strconv.Atoi(fields[0]); err != nil {
+			return time.Time{}, false
+		}
+	}
+	switch strings.TrimSuffix(fields[1], "s") {
+	case "second":
+		return now.Add(-time.Duration(n) * time.Second), true
+	case "minute":
+		return now.Add(-time.Duration(n) * time.Minute), true
+	case "hour":
+		return now.Add(-time.Duration(n) * time.Hour), true
+	case "day":
+		return now.AddDate(0, 0, -n), true
+	case "week":
+		return now.AddDate(0, 0, -7*n), true
+	case "month":
+		return now.AddDate(0, -n, 0), true
+	case "year":
+		return now.AddDate(-n, 0, 0), true
+	}
+	return time.Time{}, false
+}
+
+// appendBuildCacheRecords offers each cache record as its own item.
+// Records BuildKit cannot reclaim yet are locked; with a min age, records
+// used more recently are skipped.
+func appendBuildCacheRecords(result *types.ScanResult, records []buildCacheRecord, minAgeDays int) {
+	var cutoff time.Time
+	if minAgeDays > 0 {
+		cutoff = time.Now().AddDate(0, 0, -minAgeDays)
+	}
+
+	for _, r := range records {
+		if r.Size == 0 {
+			continue
+		}
+		lastActive := r.lastActive()
+		if !cutoff.IsZero() && (lastActive.IsZero() || lastActive.After(cutoff)) {
+			continue
+		}
+
+		label := r.Description
+		if label == "" {
+			label = shortDockerID(r.ID)
+		}
+		baseName := "Build Cache: " + truncateName(label, dockerNameLimit)
+		sharing := "private"
+		if r.Shared {
+			sharing = "shared"
+		}
+		displayName := fmt.Sprintf("%s (%s, used %d times)", baseName, sharing, r.UsageCount)
+
+		item := appendDockerItem(result, dockerPathPrefixBuildCache+r.ID, r.Size, baseName, displayName, !r.Reclaimable)
+		item.ModifiedAt = lastActive
+	}
+}
@@ -0,0 +1,157 @@
+package target
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/2ykwang/mac-cleanup-go/internal/types"
+)
+
+const buildxDuVerbose = `ID:		k2h7s0lq5t1mz
+Parent:		abc
+Created at:	2024-01-10 08:00:00.123456789 +0000 UTC
+Mutable:	false
+Reclaimable:	true
+Shared:		false
+Size:		120MB
+Description:	[build 3/5] RUN npm ci
+Usage count:	4
+Last used:	3 months ago
+Type:		regular
+
+ID:		p9x2w4m8n6b1c
+Created at:	2024-01-10 08:00:00 +0000 UTC
+Mutable:	true
+Reclaimable:	false
+Shared:		true
+Size:		1.5GB
+Description:	local source for context
+Usage count:	12
+Last used:	About an hour ago
+Type:		source.local
+
+ID:		empty0
+Size:		0B
+`
+
+func TestParseBuildxDu(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	records := parseBuildxDu(buildxDuVerbose, now)
+
+	require.Len(t, records, 3)
+	npm := records[0]
+	assert.Equal(t, "k2h7s0lq5t1mz", npm.ID)
+	assert.Equal(t, "[build 3/5] RUN npm ci", npm.Description)
+	assert.Equal(t, parseDockerSize("120MB"), npm.Size)
+	assert.Equal(t, 4, npm.UsageCount)
+	assert.True(t, npm.Reclaimable)
+	assert.False(t, npm.Shared)
+	assert.Equal(t, time.Date(2024, 1, 10, 8, 0, 0, 123456789, time.UTC), npm.CreatedAt.UTC())
+	assert.Equal(t, now.AddDate(0, -3, 0), npm.LastUsed)
+	assert.Equal(t, npm.LastUsed, npm.lastActive())
+
+	source := records[1]
+	assert.True(t, source.Shared)
+	assert.False(t, source.Reclaimable)
+	assert.Equal(t, now.Add(-time.Hour), source.LastUsed)
+}
+
+func TestParseTimeAgo(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		in   string
+		want time.Time
+		ok   bool
+	}{
+		{"Less than a second ago", now, true},
+		{"About a minute ago", now.Add(-time.Minute), true},
+		{"About an hour ago", now.Add(-time.Hour), true},
+		{"5 hours ago", now.Add(-5 * time.Hour), true},
+		{"2 days ago", now.AddDate(0, 0, -2), true},
+		{"3 weeks ago", now.AddDate(0, 0, -21), true},
+		{"2 years ago", now.AddDate(-2, 0, 0), true},
+		{"", time.Time{}, false},
+		{"<nil>", time.Time{}, false},
+		{"many days ago", time.Time{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseTimeAgo(tt.in, now)
+		assert.Equal(t, tt.ok, ok, tt.in)
+		assert.Equal(t, tt.want, got, tt.in)
+	}
+}
+
+func TestDockerTarget_Scan_BuildCacheRecords(t *testing.T) {
+	stubRuntimeCLI(t, []string{"docker"}, map[string]string{
+		"docker buildx du --verbose": buildxDuVerbose,
+	})
+
+	result, err := NewDockerTarget(types.Category{ID: "docker", Runtime: "docker"}).Scan()
+
+	require.NoError(t, err)
+	require.Len(t, result.Items, 2, "empty records are not offered")
+	npm := result.Items[0]
+	assert.Equal(t, dockerPathPrefixBuildCache+"k2h7s0lq5t1mz", npm.Path)
+	assert.Equal(t, "Build Cache: [build 3/5] RUN npm ci (private, used 4 times)", npm.DisplayName)
+	assert.True(t, npm.Status.Cleanable())
+	assert.False(t, npm.ModifiedAt.IsZero())
+
+	source := result.Items[1]
+	assert.Contains(t, source.DisplayName, "(shared, used 12 times)")
+	assert.Equal(t, types.ItemStatusProcessLocked, source.Status, "not reclaimable")
+}
+
+func TestDockerTarget_Scan_BuildCacheRecordsMinAge(t *testing.T) {
+	stubRuntimeCLI(t, []string{"docker"}, map[string]string{
+		"docker buildx du --verbose": buildxDuVerbose,
+	})
+
+	result, err := NewDockerTarget(types.Category{ID: "docker", Runtime: "docker", MinAgeDays: 30}).Scan()
+
+	require.NoError(t, err)
+	require.Len(t, result.Items, 1)
+	assert.Equal(t, dockerPathPrefixBuildCache+"k2h7s0lq5t1mz", result.Items[0].Path)
+}
+
+func TestDockerTarget_Scan_BuildCacheTotalSkippedWithMinAge(t *testing.T) {
+	stubRuntimeCLI(t, []string{"docker"}, map[string]string{
+		"docker system df --format {{json .}}": `{"Type":"Build Cache","Reclaimable":"2GB"}`,
+	})
+
+	withAge, err := NewDockerTarget(types.Category{ID: "docker", Runtime: "docker", MinAgeDays: 7}).Scan()
+	require.NoError(t, err)
+	assert.Empty(t, withAge.Items)
+
+	without, err := NewDockerTarget(types.Category{ID: "docker", Runtime: "docker"}).Scan()
+	require.NoError(t, err)
+	require.Len(t, without.Items, 1)
+	assert.Equal(t, dockerPathBuildCache, without.Items[0].Path)
+}
+
+func TestDockerTarget_Clean_BuildCacheRecord(t *testing.T) {
+	calls := stubRuntimeCLI(t, []string{"docker"}, nil)
+
+	result, err := NewDockerTarget(types.Category{ID: "docker", Runtime: "docker"}).Clean([]types.CleanableItem{
+		{Path: dockerPathPrefixBuildCache + "k2h7s0lq5t1mz", Size: 100},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.CleanedItems)
+	assert.Equal(t, []string{"docker buildx prune --force --filter id=k2h7s0lq5t1mz"}, *calls)
+}
+
+func TestDockerTarget_Clean_BuildCacheRecordWithMinAge(t *testing.T) {
+	calls := stubRuntimeCLI(t, []string{"docker"}, nil)
+
+	result, err := NewDockerTarget(types.Category{ID: "docker", Runtime: "docker", MinAgeDays: 30}).Clean([]types.CleanableItem{
+		{Path: dockerPathPrefixBuildCache + "k2h7s0lq5t1mz", Size: 100},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.CleanedItems)
+	assert.Equal(t, []string{"docker buildx prune --force --filter id=k2h7s0lq5t1mz --filter until=720h"}, *calls)
+}
@@ -0,0 +1,57 @@
+package target
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/2ykwang/mac-cleanup-go/internal/logger"
+	"github.com/2ykwang/mac-cleanup-go/internal/types"
+)
+
+// variantSeparator joins a builtin ID and a variant in derived category IDs,
+// e.g. "docker@colima".
+const variantSeparator = "@"
+
+// builtinID returns the builtin a category ID refers to, stripping any variant.
+func builtinID(id string) string {
+	base, _, _ := strings.Cut(id, variantSeparator)
+	return base
+}
+
+// ExpandConfig returns a copy of cfg whose categories are expanded the way
+// DefaultRegistry registers them, leaving cfg itself untouched.
+func ExpandConfig(cfg *types.Config) *types.Config {
+	expanded := *cfg
+	expanded.Categories = expandDockerContexts(cfg.Categories)
+	return &expanded
+}
+
+// expandDockerContexts replaces the docker category with one category per
+// configured Docker context so each context is scanned as its own section.
+// The first context keeps the "docker" ID; the others get "docker@<context>".
+// Without configured contexts only the current one is scanned: contexts such
+// as Docker Desktop's "default" and "desktop-linux" often reach the same
+// engine, and listing them all would show every image twice.
+func expandDockerContexts(categories []types.Category) []types.Category {
+	var expanded []types.Category
+	for _, cat := range categories {
+		contexts := cat.Contexts
+		if cat.ID != "docker" || len(contexts) < 2 {
+			expanded = append(expanded, cat)
+			continue
+		}
+
+		for i, name := range contexts {
+			derived := cat
+			derived.Runtime = runtimeDocker
+			derived.Contexts = []string{name}
+			derived.Name = fmt.Sprintf("%s (%s)", cat.Name, name)
+			if i > 0 {
+				derived.ID = cat.ID + variantSeparator + name
+			}
+			expanded = append(expanded, derived)
+		}
+		logger.Info("docker contexts expanded", "contexts", contexts)
+	}
+	return expanded
+}
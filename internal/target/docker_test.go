@@ -5,8 +5,10 @@ import (
 	"os/exec"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/2ykwang/mac-cleanup-go/internal/types"
 	"github.com/2ykwang/mac-cleanup-go/internal/utils"
@@ -82,6 +84,12 @@ func TestParseDockerSize_TB(t *testing.T) {
 	assert.Equal(t, int64(1024*1024*1024*1024), parseDockerSize("1TB"))
 }
 
+func TestParseDockerSize_BinaryUnits(t *testing.T) {
+	assert.Equal(t, int64(1536), parseDockerSize("1.5 KiB"))
+	assert.Equal(t, int64(2*1024*1024), parseDockerSize("2MiB"))
+	assert.Equal(t, int64(1024*1024*1024), parseDockerSize("1 GiB"))
+}
+
 func TestParseDockerSize_CaseInsensitive(t *testing.T) {
 	expected := int64(1024 * 1024 * 1024)
 
@@ -446,6 +454,183 @@ func TestDockerTarget_Scan_MultiTagImagesHaveUniquePaths(t *testing.T) {
 	}
 }
 
+func TestDockerTarget_Scan_StoppedContainers(t *testing.T) {
+	verboseOutput := `{"Images":[` +
+		`{"ID":"sha256:old","Repository":"old","Tag":"1","Size":"1GB"},` +
+		`{"ID":"sha256:live","Repository":"live","Tag":"1","Size":"2GB"}],` +
+		`"Containers":[` +
+		`{"ID":"c0ffee","Image":"old:1","Names":"batch","Mounts":"data","Size":"10MB","State":"exited","Status":"Exited (0) 3 weeks ago","CreatedAt":"2024-05-01 10:00:00 +0000 UTC"},` +
+		`{"ID":"beef","Image":"live:1","Names":"api","Mounts":"","Size":"5MB","State":"running","Status":"Up 2 hours"}],` +
+		`"Volumes":[{"Name":"data","Size":"500MB"}]}`
+	defer stubDockerScan(t, verboseOutput, `{"Type":"Build Cache","TotalCount":"0","Active":"0","Size":"0B","Reclaimable":"0B"}`)()
+
+	s := NewDockerTarget(types.Category{ID: "docker", Name: "Docker"})
+
+	result, err := s.Scan()
+
+	require.NoError(t, err)
+	items := make(map[string]types.CleanableItem, len(result.Items))
+	for _, item := range result.Items {
+		items[item.Path] = item
+	}
+	require.Len(t, items, 4, "running containers are not offered")
+
+	container := items[dockerPathPrefixContainer+"c0ffee"]
+	assert.Equal(t, "Container: batch (Exited (0) 3 weeks ago)", container.DisplayName)
+	assert.Equal(t, parseDockerSize("10MB"), container.Size)
+	assert.Equal(t, time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC), container.ModifiedAt.UTC())
+	assert.Equal(t, types.ItemStatusAvailable, container.Status)
+
+	oldImage := items[dockerPathPrefixImage+"sha256:old"]
+	assert.Equal(t, types.ItemStatusAvailable, oldImage.Status, "held only by a stopped container")
+	assert.Contains(t, oldImage.DisplayName, "Used By: batch (stopped)")
+	assert.Equal(t, types.ItemStatusAvailable, items[dockerPathPrefixVolume+"data"].Status)
+	assert.Equal(t, types.ItemStatusProcessLocked, items[dockerPathPrefixImage+"sha256:live"].Status)
+}
+
+// imageAgeListing has a dangling image, an image last used by a container,
+// and an image only re-tagged recently.
+const imageAgeListing = `{"Images":[` +
+	`{"ID":"sha256:dangling","Repository":"<none>","Tag":"<none>","Size":"1GB","CreatedAt":"2024-01-01 00:00:00 +0000 UTC"},` +
+	`{"ID":"sha256:used","Repository":"app","Tag":"1","Size":"2GB","CreatedAt":"2024-01-01 00:00:00 +0000 UTC"},` +
+	`{"ID":"sha256:tagged","Repository":"base","Tag":"1","Size":"3GB","CreatedAt":"2024-01-01 00:00:00 +0000 UTC"}],` +
+	`"Containers":[` +
+	`{"ID":"c1","Image":"app:1","Names":"job","State":"exited","Status":"Exited (0)","CreatedAt":"2024-03-01 00:00:00 +0000 UTC"}],` +
+	`"Volumes":[]}`
+
+func stubImageAgeScan(t *testing.T, tagged time.Time) {
+	t.Helper()
+	inspect := strings.Join([]string{"docker", "image", "inspect", "--format",
+		`{{.Id}} {{.Metadata.LastTagTime.Format "2006-01-02T15:04:05Z07:00"}}`,
+		"sha256:dangling", "sha256:used", "sha256:tagged"}, " ")
+	stubRuntimeCLI(t, []string{"docker"}, map[string]string{
+		"docker system df -v --format {{json .}}": imageAgeListing,
+		inspect: "sha256:dangling 0001-01-01T00:00:00Z\n" +
+			"sha256:used 2024-01-01T00:00:00Z\n" +
+			"sha256:tagged " + tagged.Format(time.RFC3339) + "\n",
+	})
+}
+
+func TestDockerTarget_Scan_ImageLastUsed(t *testing.T) {
+	tagged := time.Now().Add(-time.Hour).Truncate(time.Second)
+	stubImageAgeScan(t, tagged)
+
+	result, err := NewDockerTarget(types.Category{ID: "docker", Runtime: "docker"}).Scan()
+
+	require.NoError(t, err)
+	items := make(map[string]types.CleanableItem, len(result.Items))
+	for _, item := range result.Items {
+		items[item.Path] = item
+	}
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), items[dockerPathPrefixImage+"sha256:dangling"].ModifiedAt.UTC())
+	assert.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), items[dockerPathPrefixImage+"sha256:used"].ModifiedAt.UTC(), "container creation counts as use")
+	assert.True(t, tagged.Equal(items[dockerPathPrefixImage+"sha256:tagged"].ModifiedAt))
+}
+
+func TestDockerTarget_Scan_ImageFilters(t *testing.T) {
+	tests := []struct {
+		name string
+		cat  types.Category
+		want []string
+	}{
+		{"dangling only", types.Category{DanglingOnly: true}, []string{"sha256:dangling"}},
+		{"older than", types.Category{MinAgeDays: 30}, []string{"sha256:dangling", "sha256:used"}},
+		{"both", types.Category{DanglingOnly: true, MinAgeDays: 30}, []string{"sha256:dangling"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stubImageAgeScan(t, time.Now().Add(-time.Hour))
+			tt.cat.ID, tt.cat.Runtime = "docker", "docker"
+
+			result, err := NewDockerTarget(tt.cat).Scan()
+
+			require.NoError(t, err)
+			var images []string
+			for _, item := range result.Items {
+				if strings.HasPrefix(item.Path, dockerPathPrefixImage) {
+					images = append(images, strings.TrimPrefix(item.Path, dockerPathPrefixImage))
+				}
+			}
+			assert.Equal(t, tt.want, images)
+			assert.Len(t, result.Items, len(tt.want)+1, "the stopped container is still offered")
+		})
+	}
+}
+
+func TestImageFilter_SkipsUnknownAge(t *testing.T) {
+	f := newImageFilter(types.Category{MinAgeDays: 7})
+
+	assert.True(t, f.skip(nil, time.Time{}))
+	assert.False(t, f.skip(nil, time.Now().AddDate(0, 0, -8)))
+	assert.False(t, newImageFilter(types.Category{}).skip([]string{"app:1"}, time.Time{}))
+}
+
+func TestDockerTarget_Clean_RemovesContainersFirst(t *testing.T) {
+	original := execCommand
+	defer func() { execCommand = original }()
+
+	var calls []string
+	execCommand = func(_ string, args ...string) *exec.Cmd {
+		calls = append(calls, strings.Join(args, " "))
+		return exec.Command("true")
+	}
+
+	s := NewDockerTarget(types.Category{ID: "docker", Name: "Docker"})
+	items := []types.CleanableItem{
+		{Path: dockerPathPrefixImage + "sha256:old", Size: 1000},
+		{Path: dockerPathPrefixContainer + "c0ffee", Size: 10},
+		{Path: dockerPathPrefixVolume + "data", Size: 500},
+	}
+
+	result, err := s.Clean(items)
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.CleanedItems)
+	assert.Equal(t, []string{"rm c0ffee", "image rm sha256:old", "volume rm data"}, calls)
+}
+
+func TestDockerTarget_Clean_KeepsItemsHeldByUnselectedContainers(t *testing.T) {
+	verboseOutput := `{"Images":[{"ID":"sha256:old","Repository":"old","Tag":"1","Size":"1GB"}],` +
+		`"Containers":[{"ID":"c0ffee","Image":"old:1","Names":"batch","Mounts":"data","Size":"10MB","State":"exited"}],` +
+		`"Volumes":[{"Name":"data","Size":"500MB"}]}`
+	restore := stubDockerScan(t, verboseOutput, `{"Type":"Build Cache","TotalCount":"0","Active":"0","Size":"0B","Reclaimable":"0B"}`)
+	s := NewDockerTarget(types.Category{ID: "docker", Name: "Docker"})
+	scanned, err := s.Scan()
+	restore()
+	require.NoError(t, err)
+	items := make(map[string]types.CleanableItem, len(scanned.Items))
+	for _, item := range scanned.Items {
+		items[item.Path] = item
+	}
+
+	original := execCommand
+	defer func() { execCommand = original }()
+	var calls []string
+	execCommand = func(_ string, args ...string) *exec.Cmd {
+		calls = append(calls, strings.Join(args, " "))
+		return exec.Command("true")
+	}
+
+	result, err := s.Clean([]types.CleanableItem{items[dockerPathPrefixImage+"sha256:old"], items[dockerPathPrefixVolume+"data"]})
+
+	require.NoError(t, err)
+	assert.Empty(t, calls)
+	assert.Zero(t, result.CleanedItems)
+	require.Len(t, result.Errors, 2)
+	assert.Contains(t, result.Errors[0], "still used by stopped container c0ffee")
+
+	result, err = s.Clean([]types.CleanableItem{
+		items[dockerPathPrefixImage+"sha256:old"],
+		items[dockerPathPrefixContainer+"c0ffee"],
+		items[dockerPathPrefixVolume+"data"],
+	})
+
+	require.NoError(t, err)
+	assert.Empty(t, result.Errors)
+	assert.Equal(t, 3, result.CleanedItems)
+	assert.Equal(t, []string{"rm c0ffee", "image rm sha256:old", "volume rm data"}, calls)
+}
+
 func TestDockerTarget_Clean_AllTypes(t *testing.T) {
 	original := execCommand
 	defer func() { execCommand = original }()
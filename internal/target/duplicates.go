@@ -0,0 +1,387 @@
+package target
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/2ykwang/mac-cleanup-go/internal/logger"
+	"github.com/2ykwang/mac-cleanup-go/internal/types"
+	"github.com/2ykwang/mac-cleanup-go/internal/utils"
+)
+
+// partialHashSize is how much of the start and of the end of a file the
+// partial hash reads.
+const partialHashSize = 64 << 10
+
+// packageExts are directories macOS shows as single files. Duplicates inside
+// them are part of an app or library, not copies a user made.
+var packageExts = map[string]struct{}{
+	".app": {}, ".bundle": {}, ".framework": {}, ".plugin": {}, ".kext": {},
+	".photoslibrary": {}, ".musiclibrary": {}, ".tvlibrary": {},
+	".fcpbundle": {}, ".logicx": {}, ".band": {}, ".xcodeproj": {}, ".xcworkspace": {},
+}
+
+// dupFile is a candidate file found under one of the roots.
+type dupFile struct {
+	path    string
+	root    int // index of the root it was found under; lower roots keep originals
+	size    int64
+	modTime time.Time
+	info    os.FileInfo
+}
+
+// DuplicatesTarget finds files with identical content under the category's
+// paths. The copy under the earliest listed path (then the oldest) is kept
+// as the original; every other copy is offered for removal.
+type DuplicatesTarget struct {
+	category types.Category
+
+	mu        sync.Mutex
+	originals map[string]string // copy path -> original path, from the last scan
+}
+
+func NewDuplicatesTarget(cat types.Category) *DuplicatesTarget {
+	return &DuplicatesTarget{category: cat}
+}
+
+func (t *DuplicatesTarget) Category() types.Category { return t.category }
+func (t *DuplicatesTarget) IsAvailable() bool        { return len(t.category.Paths) > 0 }
+
+func (t *DuplicatesTarget) Scan() (*types.ScanResult, error) {
+	result := types.NewScanResult(t.category)
+	start := time.Now()
+
+	bySize := make(map[int64][]dupFile)
+	for _, f := range t.collect() {
+		bySize[f.size] = append(bySize[f.size], f)
+	}
+
+	var candidates [][]dupFile
+	for _, files := range bySize {
+		if files = dropHardLinks(files); len(files) > 1 {
+			candidates = append(candidates, files)
+		}
+	}
+	candidates = refineGroups(candidates, partialHash)
+	groups := refineGroups(candidates, fullHash)
+
+	originals := make(map[string]string)
+	for _, group := range groups {
+		sort.Slice(group, func(i, j int) bool {
+			a, b := group[i], group[j]
+			if a.root != b.root {
+				return a.root < b.root
+			}
+			if !a.modTime.Equal(b.modTime) {
+				return a.modTime.Before(b.modTime)
+			}
+			return a.path < b.path
+		})
+
+		original := group[0]
+		result.Items = append(result.Items, types.CleanableItem{
+			Path:        original.path,
+			Size:        original.size,
+			FileCount:   1,
+			Name:        filepath.Base(original.path),
+			DisplayName: homeRelative(original.path),
+			ModifiedAt:  original.modTime,
+			Status:      types.ItemStatusOriginal,
+			Group:       original.path,
+		})
+		for _, dup := range group[1:] {
+			result.Items = append(result.Items, types.CleanableItem{
+				Path:        dup.path,
+				Size:        dup.size,
+				FileCount:   1,
+				Name:        filepath.Base(dup.path),
+				DisplayName: fmt.Sprintf("%s (copy of %s)", homeRelative(dup.path), homeRelative(original.path)),
+				ModifiedAt:  dup.modTime,
+				Group:       original.path,
+			})
+			result.TotalSize += dup.size
+			result.TotalFileCount++
+			originals[dup.path] = original.path
+		}
+	}
+
+	t.mu.Lock()
+	t.originals = originals
+	t.mu.Unlock()
+
+	logger.Info("duplicates scan complete",
+		"groups", len(groups),
+		"copies", len(originals),
+		"total_size", result.TotalSize,
+		"total_ms", time.Since(start).Milliseconds())
+
+	return result, nil
+}
+
+// collect walks the roots for regular files of at least the category's
+// min_size, skipping hidden entries, macOS packages, excluded and
+// SIP-protected paths. A file under several roots is listed once.
+func (t *DuplicatesTarget) collect() []dupFile {
+	var files []dupFile
+	seen := make(map[string]struct{})
+	minSize := int64(t.category.MinSize)
+
+	for i, root := range t.category.Paths {
+		root = utils.ExpandPath(root)
+		//nolint:errcheck // WalkDir errors are handled per-entry
+		filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			if path != root && (strings.HasPrefix(d.Name(), ".") || t.isExcluded(path)) {
+				if d.IsDir() {
+					return fs.SkipDir
+				}
+				return nil
+			}
+			if d.IsDir() {
+				if _, pkg := packageExts[strings.ToLower(filepath.Ext(d.Name()))]; pkg {
+					return fs.SkipDir
+				}
+				return nil
+			}
+			if !d.Type().IsRegular() {
+				return nil
+			}
+			if _, dup := seen[path]; dup {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil || info.Size() == 0 || info.Size() < minSize {
+				return nil
+			}
+			seen[path] = struct{}{}
+			files = append(files, dupFile{path: path, root: i, size: info.Size(), modTime: info.ModTime(), info: info})
+			return nil
+		})
+	}
+	return files
+}
+
+func (t *DuplicatesTarget) isExcluded(path string) bool {
+	if utils.IsSIPProtected(path) {
+		return true
+	}
+	for _, pattern := range t.category.Exclude {
+		if utils.MatchPathOrAncestor(utils.ExpandPath(pattern), path) {
+			return true
+		}
+	}
+	return false
+}
+
+// dropHardLinks keeps one path per underlying file: removing a hard link
+// frees nothing.
+func dropHardLinks(files []dupFile) []dupFile {
+	var kept []dupFile
+	for _, f := range files {
+		linked := false
+		for _, k := range kept {
+			if os.SameFile(f.info, k.info) {
+				linked = true
+				break
+			}
+		}
+		if !linked {
+			kept = append(kept, f)
+		}
+	}
+	return kept
+}
+
+// refineGroups splits each group by hash, in parallel over
+// utils.DefaultWorkers, and keeps the subgroups with more than one file.
+// Files that cannot be read are dropped.
+func refineGroups(groups [][]dupFile, hash func(path string) (string, error)) [][]dupFile {
+	type keyed struct {
+		group int
+		key   string
+		file  dupFile
+	}
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		hashed []keyed
+		sem    = make(chan struct{}, utils.DefaultWorkers())
+	)
+	for gi, group := range groups {
+		for _, f := range group {
+			sem <- struct{}{}
+			wg.Add(1)
+			go func(gi int, f dupFile) {
+				defer wg.Done()
+				defer func() { <-sem }()
+
+				key, err := hash(f.path)
+				if err != nil {
+					logger.Debug("duplicate candidate unreadable", "path", f.path, "error", err)
+					return
+				}
+				mu.Lock()
+				hashed = append(hashed, keyed{group: gi, key: key, file: f})
+				mu.Unlock()
+			}(gi, f)
+		}
+	}
+	wg.Wait()
+
+	buckets := make(map[string][]dupFile)
+	for _, h := range hashed {
+		k := fmt.Sprintf("%d:%s", h.group, h.key)
+		buckets[k] = append(buckets[k], h.file)
+	}
+	var refined [][]dupFile
+	for _, files := range buckets {
+		if len(files) > 1 {
+			refined = append(refined, files)
+		}
+	}
+	// Map order is random; keep results stable between scans.
+	sort.Slice(refined, func(i, j int) bool {
+		if refined[i][0].size != refined[j][0].size {
+			return refined[i][0].size > refined[j][0].size
+		}
+		return minPath(refined[i]) < minPath(refined[j])
+	})
+	return refined
+}
+
+func minPath(files []dupFile) string {
+	p := files[0].path
+	for _, f := range files[1:] {
+		if f.path < p {
+			p = f.path
+		}
+	}
+	return p
+}
+
+// partialHash hashes the first and last partialHashSize bytes of a file,
+// which tells most same-sized files apart without reading them whole.
+func partialHash(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	if _, err := io.CopyN(h, f, partialHashSize); err != nil && err != io.EOF {
+		return "", err
+	}
+	if info.Size() > 2*partialHashSize {
+		if _, err := f.Seek(-partialHashSize, io.SeekEnd); err != nil {
+			return "", err
+		}
+		if _, err := io.Copy(h, f); err != nil {
+			return "", err
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func fullHash(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// homeRelative shows paths under $HOME as ~/...
+func homeRelative(path string) string {
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" {
+		return path
+	}
+	if rel, err := filepath.Rel(home, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return filepath.Join("~", rel)
+	}
+	return path
+}
+
+// Clean moves the selected copies to the Trash. A copy is only removed while
+// its original from the last scan still has the same content as the copy,
+// checked by hashing both again, so no content is ever left without a copy.
+func (t *DuplicatesTarget) Clean(items []types.CleanableItem) (*types.CleanResult, error) {
+	result := types.NewCleanResult(t.category)
+	if len(items) == 0 {
+		return result, nil
+	}
+
+	t.mu.Lock()
+	originals := t.originals
+	t.mu.Unlock()
+
+	originalHashes := make(map[string]string)
+	var copies []types.CleanableItem
+	for _, item := range items {
+		original, ok := originals[item.Path]
+		if !ok {
+			result.Errors = append(result.Errors, fmt.Sprintf("not a known duplicate: %s", item.Path))
+			continue
+		}
+		if !sameContent(original, item.Path, originalHashes) {
+			result.Errors = append(result.Errors, fmt.Sprintf("original changed or missing, kept %s", item.Path))
+			continue
+		}
+		copies = append(copies, item)
+	}
+	if len(copies) == 0 {
+		return result, nil
+	}
+
+	batchResult := utils.BatchTrash(copies, types.BatchTrashOptions{
+		Category: t.category,
+	})
+	result.Merge(batchResult)
+	return result, nil
+}
+
+// sameContent reports whether the files at original and dup still hold the
+// same bytes. Original hashes are kept in hashes, since a group's copies
+// share one original.
+func sameContent(original, dup string, hashes map[string]string) bool {
+	oInfo, err := os.Stat(original)
+	if err != nil {
+		return false
+	}
+	dInfo, err := os.Stat(dup)
+	if err != nil || oInfo.Size() != dInfo.Size() {
+		return false
+	}
+
+	want, ok := hashes[original]
+	if !ok {
+		if want, err = fullHash(original); err != nil {
+			return false
+		}
+		hashes[original] = want
+	}
+	got, err := fullHash(dup)
+	return err == nil && got == want
+}
@@ -0,0 +1,181 @@
+package target
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/2ykwang/mac-cleanup-go/internal/types"
+)
+
+func writeFile(t *testing.T, path string, content []byte, modTime time.Time) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, content, 0o644))
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+}
+
+func newTestDuplicatesTarget(roots ...string) *DuplicatesTarget {
+	return NewDuplicatesTarget(types.Category{
+		ID:     "duplicates",
+		Name:   "Duplicate Files",
+		Safety: types.SafetyLevelRisky,
+		Method: types.MethodBuiltin,
+		Paths:  roots,
+	})
+}
+
+func TestDuplicatesTarget_Scan_GroupsIdenticalFiles(t *testing.T) {
+	base := t.TempDir()
+	docs, desktop, downloads := filepath.Join(base, "Documents"), filepath.Join(base, "Desktop"), filepath.Join(base, "Downloads")
+	asset := bytes.Repeat([]byte("asset"), 50_000)
+	old := time.Now().AddDate(0, -1, 0)
+
+	writeFile(t, filepath.Join(downloads, "asset.psd"), asset, old.AddDate(0, 0, -10))
+	writeFile(t, filepath.Join(desktop, "asset copy.psd"), asset, old)
+	writeFile(t, filepath.Join(docs, "work", "asset.psd"), asset, old)
+	// Same size and start, different end: dropped by the partial hash.
+	differentEnd := bytes.Clone(asset)
+	differentEnd[len(differentEnd)-1] = 'X'
+	writeFile(t, filepath.Join(downloads, "asset-v2.psd"), differentEnd, old)
+	// Same size, same start and end, different middle: dropped by the full hash.
+	differentMiddle := bytes.Clone(asset)
+	differentMiddle[len(differentMiddle)/2] = 'X'
+	writeFile(t, filepath.Join(downloads, "asset-v3.psd"), differentMiddle, old)
+	// Hidden files and unique files are ignored.
+	writeFile(t, filepath.Join(desktop, ".asset.psd"), asset, old)
+	writeFile(t, filepath.Join(desktop, "notes.txt"), []byte("notes"), old)
+
+	result, err := newTestDuplicatesTarget(docs, desktop, downloads).Scan()
+
+	require.NoError(t, err)
+	require.Len(t, result.Items, 3)
+	original := result.Items[0]
+	assert.Equal(t, filepath.Join(docs, "work", "asset.psd"), original.Path, "first root wins over the older copy")
+	assert.Equal(t, types.ItemStatusOriginal, original.Status)
+	for _, copy := range result.Items[1:] {
+		assert.True(t, copy.Status.Cleanable())
+		assert.Equal(t, original.Path, copy.Group)
+		assert.Contains(t, copy.DisplayName, "(copy of "+original.Path+")")
+	}
+	assert.Equal(t, original.Path, result.Items[0].Group)
+	assert.Equal(t, 2*int64(len(asset)), result.TotalSize)
+	assert.Equal(t, int64(2), result.TotalFileCount)
+}
+
+func TestDuplicatesTarget_Scan_OldestCopyWithinRootIsOriginal(t *testing.T) {
+	root := t.TempDir()
+	content := []byte("same content")
+	now := time.Now()
+	writeFile(t, filepath.Join(root, "b.txt"), content, now.AddDate(0, 0, -5))
+	writeFile(t, filepath.Join(root, "a.txt"), content, now)
+
+	result, err := newTestDuplicatesTarget(root).Scan()
+
+	require.NoError(t, err)
+	require.Len(t, result.Items, 2)
+	assert.Equal(t, filepath.Join(root, "b.txt"), result.Items[0].Path)
+	assert.Equal(t, filepath.Join(root, "a.txt"), result.Items[1].Path)
+}
+
+func TestDuplicatesTarget_Scan_SkipsHardLinksPackagesAndSmallFiles(t *testing.T) {
+	root := t.TempDir()
+	content := bytes.Repeat([]byte("x"), 4096)
+	now := time.Now()
+	writeFile(t, filepath.Join(root, "a.bin"), content, now)
+	require.NoError(t, os.Link(filepath.Join(root, "a.bin"), filepath.Join(root, "a-link.bin")))
+	writeFile(t, filepath.Join(root, "Tool.app", "Contents", "a.bin"), content, now)
+	writeFile(t, filepath.Join(root, "small1"), []byte("tiny"), now)
+	writeFile(t, filepath.Join(root, "small2"), []byte("tiny"), now)
+
+	target := newTestDuplicatesTarget(root)
+	target.category.MinSize = 1024
+	result, err := target.Scan()
+
+	require.NoError(t, err)
+	assert.Empty(t, result.Items)
+}
+
+func TestDuplicatesTarget_Scan_OverlappingRootsListFilesOnce(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "sub", "a.txt"), []byte("dup"), time.Now())
+	writeFile(t, filepath.Join(root, "b.txt"), []byte("dup"), time.Now())
+
+	result, err := newTestDuplicatesTarget(root, filepath.Join(root, "sub")).Scan()
+
+	require.NoError(t, err)
+	assert.Len(t, result.Items, 2)
+}
+
+func TestPartialHash_ReadsStartAndEnd(t *testing.T) {
+	dir := t.TempDir()
+	content := bytes.Repeat([]byte("a"), 3*partialHashSize)
+	a, b := filepath.Join(dir, "a"), filepath.Join(dir, "b")
+	writeFile(t, a, content, time.Now())
+	changed := bytes.Clone(content)
+	changed[len(changed)/2] = 'b'
+	writeFile(t, b, changed, time.Now())
+
+	ha, err := partialHash(a)
+	require.NoError(t, err)
+	hb, err := partialHash(b)
+	require.NoError(t, err)
+	assert.Equal(t, ha, hb, "the middle is not read")
+
+	fa, err := fullHash(a)
+	require.NoError(t, err)
+	fb, err := fullHash(b)
+	require.NoError(t, err)
+	assert.NotEqual(t, fa, fb)
+}
+
+func TestDuplicatesTarget_Clean_RefusesWhenOriginalIsGone(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "a.txt"), []byte("dup"), time.Now().AddDate(0, 0, -1))
+	writeFile(t, filepath.Join(root, "b.txt"), []byte("dup"), time.Now())
+
+	target := newTestDuplicatesTarget(root)
+	result, err := target.Scan()
+	require.NoError(t, err)
+	require.Len(t, result.Items, 2)
+	require.NoError(t, os.Remove(result.Items[0].Path))
+
+	cleaned, err := target.Clean(result.Items[1:])
+
+	require.NoError(t, err)
+	assert.Zero(t, cleaned.CleanedItems)
+	require.Len(t, cleaned.Errors, 1)
+	assert.Contains(t, cleaned.Errors[0], "original changed or missing")
+	assert.FileExists(t, filepath.Join(root, "b.txt"))
+
+	unknown, err := target.Clean([]types.CleanableItem{{Path: filepath.Join(root, "other.txt")}})
+	require.NoError(t, err)
+	assert.Contains(t, unknown.Errors[0], "not a known duplicate")
+}
+
+func TestDuplicatesTarget_Clean_RefusesWhenOriginalEditedToSameSize(t *testing.T) {
+	root := t.TempDir()
+	original := filepath.Join(root, "a.txt")
+	writeFile(t, original, []byte("dup"), time.Now().AddDate(0, 0, -1))
+	writeFile(t, filepath.Join(root, "b.txt"), []byte("dup"), time.Now())
+
+	target := newTestDuplicatesTarget(root)
+	result, err := target.Scan()
+	require.NoError(t, err)
+	require.Len(t, result.Items, 2)
+	require.Equal(t, original, result.Items[0].Path)
+	require.NoError(t, os.WriteFile(original, []byte("new"), 0o644))
+
+	cleaned, err := target.Clean(result.Items[1:])
+
+	require.NoError(t, err)
+	assert.Zero(t, cleaned.CleanedItems)
+	require.Len(t, cleaned.Errors, 1)
+	assert.Contains(t, cleaned.Errors[0], "original changed or missing")
+	assert.FileExists(t, filepath.Join(root, "b.txt"))
+}
@@ -0,0 +1,305 @@
+package target
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"html"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/2ykwang/mac-cleanup-go/internal/logger"
+	"github.com/2ykwang/mac-cleanup-go/internal/utils"
+)
+
+// installedAppDirs are searched for installed apps, one level of
+// subfolders deep (e.g. /Applications/Utilities). Overridable for testing.
+var installedAppDirs = []string{"/Applications", "~/Applications"}
+
+const (
+	// installerMountTimeout bounds mounting a disk image to look inside it.
+	installerMountTimeout = time.Minute
+	// maxPlistSize caps how much of an Info.plist is read from an archive.
+	maxPlistSize = 1 << 20
+)
+
+var (
+	plistBundleIDRe = regexp.MustCompile(`<key>CFBundleIdentifier</key>\s*<string>([^<]*)</string>`)
+	mountPointRe    = regexp.MustCompile(`<key>mount-point</key>\s*<string>([^<]*)</string>`)
+	imagePathRe     = regexp.MustCompile(`^\s*<string>([^<]*)</string>`)
+	dmgLicenseRe    = regexp.MustCompile(`<key>Software License Agreement</key>\s*<true/>`)
+	versionSuffixRe = regexp.MustCompile(`^ v?\d`)
+	zipAppPlistRe   = regexp.MustCompile(`^((?:[^/]+/)*?)([^/]+\.app)/Contents/Info\.plist$`)
+	payloadAppRe    = regexp.MustCompile(`(?:^|/)([^/]+\.app)(?:/|$)`)
+)
+
+// appRef is an app bundle found inside an installer.
+type appRef struct {
+	name     string // e.g. "Slack.app"
+	bundleID string // empty when it could not be read
+}
+
+// isInstallerFile reports whether path looks like an app installer.
+func isInstallerFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".dmg", ".pkg", ".zip":
+		return true
+	}
+	return false
+}
+
+// installedApps indexes the installed apps by name and, on first use, by bundle ID.
+type installedApps struct {
+	paths  []string
+	byName map[string]string // appKey -> app file name
+	byID   map[string]string // bundle ID -> app file name
+	index  *installerIndex   // what each installer contains, from earlier scans
+}
+
+func newInstalledApps(dirs []string, index *installerIndex) *installedApps {
+	a := &installedApps{byName: make(map[string]string), index: index}
+	for _, dir := range dirs {
+		dir = utils.ExpandPath(dir)
+		for _, pattern := range []string{"*.app", "*/*.app"} {
+			matches, _ := filepath.Glob(filepath.Join(dir, pattern))
+			for _, app := range matches {
+				a.paths = append(a.paths, app)
+				a.byName[appKey(filepath.Base(app))] = filepath.Base(app)
+			}
+		}
+	}
+	return a
+}
+
+// appKey normalizes an app bundle name for matching ("Slack.app" -> "slack").
+func appKey(name string) string {
+	return strings.ToLower(strings.TrimSuffix(name, ".app"))
+}
+
+// bundleIDs reads the bundle ID of every installed app once. Names match
+// most installers, so this is only done when one does not.
+func (a *installedApps) bundleIDs() map[string]string {
+	if a.byID == nil {
+		a.byID = make(map[string]string, len(a.paths))
+		for _, app := range a.paths {
+			if id := readBundleID(filepath.Join(app, "Contents", "Info.plist")); id != "" {
+				a.byID[id] = filepath.Base(app)
+			}
+		}
+	}
+	return a.byID
+}
+
+// matchInstaller returns the installed app that the installer at path
+// contains, matched by bundle name or bundle ID, or "" if none is installed.
+// Disk images named after an installed app are matched without mounting them.
+func (a *installedApps) matchInstaller(path string) string {
+	if len(a.paths) == 0 {
+		return ""
+	}
+	if strings.EqualFold(filepath.Ext(path), ".dmg") {
+		if app := a.matchFileName(path); app != "" {
+			return app
+		}
+	}
+	for _, ref := range a.index.apps(path) {
+		if app, ok := a.byName[appKey(ref.name)]; ok {
+			return app
+		}
+		if ref.bundleID == "" {
+			continue
+		}
+		if app, ok := a.bundleIDs()[ref.bundleID]; ok {
+			return app
+		}
+	}
+	return ""
+}
+
+// matchFileName returns the installed app an installer file is named after,
+// alone or followed by a version (e.g. "Slack-4.36.1.dmg", "Firefox 120.0.dmg").
+// The longest matching app name wins.
+func (a *installedApps) matchFileName(path string) string {
+	stem := installerNameKey(strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)))
+	var best string
+	for key, app := range a.byName {
+		rest, ok := strings.CutPrefix(stem, installerNameKey(key))
+		if !ok || (rest != "" && !versionSuffixRe.MatchString(rest)) {
+			continue
+		}
+		if len(app) > len(best) || (len(app) == len(best) && app < best) {
+			best = app
+		}
+	}
+	return best
+}
+
+// installerNameKey lowercases name and treats '-', '_' and '.' as spaces.
+func installerNameKey(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '-', '_', '.':
+			return ' '
+		}
+		return r
+	}, strings.ToLower(name))
+}
+
+// installerApps lists the app bundles inside an installer. Only disk images
+// report an error, as a failed mount may succeed next time.
+func installerApps(path string) ([]appRef, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".zip":
+		return zipApps(path), nil
+	case ".pkg":
+		return pkgApps(path), nil
+	case ".dmg":
+		return dmgApps(path)
+	}
+	return nil, nil
+}
+
+// zipApps reads the top-level app bundles and their Info.plist from a zip archive.
+func zipApps(path string) []appRef {
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		logger.Debug("installer zip unreadable", "path", path, "error", err)
+		return nil
+	}
+	defer r.Close()
+
+	var apps []appRef
+	for _, f := range r.File {
+		m := zipAppPlistRe.FindStringSubmatch(f.Name)
+		// Skip resource forks and apps nested in another bundle.
+		if m == nil || strings.HasPrefix(f.Name, "__MACOSX/") || strings.Contains(m[1], ".app/") {
+			continue
+		}
+		ref := appRef{name: m[2]}
+		if rc, err := f.Open(); err == nil {
+			data, _ := io.ReadAll(io.LimitReader(rc, maxPlistSize))
+			rc.Close()
+			ref.bundleID = plistBundleID(data)
+		}
+		apps = append(apps, ref)
+	}
+	return apps
+}
+
+// pkgApps lists the app bundles in a flat package's payload. Bundle IDs are
+// not read; packages are matched by app name.
+func pkgApps(path string) []appRef {
+	output, err := execCommand("pkgutil", "--payload-files", path).Output()
+	if err != nil {
+		logger.Debug("pkgutil --payload-files failed", "path", path, "error", err)
+		return nil
+	}
+	seen := make(map[string]bool)
+	var apps []appRef
+	for _, line := range strings.Split(string(output), "\n") {
+		m := payloadAppRe.FindStringSubmatch(line)
+		if m == nil || seen[m[1]] {
+			continue
+		}
+		seen[m[1]] = true
+		apps = append(apps, appRef{name: m[1]})
+	}
+	return apps
+}
+
+// dmgApps mounts a disk image read-only and out of sight, reads the app
+// bundles at the top of each mounted volume, and detaches it again. Images
+// that ask for a license agreement are skipped rather than accepted.
+func dmgApps(path string) ([]appRef, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), installerMountTimeout)
+	defer cancel()
+
+	info, err := execCommandContext(ctx, "hdiutil", "imageinfo", "-plist", path).Output()
+	if err != nil {
+		logger.Debug("hdiutil imageinfo failed", "path", path, "error", err)
+		return nil, err
+	}
+	if dmgLicenseRe.Match(info) {
+		logger.Debug("disk image has a license agreement, not mounting", "path", path)
+		return nil, nil
+	}
+
+	// Stdin stays empty, so a license prompt missed above declines the mount.
+	output, err := execCommandContext(ctx, "hdiutil", "attach", "-nobrowse", "-readonly", "-noautoopen", "-plist", path).Output()
+	if err != nil {
+		logger.Debug("hdiutil attach failed", "path", path, "error", err)
+		// The image may have been attached before the timeout hit.
+		detachImage(path)
+		return nil, err
+	}
+
+	var apps []appRef
+	for _, m := range mountPointRe.FindAllStringSubmatch(string(output), -1) {
+		mount := m[1]
+		matches, _ := filepath.Glob(filepath.Join(mount, "*.app"))
+		for _, app := range matches {
+			apps = append(apps, appRef{
+				name:     filepath.Base(app),
+				bundleID: readBundleID(filepath.Join(app, "Contents", "Info.plist")),
+			})
+		}
+		detachVolume(mount)
+	}
+	return apps, nil
+}
+
+// detachImage detaches every volume `hdiutil info` lists as mounted from
+// the image at path.
+func detachImage(path string) {
+	output, err := execCommand("hdiutil", "info", "-plist").Output()
+	if err != nil {
+		logger.Debug("hdiutil info failed", "error", err)
+		return
+	}
+	// Each image's dict starts at its image-path; its mount points follow.
+	for _, image := range strings.Split(string(output), "<key>image-path</key>")[1:] {
+		m := imagePathRe.FindStringSubmatch(image)
+		if m == nil || html.UnescapeString(m[1]) != path {
+			continue
+		}
+		for _, mount := range mountPointRe.FindAllStringSubmatch(image, -1) {
+			detachVolume(html.UnescapeString(mount[1]))
+		}
+	}
+}
+
+func detachVolume(mount string) {
+	if err := execCommand("hdiutil", "detach", mount, "-quiet").Run(); err != nil {
+		logger.Warn("hdiutil detach failed", "mount", mount, "error", err)
+	}
+}
+
+func readBundleID(plistPath string) string {
+	data, err := os.ReadFile(plistPath)
+	if err != nil {
+		return ""
+	}
+	return plistBundleID(data)
+}
+
+// plistBundleID returns CFBundleIdentifier from an XML or binary property list.
+// Binary lists are converted by plutil.
+func plistBundleID(data []byte) string {
+	if !bytes.HasPrefix(data, []byte("bplist")) {
+		if m := plistBundleIDRe.FindSubmatch(data); m != nil {
+			return strings.TrimSpace(string(m[1]))
+		}
+		return ""
+	}
+	cmd := execCommand("plutil", "-extract", "CFBundleIdentifier", "raw", "-o", "-", "-")
+	cmd.Stdin = bytes.NewReader(data)
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
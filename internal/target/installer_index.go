@@ -0,0 +1,125 @@
+package target
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/2ykwang/mac-cleanup-go/internal/logger"
+)
+
+const (
+	// installerIndexFile is the installer contents index, relative to $HOME.
+	installerIndexFile = ".config/mac-cleanup-go/installer-index.json"
+	// installerIndexVersion is bumped whenever the index layout changes;
+	// an index with another version is rebuilt.
+	installerIndexVersion = 1
+)
+
+// indexedInstaller records the apps found in an installer as of its size
+// and mtime. A replaced or re-downloaded installer is looked into again.
+type indexedInstaller struct {
+	Size    int64        `json:"size"`
+	ModTime int64        `json:"mtime"`
+	Apps    []indexedApp `json:"apps,omitempty"`
+}
+
+type indexedApp struct {
+	Name     string `json:"name"`
+	BundleID string `json:"bundle_id,omitempty"`
+}
+
+type installerIndexData struct {
+	Version    int                         `json:"version"`
+	Installers map[string]indexedInstaller `json:"installers"`
+}
+
+// installerIndex lets a scan skip mounting disk images and reading archives
+// that have not changed since the previous scan. Installers not looked into
+// by a scan are dropped when it is saved.
+type installerIndex struct {
+	path string // empty keeps the index in memory only
+	prev map[string]indexedInstaller
+	next map[string]indexedInstaller
+}
+
+// defaultInstallerIndexPath returns where the index is kept, or "" without a home directory.
+func defaultInstallerIndexPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, installerIndexFile)
+}
+
+// loadInstallerIndex reads the index at path. A missing, unreadable,
+// corrupt or outdated index is discarded and every installer is looked
+// into again.
+func loadInstallerIndex(path string) *installerIndex {
+	idx := &installerIndex{
+		path: path,
+		prev: make(map[string]indexedInstaller),
+		next: make(map[string]indexedInstaller),
+	}
+	if path == "" {
+		return idx
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			logger.Warn("installer index unreadable, rebuilding", "path", path, "error", err)
+		}
+		return idx
+	}
+	var prev installerIndexData
+	if err := json.Unmarshal(data, &prev); err != nil || prev.Version != installerIndexVersion || prev.Installers == nil {
+		logger.Warn("installer index invalid, rebuilding", "path", path, "version", prev.Version, "error", err)
+		return idx
+	}
+	idx.prev = prev.Installers
+	return idx
+}
+
+// apps returns the app bundles inside the installer at path, looking inside
+// it only when it changed since the last scan. Installers that could not be
+// read, such as a disk image whose mount timed out, are tried again next time.
+func (idx *installerIndex) apps(path string) []appRef {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil
+	}
+	size, mtime := info.Size(), info.ModTime().UnixNano()
+
+	entry, ok := idx.prev[path]
+	if !ok || entry.Size != size || entry.ModTime != mtime {
+		refs, err := installerApps(path)
+		if err != nil {
+			return nil
+		}
+		entry = indexedInstaller{Size: size, ModTime: mtime}
+		for _, ref := range refs {
+			entry.Apps = append(entry.Apps, indexedApp{Name: ref.name, BundleID: ref.bundleID})
+		}
+	}
+	idx.next[path] = entry
+
+	refs := make([]appRef, 0, len(entry.Apps))
+	for _, app := range entry.Apps {
+		refs = append(refs, appRef{name: app.Name, bundleID: app.BundleID})
+	}
+	return refs
+}
+
+// save writes what this scan looked into, replacing the previous index atomically.
+func (idx *installerIndex) save() error {
+	if idx.path == "" {
+		return nil
+	}
+	data, err := json.Marshal(installerIndexData{Version: installerIndexVersion, Installers: idx.next})
+	if err != nil {
+		return err
+	}
+	return writeIndexFile(idx.path, data)
+}
@@ -0,0 +1,297 @@
+package target
+
+import (
+	"archive/zip"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/2ykwang/mac-cleanup-go/internal/types"
+)
+
+func infoPlist(bundleID string) string {
+	return `<?xml version="1.0" encoding="UTF-8"?>
+<plist version="1.0"><dict>
+	<key>CFBundleIdentifier</key>
+	<string>` + bundleID + `</string>
+</dict></plist>`
+}
+
+// makeApp creates a minimal app bundle with an XML Info.plist.
+func makeApp(t *testing.T, dir, name, bundleID string) {
+	t.Helper()
+	contents := filepath.Join(dir, name, "Contents")
+	require.NoError(t, os.MkdirAll(contents, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(contents, "Info.plist"), []byte(infoPlist(bundleID)), 0o644))
+}
+
+// makeZip writes a zip archive with the given files and contents.
+func makeZip(t *testing.T, path string, files map[string]string) {
+	t.Helper()
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	w := zip.NewWriter(f)
+	for name, content := range files {
+		fw, err := w.Create(name)
+		require.NoError(t, err)
+		_, err = fw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+	require.NoError(t, f.Close())
+}
+
+// withInstalledApps points installedAppDirs at a temp dir holding the given apps (name -> bundle ID).
+func withInstalledApps(t *testing.T, apps map[string]string) {
+	t.Helper()
+	dir := t.TempDir()
+	for name, id := range apps {
+		makeApp(t, dir, name, id)
+	}
+	original := installedAppDirs
+	installedAppDirs = []string{dir}
+	t.Cleanup(func() { installedAppDirs = original })
+}
+
+func TestIsInstallerFile(t *testing.T) {
+	for _, path := range []string{"a.dmg", "b.PKG", "c.zip"} {
+		assert.True(t, isInstallerFile(path), path)
+	}
+	assert.False(t, isInstallerFile("notes.txt"))
+}
+
+func TestZipApps(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "Tool.zip")
+	makeZip(t, path, map[string]string{
+		"Tool.app/Contents/Info.plist":                                infoPlist("com.example.tool"),
+		"Tool.app/Contents/Frameworks/Helper.app/Contents/Info.plist": infoPlist("com.example.helper"),
+		"__MACOSX/Tool.app/Contents/Info.plist":                       "resource fork",
+		"Extras/Other.app/Contents/Info.plist":                        infoPlist("com.example.other"),
+		"Tool.app/Contents/MacOS/tool":                                "binary",
+	})
+
+	apps := zipApps(path)
+
+	assert.ElementsMatch(t, []appRef{
+		{name: "Tool.app", bundleID: "com.example.tool"},
+		{name: "Other.app", bundleID: "com.example.other"},
+	}, apps)
+}
+
+func TestPkgApps(t *testing.T) {
+	stubRuntimeCLI(t, nil, map[string]string{
+		"pkgutil --payload-files /dl/Tool.pkg": ".\n./Tool.app\n./Tool.app/Contents/Info.plist\n./Library/LaunchAgents/x.plist\n",
+	})
+
+	assert.Equal(t, []appRef{{name: "Tool.app"}}, pkgApps("/dl/Tool.pkg"))
+}
+
+// stubHdiutil makes execCommandContext print outputs[cmdline], failing for
+// commands without one, and returns the command lines it ran.
+func stubHdiutil(t *testing.T, outputs map[string]string) *[]string {
+	t.Helper()
+	originalCtx := execCommandContext
+	t.Cleanup(func() { execCommandContext = originalCtx })
+	var ran []string
+	execCommandContext = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		line := strings.Join(append([]string{name}, args...), " ")
+		ran = append(ran, line)
+		out, ok := outputs[line]
+		if !ok {
+			return exec.CommandContext(ctx, "false")
+		}
+		return exec.CommandContext(ctx, "printf", "%s", out)
+	}
+	return &ran
+}
+
+const plainImageInfo = `<plist><dict><key>Properties</key><dict>
+<key>Software License Agreement</key><false/></dict></dict></plist>`
+
+func TestDmgApps_MountsReadsAndDetaches(t *testing.T) {
+	mount := t.TempDir()
+	makeApp(t, mount, "Viewer.app", "com.example.viewer")
+
+	ran := stubHdiutil(t, map[string]string{
+		"hdiutil imageinfo -plist /dl/Viewer.dmg": plainImageInfo,
+		"hdiutil attach -nobrowse -readonly -noautoopen -plist /dl/Viewer.dmg": `<plist><dict><key>system-entities</key><array>
+<dict><key>content-hint</key><string>Apple_HFS</string><key>mount-point</key><string>` + mount + `</string></dict>
+</array></dict></plist>`,
+	})
+	calls := stubRuntimeCLI(t, nil, nil)
+
+	apps, err := dmgApps("/dl/Viewer.dmg")
+
+	require.NoError(t, err)
+	assert.Equal(t, []appRef{{name: "Viewer.app", bundleID: "com.example.viewer"}}, apps)
+	assert.Equal(t, []string{
+		"hdiutil imageinfo -plist /dl/Viewer.dmg",
+		"hdiutil attach -nobrowse -readonly -noautoopen -plist /dl/Viewer.dmg",
+	}, *ran)
+	assert.Equal(t, []string{"hdiutil detach " + mount + " -quiet"}, *calls)
+}
+
+func TestDmgApps_SkipsImageWithLicense(t *testing.T) {
+	ran := stubHdiutil(t, map[string]string{
+		"hdiutil imageinfo -plist /dl/Licensed.dmg": `<plist><dict><key>Properties</key><dict>
+<key>Software License Agreement</key>
+<true/></dict></dict></plist>`,
+	})
+	calls := stubRuntimeCLI(t, nil, nil)
+
+	apps, err := dmgApps("/dl/Licensed.dmg")
+
+	require.NoError(t, err)
+	assert.Empty(t, apps)
+	assert.Equal(t, []string{"hdiutil imageinfo -plist /dl/Licensed.dmg"}, *ran, "never attached")
+	assert.Empty(t, *calls)
+}
+
+func TestDmgApps_DetachesAfterFailedAttach(t *testing.T) {
+	stubHdiutil(t, map[string]string{"hdiutil imageinfo -plist /dl/A & B.dmg": plainImageInfo})
+	calls := stubRuntimeCLI(t, nil, map[string]string{
+		"hdiutil info -plist": `<plist><dict><key>images</key><array>
+<dict><key>image-path</key><string>/dl/Other.dmg</string><key>system-entities</key><array>
+<dict><key>mount-point</key><string>/private/tmp/other</string></dict></array></dict>
+<dict><key>image-path</key><string>/dl/A &amp; B.dmg</string><key>system-entities</key><array>
+<dict><key>content-hint</key><string>GUID_partition_scheme</string></dict>
+<dict><key>mount-point</key><string>/private/tmp/dmg.x1</string></dict></array></dict>
+</array></dict></plist>`,
+	})
+
+	apps, err := dmgApps("/dl/A & B.dmg")
+
+	require.Error(t, err)
+	assert.Empty(t, apps)
+	assert.Equal(t, []string{"hdiutil info -plist", "hdiutil detach /private/tmp/dmg.x1 -quiet"}, *calls)
+}
+
+func TestInstalledApps_MatchInstaller(t *testing.T) {
+	withInstalledApps(t, map[string]string{
+		"Tool.app":        "com.example.tool",
+		"Renamed Pro.app": "com.example.renamed",
+	})
+	dir := t.TempDir()
+	byName := filepath.Join(dir, "tool-2.0.zip")
+	makeZip(t, byName, map[string]string{"Tool.app/Contents/Info.plist": infoPlist("com.other.id")})
+	byID := filepath.Join(dir, "renamed.zip")
+	makeZip(t, byID, map[string]string{"Renamed.app/Contents/Info.plist": infoPlist("com.example.renamed")})
+	missing := filepath.Join(dir, "new.zip")
+	makeZip(t, missing, map[string]string{"New.app/Contents/Info.plist": infoPlist("com.example.new")})
+
+	apps := newInstalledApps(installedAppDirs, loadInstallerIndex(""))
+
+	assert.Equal(t, "Tool.app", apps.matchInstaller(byName))
+	assert.Equal(t, "Renamed Pro.app", apps.matchInstaller(byID))
+	assert.Empty(t, apps.matchInstaller(missing))
+}
+
+func TestInstalledApps_MatchInstaller_DmgByFileNameWithoutMounting(t *testing.T) {
+	withInstalledApps(t, map[string]string{
+		"Visual Studio.app":      "com.example.vs",
+		"Visual Studio Code.app": "com.example.code",
+		"Slack.app":              "com.example.slack",
+	})
+	originalCtx := execCommandContext
+	t.Cleanup(func() { execCommandContext = originalCtx })
+	var attached []string
+	execCommandContext = func(ctx context.Context, _ string, args ...string) *exec.Cmd {
+		attached = append(attached, args[len(args)-1])
+		return exec.CommandContext(ctx, "false")
+	}
+	stubRuntimeCLI(t, nil, nil)
+
+	apps := newInstalledApps(installedAppDirs, loadInstallerIndex(""))
+
+	assert.Equal(t, "Slack.app", apps.matchInstaller("/dl/Slack-4.36.1.dmg"))
+	assert.Equal(t, "Slack.app", apps.matchInstaller("/dl/slack.dmg"))
+	assert.Equal(t, "Visual Studio Code.app", apps.matchInstaller("/dl/Visual Studio Code 1.90.dmg"))
+	assert.Empty(t, attached, "named images are not mounted")
+
+	assert.Empty(t, apps.matchInstaller("/dl/Slacker.dmg"))
+	assert.Empty(t, apps.matchInstaller("/dl/Slack Helper.dmg"))
+}
+
+func TestOldDownloadTarget_ReusesInstallerIndex(t *testing.T) {
+	withInstalledApps(t, map[string]string{"Tool.app": "com.example.tool"})
+	dir := t.TempDir()
+	installer := filepath.Join(dir, "setup.zip")
+	makeZip(t, installer, map[string]string{"Tool.app/Contents/Info.plist": infoPlist("com.example.tool")})
+	info, err := os.Stat(installer)
+	require.NoError(t, err)
+
+	target := NewOldDownloadTarget(types.Category{
+		ID:         "old-downloads",
+		Method:     types.MethodBuiltin,
+		Paths:      []string{filepath.Join(dir, "*")},
+		Installers: true,
+	}, 30)
+	target.indexPath = filepath.Join(t.TempDir(), "installer-index.json")
+	first, err := target.Scan()
+	require.NoError(t, err)
+	require.Len(t, first.Items, 1)
+
+	// Same size and mtime: the installer is not read again.
+	require.NoError(t, os.WriteFile(installer, make([]byte, info.Size()), 0o644))
+	require.NoError(t, os.Chtimes(installer, info.ModTime(), info.ModTime()))
+	second, err := target.Scan()
+	require.NoError(t, err)
+	assert.Len(t, second.Items, 1)
+
+	// A changed installer is looked into again.
+	later := info.ModTime().Add(time.Second)
+	require.NoError(t, os.Chtimes(installer, later, later))
+	third, err := target.Scan()
+	require.NoError(t, err)
+	assert.Empty(t, third.Items)
+}
+
+func TestOldDownloadTarget_OffersInstalledInstallersRegardlessOfAge(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	withInstalledApps(t, map[string]string{"Tool.app": "com.example.tool"})
+	dir := t.TempDir()
+	makeZip(t, filepath.Join(dir, "Tool-2.0.zip"), map[string]string{"Tool.app/Contents/Info.plist": infoPlist("com.example.tool")})
+	makeZip(t, filepath.Join(dir, "New-1.0.zip"), map[string]string{"New.app/Contents/Info.plist": infoPlist("com.example.new")})
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "recent.txt"), []byte("x"), 0o644))
+
+	cat := types.Category{
+		ID:         "old-downloads",
+		Method:     types.MethodBuiltin,
+		Paths:      []string{filepath.Join(dir, "*")},
+		Installers: true,
+		MinAgeDays: 30,
+	}
+	result, err := NewOldDownloadTarget(cat, 30).Scan()
+
+	require.NoError(t, err)
+	require.Len(t, result.Items, 1)
+	assert.Equal(t, "Tool-2.0.zip", result.Items[0].Name)
+	assert.Equal(t, "Tool-2.0.zip (Tool.app installed)", result.Items[0].DisplayName)
+	assert.Equal(t, result.Items[0].Size, result.TotalSize)
+
+	cat.Installers = false
+	without, err := NewOldDownloadTarget(cat, 30).Scan()
+	require.NoError(t, err)
+	assert.Empty(t, without.Items)
+}
+
+func TestDefaultRegistry_OldDownloadsUsesMinAgeDays(t *testing.T) {
+	cfg := &types.Config{Categories: []types.Category{
+		{ID: "old-downloads", Method: types.MethodBuiltin, Paths: []string{"~/Downloads/*"}, MinAgeDays: 90},
+	}}
+
+	r, err := DefaultRegistry(cfg)
+
+	require.NoError(t, err)
+	s, ok := r.Get("old-downloads")
+	require.True(t, ok)
+	require.IsType(t, &OldDownloadTarget{}, s)
+	assert.Equal(t, 90, s.(*OldDownloadTarget).daysOld)
+}
@@ -0,0 +1,241 @@
+package target
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/2ykwang/mac-cleanup-go/internal/logger"
+	"github.com/2ykwang/mac-cleanup-go/internal/types"
+	"github.com/2ykwang/mac-cleanup-go/internal/utils"
+)
+
+const (
+	// defaultLargeFileSize and defaultLargeFileDays apply when the category
+	// sets no min_size or min_age_days.
+	defaultLargeFileSize = 1 << 30
+	defaultLargeFileDays = 90
+)
+
+// vmBundleExts are package directories holding a virtual machine or disk
+// image. They are reported whole instead of being skipped like other packages.
+var vmBundleExts = map[string]struct{}{
+	".pvm": {}, ".vmwarevm": {}, ".utm": {}, ".sparsebundle": {},
+}
+
+// LargeFilesTarget walks $HOME for big files that have not been modified in
+// a while. It walks the same roots and skips the same directories as the
+// project-cache scan, and leaves the caches that scan finds to it.
+type LargeFilesTarget struct {
+	category types.Category
+	walker   *ProjectCacheTarget
+	minSize  int64
+	daysOld  int
+}
+
+// NewLargeFilesTarget takes its roots, exclude_dirs and max_depth from the
+// project-cache category in categories, when there is one.
+func NewLargeFilesTarget(cat types.Category, categories []types.Category) *LargeFilesTarget {
+	walkCat := types.Category{ID: "project-cache"}
+	for _, c := range categories {
+		if c.ID == walkCat.ID {
+			walkCat = c
+			break
+		}
+	}
+
+	t := &LargeFilesTarget{
+		category: cat,
+		walker:   NewProjectCacheTarget(walkCat),
+		minSize:  defaultLargeFileSize,
+		daysOld:  defaultLargeFileDays,
+	}
+	if cat.MinSize > 0 {
+		t.minSize = int64(cat.MinSize)
+	}
+	if cat.MinAgeDays > 0 {
+		t.daysOld = cat.MinAgeDays
+	}
+	return t
+}
+
+func (t *LargeFilesTarget) Category() types.Category { return t.category }
+func (t *LargeFilesTarget) IsAvailable() bool        { return t.walker.IsAvailable() }
+
+func (t *LargeFilesTarget) Scan() (*types.ScanResult, error) {
+	result := types.NewScanResult(t.category)
+	if !t.IsAvailable() {
+		return result, nil
+	}
+
+	start := time.Now()
+	cutoff := time.Now().AddDate(0, 0, -t.daysOld)
+
+	seen := make(map[string]struct{})
+	for _, root := range t.walker.roots() {
+		for _, item := range t.walkRoot(root, cutoff) {
+			// Roots may overlap (e.g. an extra root under $HOME).
+			if _, dup := seen[item.Path]; dup {
+				continue
+			}
+			seen[item.Path] = struct{}{}
+			result.Items = append(result.Items, item)
+			result.TotalSize += item.Size
+			result.TotalFileCount += item.FileCount
+		}
+	}
+
+	sort.Slice(result.Items, func(i, j int) bool {
+		return result.Items[i].Size > result.Items[j].Size
+	})
+
+	logger.Info("large files scan complete",
+		"found", len(result.Items),
+		"total_size", result.TotalSize,
+		"total_ms", time.Since(start).Milliseconds())
+
+	return result, nil
+}
+
+// walkRoot returns the files below root of at least minSize that were last
+// modified before cutoff. Hidden entries, packages and directories named
+// like a project cache are not descended into.
+func (t *LargeFilesTarget) walkRoot(root string, cutoff time.Time) []types.CleanableItem {
+	maxDepth := t.walker.maxDepth
+	if maxDepth <= 0 {
+		maxDepth = maxScanDepth
+	}
+	cacheNames := make(map[string]struct{}, len(t.walker.patterns))
+	for _, p := range t.walker.patterns {
+		cacheNames[p.DirName] = struct{}{}
+	}
+
+	var items []types.CleanableItem
+	var walk func(dir string, depth int)
+	walk = func(dir string, depth int) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return
+		}
+		for _, e := range entries {
+			name := e.Name()
+			path := filepath.Join(dir, name)
+
+			if strings.HasPrefix(name, ".") {
+				continue
+			}
+			if depth == 0 && root == t.walker.scanRoot {
+				if _, excluded := excludeDirs[name]; excluded {
+					continue
+				}
+			}
+			if t.walker.isExcluded(path, name) || t.isExcluded(path) {
+				continue
+			}
+
+			if e.IsDir() {
+				ext := strings.ToLower(filepath.Ext(name))
+				if _, vm := vmBundleExts[ext]; vm {
+					if item, ok := t.bundleItem(root, path, cutoff); ok {
+						items = append(items, item)
+					}
+					continue
+				}
+				if _, pkg := packageExts[ext]; pkg {
+					continue
+				}
+				if _, cache := cacheNames[name]; cache {
+					continue
+				}
+				if depth+1 < maxDepth {
+					walk(path, depth+1)
+				}
+				continue
+			}
+			if !e.Type().IsRegular() {
+				continue
+			}
+			info, err := e.Info()
+			if err != nil || info.Size() < t.minSize || !info.ModTime().Before(cutoff) {
+				continue
+			}
+			items = append(items, t.item(root, path, info.Size(), 1, info.ModTime(), false))
+		}
+	}
+	walk(root, 0)
+
+	return items
+}
+
+// isExcluded reports whether path matches the category's exclude patterns.
+func (t *LargeFilesTarget) isExcluded(path string) bool {
+	for _, pattern := range t.category.Exclude {
+		if utils.MatchPathOrAncestor(utils.ExpandPath(pattern), path) {
+			return true
+		}
+	}
+	return false
+}
+
+// bundleItem measures a VM bundle as a whole; its newest file decides its age.
+func (t *LargeFilesTarget) bundleItem(root, path string, cutoff time.Time) (types.CleanableItem, bool) {
+	size, count, err := utils.GetDirSizeWithCount(path)
+	if err != nil || size < t.minSize {
+		return types.CleanableItem{}, false
+	}
+	modifiedAt := newestFileTime(path)
+	if !modifiedAt.Before(cutoff) {
+		return types.CleanableItem{}, false
+	}
+	return t.item(root, path, size, count, modifiedAt, true), true
+}
+
+// item builds a cleanable item grouped under its top-level folder below root,
+// so the preview lists everything from e.g. ~/Documents together.
+func (t *LargeFilesTarget) item(root, path string, size, count int64, modifiedAt time.Time, isDir bool) types.CleanableItem {
+	return types.CleanableItem{
+		Path:        path,
+		Size:        size,
+		FileCount:   count,
+		Name:        filepath.Base(path),
+		DisplayName: t.walker.relativeName(root, path),
+		IsDirectory: isDir,
+		ModifiedAt:  modifiedAt,
+		Group:       t.topLevelFolder(root, path),
+	}
+}
+
+// topLevelFolder returns the folder directly below root that holds path,
+// shown as ~/Name under $HOME, or root itself for files at the top.
+func (t *LargeFilesTarget) topLevelFolder(root, path string) string {
+	top := root
+	if rel, err := filepath.Rel(root, path); err == nil {
+		if first, _, nested := strings.Cut(rel, string(filepath.Separator)); nested {
+			top = filepath.Join(root, first)
+		}
+	}
+	if root == t.walker.scanRoot {
+		if top == root {
+			return "~"
+		}
+		return filepath.Join("~", filepath.Base(top))
+	}
+	return top
+}
+
+// Clean moves the selected files to the Trash. Large files are never deleted
+// permanently; the config validation keeps the category on method 'builtin'.
+func (t *LargeFilesTarget) Clean(items []types.CleanableItem) (*types.CleanResult, error) {
+	result := types.NewCleanResult(t.category)
+	if len(items) == 0 {
+		return result, nil
+	}
+
+	batchResult := utils.BatchTrash(items, types.BatchTrashOptions{
+		Category: t.category,
+	})
+	result.Merge(batchResult)
+	return result, nil
+}
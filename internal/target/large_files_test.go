@@ -0,0 +1,150 @@
+package target
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/2ykwang/mac-cleanup-go/internal/types"
+)
+
+// newTestLargeFilesTarget scans home for files of 1KB or more older than 30 days.
+func newTestLargeFilesTarget(home string) *LargeFilesTarget {
+	target := NewLargeFilesTarget(types.Category{
+		ID:         "large-files",
+		Name:       "Large Files",
+		Safety:     types.SafetyLevelRisky,
+		Method:     types.MethodBuiltin,
+		MinSize:    1024,
+		MinAgeDays: 30,
+	}, nil)
+	target.walker.scanRoot = home
+	return target
+}
+
+// writeSized creates a file of the given size modified daysAgo days ago.
+func writeSized(t *testing.T, path string, size int, daysAgo int) {
+	t.Helper()
+	writeFile(t, path, make([]byte, size), time.Now().AddDate(0, 0, -daysAgo))
+}
+
+func TestNewLargeFilesTarget_Defaults(t *testing.T) {
+	target := NewLargeFilesTarget(types.Category{ID: "large-files"}, nil)
+
+	assert.Equal(t, int64(defaultLargeFileSize), target.minSize)
+	assert.Equal(t, defaultLargeFileDays, target.daysOld)
+}
+
+func TestNewLargeFilesTarget_UsesProjectCacheWalkOptions(t *testing.T) {
+	categories := []types.Category{{ID: "project-cache", ProjectCache: types.ProjectCacheOptions{
+		ExcludeDirs: []string{"third_party"},
+		MaxDepth:    3,
+	}}}
+
+	target := NewLargeFilesTarget(types.Category{ID: "large-files"}, categories)
+
+	assert.Contains(t, target.walker.excludeNames, "third_party")
+	assert.Equal(t, 3, target.walker.maxDepth)
+}
+
+func TestLargeFilesTarget_Scan_FindsLargeOldFiles(t *testing.T) {
+	home := t.TempDir()
+	writeSized(t, filepath.Join(home, "Documents", "VMs", "win.vmdk"), 4096, 200)
+	writeSized(t, filepath.Join(home, "Documents", "report.pdf"), 2048, 100)
+	writeSized(t, filepath.Join(home, "Desktop", "backup.zip"), 3072, 60)
+	writeSized(t, filepath.Join(home, "dump.bin"), 1024, 60)
+	writeSized(t, filepath.Join(home, "Documents", "recent.mov"), 8192, 1)
+	writeSized(t, filepath.Join(home, "Documents", "small.txt"), 100, 200)
+
+	result, err := newTestLargeFilesTarget(home).Scan()
+
+	require.NoError(t, err)
+	names := make([]string, len(result.Items))
+	groups := make(map[string]string)
+	for i, item := range result.Items {
+		names[i] = item.DisplayName
+		groups[item.DisplayName] = item.Group
+	}
+	assert.Equal(t, []string{"Documents/VMs/win.vmdk", "Desktop/backup.zip", "Documents/report.pdf", "dump.bin"}, names)
+	assert.Equal(t, "~/Documents", groups["Documents/VMs/win.vmdk"])
+	assert.Equal(t, "~/Documents", groups["Documents/report.pdf"])
+	assert.Equal(t, "~/Desktop", groups["Desktop/backup.zip"])
+	assert.Equal(t, "~", groups["dump.bin"])
+	assert.Equal(t, int64(4096+3072+2048+1024), result.TotalSize)
+	assert.Equal(t, int64(4), result.TotalFileCount)
+}
+
+func TestLargeFilesTarget_Scan_SkipsExcludedDirectories(t *testing.T) {
+	home := t.TempDir()
+	writeSized(t, filepath.Join(home, "Library", "big.db"), 4096, 200)
+	writeSized(t, filepath.Join(home, ".hidden", "big.bin"), 4096, 200)
+	writeSized(t, filepath.Join(home, "src", "third_party", "big.tar"), 4096, 200)
+	writeSized(t, filepath.Join(home, "src", "app", "node_modules", "big.node"), 4096, 200)
+	writeSized(t, filepath.Join(home, "src", "Tool.app", "Contents", "big"), 4096, 200)
+	writeSized(t, filepath.Join(home, "Archive", "keep.iso"), 4096, 200)
+	writeSized(t, filepath.Join(home, "Archive", "old.iso"), 4096, 200)
+
+	target := newTestLargeFilesTarget(home)
+	target.walker.excludeNames["third_party"] = struct{}{}
+	target.category.Exclude = []string{filepath.Join(home, "Archive", "keep.iso")}
+	result, err := target.Scan()
+
+	require.NoError(t, err)
+	require.Len(t, result.Items, 1)
+	assert.Equal(t, "Archive/old.iso", result.Items[0].DisplayName)
+}
+
+func TestLargeFilesTarget_Scan_ReportsVMBundlesWhole(t *testing.T) {
+	home := t.TempDir()
+	bundle := filepath.Join(home, "Parallels", "Windows 11.pvm")
+	writeSized(t, filepath.Join(bundle, "disk.hdd", "part1"), 800, 120)
+	writeSized(t, filepath.Join(bundle, "disk.hdd", "part2"), 800, 120)
+	active := filepath.Join(home, "Parallels", "Linux.pvm")
+	writeSized(t, filepath.Join(active, "disk"), 4096, 0)
+
+	result, err := newTestLargeFilesTarget(home).Scan()
+
+	require.NoError(t, err)
+	require.Len(t, result.Items, 1)
+	item := result.Items[0]
+	assert.Equal(t, bundle, item.Path)
+	assert.True(t, item.IsDirectory)
+	assert.Equal(t, int64(1600), item.Size)
+	assert.Equal(t, int64(2), item.FileCount)
+	assert.Equal(t, "~/Parallels", item.Group)
+}
+
+func TestLargeFilesTarget_Scan_ExtraRootsShowFullPaths(t *testing.T) {
+	home := t.TempDir()
+	extra := t.TempDir()
+	writeSized(t, filepath.Join(extra, "Footage", "raw.mov"), 4096, 200)
+
+	target := newTestLargeFilesTarget(home)
+	target.walker.extraRoots = []string{extra}
+	result, err := target.Scan()
+
+	require.NoError(t, err)
+	require.Len(t, result.Items, 1)
+	assert.Equal(t, filepath.Join(extra, "Footage", "raw.mov"), result.Items[0].DisplayName)
+	assert.Equal(t, filepath.Join(extra, "Footage"), result.Items[0].Group)
+}
+
+func TestDefaultRegistry_LargeFiles(t *testing.T) {
+	cfg := &types.Config{Categories: []types.Category{
+		{ID: "project-cache", Method: types.MethodBuiltin, ProjectCache: types.ProjectCacheOptions{MaxDepth: 4}},
+		{ID: "large-files", Method: types.MethodBuiltin, MinAgeDays: 180},
+	}}
+
+	r, err := DefaultRegistry(cfg)
+
+	require.NoError(t, err)
+	s, ok := r.Get("large-files")
+	require.True(t, ok)
+	require.IsType(t, &LargeFilesTarget{}, s)
+	target := s.(*LargeFilesTarget)
+	assert.Equal(t, 180, target.daysOld)
+	assert.Equal(t, 4, target.walker.maxDepth)
+}
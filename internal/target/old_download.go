@@ -1,28 +1,41 @@
 package target
 
 import (
+	"fmt"
 	"time"
 
+	"github.com/2ykwang/mac-cleanup-go/internal/logger"
 	"github.com/2ykwang/mac-cleanup-go/internal/types"
 	"github.com/2ykwang/mac-cleanup-go/internal/utils"
 )
 
 const defaultDaysOld = 30
 
-// OldDownloadTarget scans for old files in the Downloads folder.
+// OldDownloadTarget scans for old files in the Downloads folder. With
+// installers enabled it also offers installers of apps that are already
+// installed, regardless of age.
 type OldDownloadTarget struct {
 	*PathTarget
 	daysOld int
+	// indexPath is where the apps found in each installer are kept
+	// between scans. Empty disables the on-disk index.
+	indexPath string
 }
 
 func NewOldDownloadTarget(cat types.Category, daysOld int) *OldDownloadTarget {
+	// Age is applied here to each item's own mtime, not by PathTarget,
+	// so installers can be kept whatever their age.
+	pathCat := cat
+	pathCat.MinAgeDays = 0
 	return &OldDownloadTarget{
-		PathTarget: NewPathTarget(cat),
+		PathTarget: NewPathTarget(pathCat),
 		daysOld:    daysOld,
+		indexPath:  defaultInstallerIndexPath(),
 	}
 }
 
-// Scan returns files older than the configured days' threshold.
+// Scan returns files older than the configured days' threshold, and
+// installers whose app is installed.
 func (s *OldDownloadTarget) Scan() (*types.ScanResult, error) {
 	// Get all items from PathTarget
 	result, err := s.PathTarget.Scan()
@@ -35,12 +48,30 @@ func (s *OldDownloadTarget) Scan() (*types.ScanResult, error) {
 	filtered := make([]types.CleanableItem, 0)
 	var totalSize int64
 	var totalFileCount int64
+	var installed *installedApps
+	if s.category.Installers {
+		installed = newInstalledApps(installedAppDirs, loadInstallerIndex(s.indexPath))
+	}
 
 	for _, item := range result.Items {
-		if item.ModifiedAt.Before(cutoff) {
-			filtered = append(filtered, item)
-			totalSize += item.Size
-			totalFileCount += item.FileCount
+		if !item.ModifiedAt.Before(cutoff) {
+			if installed == nil || !isInstallerFile(item.Path) {
+				continue
+			}
+			app := installed.matchInstaller(item.Path)
+			if app == "" {
+				continue
+			}
+			item.DisplayName = fmt.Sprintf("%s (%s installed)", item.Name, app)
+		}
+		filtered = append(filtered, item)
+		totalSize += item.Size
+		totalFileCount += item.FileCount
+	}
+
+	if installed != nil {
+		if err := installed.index.save(); err != nil {
+			logger.Warn("installer index not saved", "path", s.indexPath, "error", err)
 		}
 	}
 
@@ -0,0 +1,175 @@
+package target
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/2ykwang/mac-cleanup-go/internal/logger"
+	"github.com/2ykwang/mac-cleanup-go/internal/utils"
+)
+
+// pathMatch is a physical path produced by expanding one of a category's patterns.
+type pathMatch struct {
+	path        string
+	specificity int
+}
+
+// pathMatcher is implemented by targets whose items are expanded path patterns.
+// Only these targets take part in path ownership.
+type pathMatcher interface {
+	Target
+	matchPaths() []pathMatch
+}
+
+// ownershipAware is implemented by targets that honour path ownership when scanning.
+type ownershipAware interface {
+	setOwnership(o *Ownership)
+}
+
+// Overlap describes paths that two categories both match.
+type Overlap struct {
+	// Owner is the more specific category; its scan counts the paths.
+	Owner string
+	// Other is the category whose matches also cover the paths.
+	Other string
+	// Paths are the physical paths owned by Owner that Other also covers.
+	Paths []string
+}
+
+// Ownership assigns every physical path matched by path-based categories to
+// the most specific category, so no path is counted by two categories.
+// It is computed once, on first use, after glob expansion.
+type Ownership struct {
+	targets []pathMatcher
+
+	once     sync.Once
+	owners   map[string]string              // path -> owning category ID
+	holes    map[string]map[string][]string // category ID -> item path -> nested paths owned by others
+	overlaps []Overlap
+}
+
+func newOwnership(targets []pathMatcher) *Ownership {
+	return &Ownership{targets: targets}
+}
+
+// Overlaps returns the overlapping category pairs, sorted by owner and other ID.
+func (o *Ownership) Overlaps() []Overlap {
+	if o == nil {
+		return nil
+	}
+	o.once.Do(o.compute)
+	return o.overlaps
+}
+
+// ownedByOther reports whether path is owned by a category other than catID.
+func (o *Ownership) ownedByOther(catID, path string) bool {
+	if o == nil {
+		return false
+	}
+	o.once.Do(o.compute)
+	owner, ok := o.owners[path]
+	return ok && owner != catID
+}
+
+// holesIn returns paths inside item that belong to other categories.
+func (o *Ownership) holesIn(catID, item string) []string {
+	if o == nil {
+		return nil
+	}
+	o.once.Do(o.compute)
+	return o.holes[catID][item]
+}
+
+func (o *Ownership) compute() {
+	type claim struct {
+		category    string
+		order       int
+		specificity int
+	}
+
+	claims := make(map[string][]claim)
+	for order, t := range o.targets {
+		id := t.Category().ID
+		for _, m := range t.matchPaths() {
+			claims[m.path] = append(claims[m.path], claim{category: id, order: order, specificity: m.specificity})
+		}
+	}
+
+	o.owners = make(map[string]string, len(claims))
+	o.holes = make(map[string]map[string][]string)
+	pairs := make(map[[2]string][]string)
+
+	// Exact duplicates: the most specific pattern wins, then the earlier category.
+	for path, cs := range claims {
+		sort.SliceStable(cs, func(i, j int) bool {
+			if cs[i].specificity != cs[j].specificity {
+				return cs[i].specificity > cs[j].specificity
+			}
+			return cs[i].order < cs[j].order
+		})
+		owner := cs[0].category
+		o.owners[path] = owner
+		for _, c := range cs[1:] {
+			if c.category != owner {
+				key := [2]string{owner, c.category}
+				pairs[key] = append(pairs[key], path)
+			}
+		}
+	}
+
+	// Nested matches: a path inside another category's match belongs to the
+	// deeper match, and is subtracted from the enclosing item when scanned.
+	for path, owner := range o.owners {
+		for dir := filepath.Dir(path); dir != "/" && dir != "."; dir = filepath.Dir(dir) {
+			outer, ok := o.owners[dir]
+			if !ok {
+				continue
+			}
+			if outer == owner {
+				break
+			}
+			if o.holes[outer] == nil {
+				o.holes[outer] = make(map[string][]string)
+			}
+			o.holes[outer][dir] = append(o.holes[outer][dir], path)
+			key := [2]string{owner, outer}
+			pairs[key] = append(pairs[key], path)
+			break
+		}
+	}
+	for _, items := range o.holes {
+		for item, nested := range items {
+			items[item] = utils.PruneNested(nested)
+		}
+	}
+
+	for key, paths := range pairs {
+		sort.Strings(paths)
+		o.overlaps = append(o.overlaps, Overlap{Owner: key[0], Other: key[1], Paths: paths})
+	}
+	sort.Slice(o.overlaps, func(i, j int) bool {
+		if o.overlaps[i].Owner != o.overlaps[j].Owner {
+			return o.overlaps[i].Owner < o.overlaps[j].Owner
+		}
+		return o.overlaps[i].Other < o.overlaps[j].Other
+	})
+
+	logger.Info("path ownership resolved", "paths", len(o.owners), "overlaps", len(o.overlaps))
+	for _, ov := range o.overlaps {
+		logger.Debug("category overlap", "owner", ov.Owner, "other", ov.Other, "paths", len(ov.Paths))
+	}
+}
+
+// patternSpecificity counts the literal directory segments of a pattern.
+// Patterns with more fixed segments describe their paths more precisely.
+func patternSpecificity(pattern string) int {
+	count := 0
+	for _, seg := range strings.Split(utils.ExpandPath(pattern), "/") {
+		if seg != "" && !strings.ContainsAny(seg, "*?[\\") {
+			count++
+		}
+	}
+	return count
+}
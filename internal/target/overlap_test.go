@@ -0,0 +1,121 @@
+package target
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/2ykwang/mac-cleanup-go/internal/types"
+)
+
+func writeSizedFile(t *testing.T, path string, size int) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, make([]byte, size), 0o644))
+}
+
+func overlapCategory(id string, paths ...string) types.Category {
+	return types.Category{ID: id, Method: types.MethodTrash, Safety: types.SafetyLevelSafe, Paths: paths}
+}
+
+func TestOwnership_NestedPathCountedByMostSpecificCategory(t *testing.T) {
+	caches := t.TempDir()
+	writeSizedFile(t, filepath.Join(caches, "Google", "Chrome", "Default", "blob"), 100)
+	writeSizedFile(t, filepath.Join(caches, "Google", "other"), 10)
+
+	cfg := &types.Config{Categories: []types.Category{
+		overlapCategory("caches", filepath.Join(caches, "*")),
+		overlapCategory("chrome", filepath.Join(caches, "Google", "Chrome", "*")),
+	}}
+	registry, err := DefaultRegistry(cfg)
+	require.NoError(t, err)
+
+	cachesTarget, _ := registry.Get("caches")
+	chromeTarget, _ := registry.Get("chrome")
+	cachesResult, err := cachesTarget.Scan()
+	require.NoError(t, err)
+	chromeResult, err := chromeTarget.Scan()
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(10), cachesResult.TotalSize, "Chrome files belong to the chrome category")
+	assert.Equal(t, int64(100), chromeResult.TotalSize)
+
+	overlaps := registry.Overlaps()
+	require.Len(t, overlaps, 1)
+	assert.Equal(t, "chrome", overlaps[0].Owner)
+	assert.Equal(t, "caches", overlaps[0].Other)
+	assert.Equal(t, []string{filepath.Join(caches, "Google", "Chrome", "Default")}, overlaps[0].Paths)
+}
+
+func TestOwnership_DuplicatePathOwnedByMoreSpecificPattern(t *testing.T) {
+	caches := t.TempDir()
+	writeSizedFile(t, filepath.Join(caches, "com.spotify.client", "data"), 50)
+	writeSizedFile(t, filepath.Join(caches, "RandomApp", "data"), 5)
+
+	cfg := &types.Config{Categories: []types.Category{
+		overlapCategory("caches", filepath.Join(caches, "*")),
+		overlapCategory("spotify", filepath.Join(caches, "com.spotify.client")),
+	}}
+	registry, err := DefaultRegistry(cfg)
+	require.NoError(t, err)
+
+	cachesTarget, _ := registry.Get("caches")
+	result, err := cachesTarget.Scan()
+	require.NoError(t, err)
+
+	require.Len(t, result.Items, 1)
+	assert.Equal(t, "RandomApp", result.Items[0].Name)
+
+	overlaps := registry.Overlaps()
+	require.Len(t, overlaps, 1)
+	assert.Equal(t, "spotify", overlaps[0].Owner)
+	assert.Equal(t, "caches", overlaps[0].Other)
+}
+
+func TestOwnership_EqualSpecificityPrefersEarlierCategory(t *testing.T) {
+	dir := t.TempDir()
+	writeSizedFile(t, filepath.Join(dir, "shared", "data"), 5)
+
+	cfg := &types.Config{Categories: []types.Category{
+		overlapCategory("first", filepath.Join(dir, "*")),
+		overlapCategory("second", filepath.Join(dir, "sh*")),
+	}}
+	registry, err := DefaultRegistry(cfg)
+	require.NoError(t, err)
+
+	first, _ := registry.Get("first")
+	second, _ := registry.Get("second")
+	firstResult, _ := first.Scan()
+	secondResult, _ := second.Scan()
+
+	assert.Len(t, firstResult.Items, 1)
+	assert.Empty(t, secondResult.Items)
+}
+
+func TestOwnership_NoOverlaps(t *testing.T) {
+	dir := t.TempDir()
+	writeSizedFile(t, filepath.Join(dir, "a", "x"), 1)
+	writeSizedFile(t, filepath.Join(dir, "b", "x"), 1)
+
+	cfg := &types.Config{Categories: []types.Category{
+		overlapCategory("a", filepath.Join(dir, "a", "*")),
+		overlapCategory("b", filepath.Join(dir, "b", "*")),
+	}}
+	registry, err := DefaultRegistry(cfg)
+	require.NoError(t, err)
+
+	assert.Empty(t, registry.Overlaps())
+}
+
+func TestRegistry_Overlaps_NilWithoutOwnership(t *testing.T) {
+	assert.Nil(t, NewRegistry().Overlaps())
+}
+
+func TestPatternSpecificity(t *testing.T) {
+	assert.Equal(t, 2, patternSpecificity("/a/b/*"))
+	assert.Equal(t, 4, patternSpecificity("/a/b/c/d"))
+	assert.Equal(t, 2, patternSpecificity("/a/**/c"))
+}
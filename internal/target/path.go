@@ -5,13 +5,15 @@ import (
 	"path/filepath"
 	"sort"
 	"sync"
+	"time"
 
 	"github.com/2ykwang/mac-cleanup-go/internal/types"
 	"github.com/2ykwang/mac-cleanup-go/internal/utils"
 )
 
 type PathTarget struct {
-	category types.Category
+	category  types.Category
+	ownership *Ownership
 }
 
 func NewPathTarget(cat types.Category) *PathTarget {
@@ -22,6 +24,10 @@ func (s *PathTarget) Category() types.Category {
 	return s.category
 }
 
+func (s *PathTarget) setOwnership(o *Ownership) {
+	s.ownership = o
+}
+
 func (s *PathTarget) IsAvailable() bool {
 	for _, proc := range s.category.BlockedByProcesses {
 		if utils.IsProcessRunning(proc) {
@@ -35,7 +41,7 @@ func (s *PathTarget) IsAvailable() bool {
 	}
 
 	// For path-based methods, check if any of the paths have matching files
-	for _, pattern := range s.category.Paths {
+	for _, pattern := range resolvePaths(s.category) {
 		paths, err := utils.GlobPaths(pattern)
 		if err == nil && len(paths) > 0 {
 			return true
@@ -58,25 +64,64 @@ func (s *PathTarget) Scan() (*types.ScanResult, error) {
 		return result, nil
 	}
 
-	result.Items, result.TotalSize, result.TotalFileCount = s.scanPathsParallel(paths)
+	if s.category.Quota > 0 {
+		result.Items, result.TotalSize, result.TotalFileCount = s.applyQuota(s.scanFiles(paths))
+		return result, nil
+	}
+	result.Items, result.TotalSize, result.TotalFileCount = s.applyRetention(s.applyThresholds(s.scanPathsParallel(paths)))
 	return result, nil
 }
 
-// collectPaths gathers all paths from glob patterns, filtering out SIP protected paths
+// collectPaths gathers all paths from glob patterns, filtering out SIP protected paths.
 func (s *PathTarget) collectPaths() []string {
-	var paths []string
-	for _, pattern := range s.category.Paths {
+	return s.ownedPaths(s.matchPaths())
+}
+
+func (s *PathTarget) matchPaths() []pathMatch {
+	return s.expandPatterns(nil)
+}
+
+// expandPatterns globs the category's patterns, dropping SIP protected paths,
+// exclude matches and any path for which skip returns true.
+func (s *PathTarget) expandPatterns(skip func(string) bool) []pathMatch {
+	var matches []pathMatch
+	for _, pattern := range resolvePaths(s.category) {
 		matched, err := utils.GlobPaths(pattern)
 		if err != nil {
 			continue
 		}
+		specificity := patternSpecificity(pattern)
 		for _, p := range matched {
-			if !utils.IsSIPProtected(p) {
-				paths = append(paths, p)
+			if utils.IsSIPProtected(p) || s.isExcludedByPattern(p) || (skip != nil && skip(p)) {
+				continue
 			}
+			matches = append(matches, pathMatch{path: p, specificity: specificity})
+		}
+	}
+	return matches
+}
+
+// ownedPaths drops matches owned by a more specific category, and paths nested
+// inside another match, so sizes are not counted twice.
+func (s *PathTarget) ownedPaths(matches []pathMatch) []string {
+	paths := make([]string, 0, len(matches))
+	for _, m := range matches {
+		if !s.ownership.ownedByOther(s.category.ID, m.path) {
+			paths = append(paths, m.path)
 		}
 	}
-	return paths
+	return utils.PruneNested(paths)
+}
+
+// isExcludedByPattern reports whether path, or a directory containing it,
+// matches one of the category's exclude patterns.
+func (s *PathTarget) isExcludedByPattern(path string) bool {
+	for _, pattern := range s.category.Exclude {
+		if utils.MatchPathOrAncestor(utils.ExpandPath(utils.ExpandVars(pattern)), path) {
+			return true
+		}
+	}
+	return false
 }
 
 // scanPathsParallel scans multiple paths concurrently using a worker pool
@@ -127,19 +172,90 @@ func (s *PathTarget) scanPath(path string) (types.CleanableItem, error) {
 	}
 
 	var size, fileCount int64
+	var keep []string
 	if info.IsDir() {
 		size, fileCount, _ = utils.GetDirSizeWithCount(path)
+		// Nested paths owned by other categories are counted there, not here,
+		// and left in place when this item is cleaned.
+		for _, hole := range s.ownership.holesIn(s.category.ID, path) {
+			holeSize, holeCount, err := utils.GetDirSizeWithCount(hole)
+			if err != nil {
+				continue
+			}
+			size = max(size-holeSize, 0)
+			fileCount = max(fileCount-holeCount, 0)
+			keep = append(keep, hole)
+		}
 	} else {
 		size = info.Size()
 		fileCount = 1
 	}
 
+	modTime := info.ModTime()
+	if info.IsDir() && s.category.MinAgeDays > 0 {
+		// Directory mtime misses deeper changes, so age filtering needs the newest file.
+		if newest := newestFileTime(path); !newest.IsZero() {
+			modTime = newest
+		}
+	}
+
 	return types.CleanableItem{
 		Path:        path,
 		Size:        size,
 		FileCount:   fileCount,
 		Name:        filepath.Base(path),
 		IsDirectory: info.IsDir(),
-		ModifiedAt:  info.ModTime(),
+		ModifiedAt:  modTime,
+		KeepPaths:   keep,
 	}, nil
 }
+
+// applyThresholds drops items outside the category's age and size limits
+// and recomputes the totals. Items already held back by another rule are kept
+// as they are and not counted.
+func (s *PathTarget) applyThresholds(items []types.CleanableItem, totalSize, totalCount int64) ([]types.CleanableItem, int64, int64) {
+	passes := s.thresholdFilter()
+	if passes == nil {
+		return items, totalSize, totalCount
+	}
+
+	kept := items[:0]
+	totalSize, totalCount = 0, 0
+	for _, item := range items {
+		if !item.Status.Cleanable() {
+			kept = append(kept, item)
+			continue
+		}
+		if !passes(item) {
+			continue
+		}
+		kept = append(kept, item)
+		totalSize += item.Size
+		totalCount += item.FileCount
+	}
+	return kept, totalSize, totalCount
+}
+
+// thresholdFilter reports whether an item is within the category's age and
+// size limits, or returns nil when the category sets none.
+func (s *PathTarget) thresholdFilter() func(types.CleanableItem) bool {
+	if !s.category.HasThresholds() {
+		return nil
+	}
+
+	var cutoff time.Time
+	if s.category.MinAgeDays > 0 {
+		cutoff = time.Now().AddDate(0, 0, -s.category.MinAgeDays)
+	}
+	minSize, maxSize := int64(s.category.MinSize), int64(s.category.MaxSize)
+
+	return func(item types.CleanableItem) bool {
+		if !cutoff.IsZero() && item.ModifiedAt.After(cutoff) {
+			return false
+		}
+		if minSize > 0 && item.Size < minSize {
+			return false
+		}
+		return maxSize <= 0 || item.Size <= maxSize
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -252,6 +253,104 @@ func TestScan_HandlesMultiplePathPatterns(t *testing.T) {
 	assert.Len(t, result.Items, 2)
 }
 
+func TestScan_DoubleStarPattern_MatchesNestedDirs(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	for _, dir := range []string{"AppA/Cache", "AppB/sub/Cache", "AppB/sub/Cache/Cache"} {
+		os.MkdirAll(filepath.Join(tmpDir, dir), 0o755)
+		os.WriteFile(filepath.Join(tmpDir, dir, "data"), []byte("data"), 0o644)
+	}
+
+	cat := types.Category{
+		ID:    "test",
+		Paths: []string{filepath.Join(tmpDir, "*", "**", "Cache")},
+	}
+
+	s := NewPathTarget(cat)
+	result, err := s.Scan()
+
+	assert.NoError(t, err)
+	assert.Len(t, result.Items, 2, "nested Cache inside a matched Cache should not be counted twice")
+	assert.Equal(t, int64(12), result.TotalSize)
+}
+
+func TestScan_ExcludePatterns_SkipsMatchingPaths(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	for _, name := range []string{"com.apple.Safari", "com.apple.Music", "com.vpn.client", "org.keep"} {
+		os.MkdirAll(filepath.Join(tmpDir, name), 0o755)
+		os.WriteFile(filepath.Join(tmpDir, name, "data"), []byte("data"), 0o644)
+	}
+
+	cat := types.Category{
+		ID:      "test",
+		Paths:   []string{filepath.Join(tmpDir, "*")},
+		Exclude: []string{filepath.Join(tmpDir, "com.apple.*"), filepath.Join(tmpDir, "com.vpn.client")},
+	}
+
+	s := NewPathTarget(cat)
+	result, err := s.Scan()
+
+	assert.NoError(t, err)
+	assert.Len(t, result.Items, 1)
+	assert.Equal(t, "org.keep", result.Items[0].Name)
+}
+
+func TestScan_MinAgeDays_UsesNewestFileTime(t *testing.T) {
+	tmpDir := t.TempDir()
+	old := time.Now().AddDate(0, 0, -30)
+
+	for _, name := range []string{"stale", "touched"} {
+		dir := filepath.Join(tmpDir, name)
+		os.MkdirAll(dir, 0o755)
+		file := filepath.Join(dir, "data")
+		os.WriteFile(file, []byte("data"), 0o644)
+		os.Chtimes(file, old, old)
+		os.Chtimes(dir, old, old)
+	}
+	// A recent write deep inside "touched" leaves the directory mtime old.
+	os.WriteFile(filepath.Join(tmpDir, "touched", "data"), []byte("new!"), 0o644)
+	os.Chtimes(filepath.Join(tmpDir, "touched"), old, old)
+
+	cat := types.Category{
+		ID:         "test",
+		Paths:      []string{filepath.Join(tmpDir, "*")},
+		MinAgeDays: 14,
+	}
+
+	s := NewPathTarget(cat)
+	result, err := s.Scan()
+
+	assert.NoError(t, err)
+	assert.Len(t, result.Items, 1)
+	assert.Equal(t, "stale", result.Items[0].Name)
+	assert.Equal(t, int64(4), result.TotalSize)
+}
+
+func TestScan_SizeThresholds(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	os.WriteFile(filepath.Join(tmpDir, "small"), make([]byte, 10), 0o644)
+	os.WriteFile(filepath.Join(tmpDir, "medium"), make([]byte, 100), 0o644)
+	os.WriteFile(filepath.Join(tmpDir, "large"), make([]byte, 1000), 0o644)
+
+	cat := types.Category{
+		ID:      "test",
+		Paths:   []string{filepath.Join(tmpDir, "*")},
+		MinSize: 50,
+		MaxSize: 500,
+	}
+
+	s := NewPathTarget(cat)
+	result, err := s.Scan()
+
+	assert.NoError(t, err)
+	assert.Len(t, result.Items, 1)
+	assert.Equal(t, "medium", result.Items[0].Name)
+	assert.Equal(t, int64(100), result.TotalSize)
+	assert.Equal(t, int64(1), result.TotalFileCount)
+}
+
 func TestScan_HandlesScanPathError_Gracefully(t *testing.T) {
 	tmpDir, _ := os.MkdirTemp("", "path-scanner-test")
 	defer os.RemoveAll(tmpDir)
@@ -4,6 +4,7 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
@@ -72,26 +73,68 @@ const (
 
 type foundCache struct {
 	path    string
+	root    string
 	pattern cachePattern
+	repo    *gitRepo // nil outside a git repository
+	// protectedBy is the file that asked to keep this cache, if any.
+	protectedBy *cacheProtection
 }
 
-// ProjectCacheTarget scans $HOME recursively for stale build caches
-// inside project directories, using marker-file validation to avoid false positives.
+// ProjectCacheTarget scans $HOME (and any extra roots) recursively for stale
+// build caches inside project directories, using marker-file validation to
+// avoid false positives.
 type ProjectCacheTarget struct {
-	category  types.Category
-	scanRoot  string // overridable for testing
-	patterns  []cachePattern
-	staleDays int
+	category   types.Category
+	scanRoot   string // overridable for testing
+	extraRoots []string
+	patterns   []cachePattern
+	// excludeNames are skipped at any depth, excludePaths with everything below them.
+	excludeNames map[string]struct{}
+	excludePaths []string
+	maxDepth     int
+	staleDays    int
+	// indexPath is where directory listings are kept between scans. Empty disables the on-disk index.
+	indexPath string
 }
 
+// NewProjectCacheTarget applies the category's project_cache options on top
+// of the defaults. Extra patterns are tried after the built-in ones.
 func NewProjectCacheTarget(cat types.Category) *ProjectCacheTarget {
 	home, _ := os.UserHomeDir()
-	return &ProjectCacheTarget{
-		category:  cat,
-		scanRoot:  home,
-		patterns:  defaultPatterns,
-		staleDays: defaultStaleDays,
+	opts := cat.ProjectCache
+
+	t := &ProjectCacheTarget{
+		category:     cat,
+		scanRoot:     home,
+		patterns:     defaultPatterns,
+		excludeNames: make(map[string]struct{}),
+		maxDepth:     maxScanDepth,
+		staleDays:    defaultStaleDays,
+		indexPath:    defaultProjectIndexPath(),
 	}
+	for _, root := range opts.Roots {
+		t.extraRoots = append(t.extraRoots, filepath.Clean(utils.ExpandPath(root)))
+	}
+	if len(opts.Patterns) > 0 {
+		t.patterns = slices.Clone(defaultPatterns)
+		for _, p := range opts.Patterns {
+			t.patterns = append(t.patterns, cachePattern{DirName: p.Dir, MarkerFiles: p.Markers})
+		}
+	}
+	for _, dir := range opts.ExcludeDirs {
+		if filepath.IsAbs(dir) || strings.HasPrefix(dir, "~/") || strings.HasPrefix(dir, "$") {
+			t.excludePaths = append(t.excludePaths, filepath.Clean(utils.ExpandPath(dir)))
+		} else {
+			t.excludeNames[dir] = struct{}{}
+		}
+	}
+	if opts.MaxDepth > 0 {
+		t.maxDepth = opts.MaxDepth
+	}
+	if opts.StaleDays > 0 {
+		t.staleDays = opts.StaleDays
+	}
+	return t
 }
 
 func (t *ProjectCacheTarget) Category() types.Category { return t.category }
@@ -110,63 +153,50 @@ func (t *ProjectCacheTarget) Scan() (*types.ScanResult, error) {
 		patternMap[p.DirName] = append(patternMap[p.DirName], p)
 	}
 
-	var found []foundCache
-
-	//nolint:errcheck // WalkDir errors are handled per-entry
-	filepath.WalkDir(t.scanRoot, func(path string, d fs.DirEntry, err error) error {
-		if err != nil || !d.IsDir() || path == t.scanRoot {
-			return nil
-		}
-
-		name := d.Name()
-
-		rel, _ := filepath.Rel(t.scanRoot, path)
-		depth := strings.Count(rel, string(filepath.Separator)) + 1
-		if depth > maxScanDepth {
-			return fs.SkipDir
-		}
-
-		if depth == 1 {
-			if _, excluded := excludeDirs[name]; excluded {
-				return fs.SkipDir
-			}
-		}
+	idx := loadProjectCacheIndex(t.indexPath)
 
-		if patterns, ok := patternMap[name]; ok {
-			parentDir := filepath.Dir(path)
-			for _, p := range patterns {
-				if hasMarker(parentDir, p.MarkerFiles) {
-					found = append(found, foundCache{path: path, pattern: p})
-					break // first matching pattern wins (e.g. target/ → Cargo before Maven)
-				}
+	var found []foundCache
+	seen := make(map[string]struct{})
+	for _, root := range t.roots() {
+		for _, fc := range t.walkRoot(root, patternMap, idx) {
+			// Roots may overlap (e.g. an extra root under $HOME).
+			if _, dup := seen[fc.path]; dup {
+				continue
 			}
-			// Always prune cache-named dirs even without marker —
-			// they are likely tool dependencies, and recursing into them is expensive.
-			return fs.SkipDir
+			seen[fc.path] = struct{}{}
+			found = append(found, fc)
 		}
-
-		return nil
-	})
+	}
 
 	walkDuration := time.Since(start)
 	logger.Info("project cache walk complete",
 		"found", len(found),
 		"walk_ms", walkDuration.Milliseconds())
 
-	allItems, _, _ := t.calculateSizes(found)
+	found = filterGitCaches(found)
+
+	finder := newProtectionFinder()
+	for i, fc := range found {
+		found[i].protectedBy = finder.find(filepath.Dir(fc.path), fc.root, filepath.Base(fc.path))
+	}
+
+	if err := idx.save(); err != nil {
+		logger.Warn("project cache index not saved", "path", idx.path, "error", err)
+	}
 
-	// Filter to stale caches only — protect active projects
+	// Only stale caches are offered — protect active projects
 	cutoff := time.Now().AddDate(0, 0, -t.staleDays)
-	for _, item := range allItems {
-		if item.ModifiedAt.Before(cutoff) {
-			result.Items = append(result.Items, item)
+	staleItems, _, _ := t.calculateSizes(found, cutoff)
+	for _, item := range staleItems {
+		result.Items = append(result.Items, item)
+		if item.Status.Cleanable() {
 			result.TotalSize += item.Size
 			result.TotalFileCount += item.FileCount
 		}
 	}
 
 	logger.Info("project cache scan complete",
-		"found", len(allItems),
+		"found", len(found),
 		"stale", len(result.Items),
 		"total_size", result.TotalSize,
 		"total_ms", time.Since(start).Milliseconds())
@@ -174,7 +204,77 @@ func (t *ProjectCacheTarget) Scan() (*types.ScanResult, error) {
 	return result, nil
 }
 
-func (t *ProjectCacheTarget) calculateSizes(found []foundCache) ([]types.CleanableItem, int64, int64) {
+// roots returns $HOME followed by the extra roots that exist.
+func (t *ProjectCacheTarget) roots() []string {
+	roots := []string{t.scanRoot}
+	for _, root := range t.extraRoots {
+		if info, err := os.Stat(root); err != nil || !info.IsDir() {
+			logger.Debug("project cache root skipped", "root", root, "error", err)
+			continue
+		}
+		roots = append(roots, root)
+	}
+	return roots
+}
+
+// walkRoot finds the project caches below root. Directories unchanged since
+// the last scan are listed from the index instead of read again. The
+// built-in top-level excludes only apply to $HOME.
+func (t *ProjectCacheTarget) walkRoot(root string, patternMap map[string][]cachePattern, idx *projectCacheIndex) []foundCache {
+	maxDepth := t.maxDepth
+	if maxDepth <= 0 {
+		maxDepth = maxScanDepth
+	}
+
+	var found []foundCache
+	var walk func(dir string, depth int)
+	walk = func(dir string, depth int) {
+		if depth >= maxDepth {
+			return
+		}
+		for _, name := range idx.subdirs(dir) {
+			path := filepath.Join(dir, name)
+
+			if depth == 0 && root == t.scanRoot {
+				if _, excluded := excludeDirs[name]; excluded {
+					continue
+				}
+			}
+			if t.isExcluded(path, name) {
+				continue
+			}
+
+			if patterns, ok := patternMap[name]; ok {
+				for _, p := range patterns {
+					if hasMarker(dir, p.MarkerFiles) {
+						found = append(found, foundCache{path: path, root: root, pattern: p})
+						break // first matching pattern wins (e.g. target/ → Cargo before Maven)
+					}
+				}
+				// Never descend into cache-named dirs even without marker —
+				// they are likely tool dependencies, and recursing into them is expensive.
+				continue
+			}
+
+			walk(path, depth+1)
+		}
+	}
+	walk(root, 0)
+
+	return found
+}
+
+// isExcluded reports whether a directory matches the configured exclude_dirs.
+func (t *ProjectCacheTarget) isExcluded(path, name string) bool {
+	if _, ok := t.excludeNames[name]; ok {
+		return true
+	}
+	return slices.Contains(t.excludePaths, path)
+}
+
+// calculateSizes measures the stale caches in found. Caches used at or after
+// cutoff, or in a repository active since then, are left out.
+func (t *ProjectCacheTarget) calculateSizes(found []foundCache, cutoff time.Time) ([]types.CleanableItem, int64, int64) {
 	var (
 		wg         sync.WaitGroup
 		mu         sync.Mutex
@@ -192,25 +292,37 @@ func (t *ProjectCacheTarget) calculateSizes(found []foundCache) ([]types.Cleanab
 			defer wg.Done()
 			defer func() { <-sem }()
 
-			size, count, err := utils.GetDirSizeWithCount(fc.path)
-			if err != nil {
+			// Recent repository activity keeps a cache fresh even when
+			// nothing inside it was rebuilt.
+			if fc.repo != nil && !fc.repo.lastActivity.Before(cutoff) {
 				return
 			}
+			m, err := measureCache(fc.path, cutoff)
+			if err != nil || m.fresh {
+				return
+			}
+			modifiedAt := m.newest
+			if fc.repo != nil && fc.repo.lastActivity.After(modifiedAt) {
+				modifiedAt = fc.repo.lastActivity
+			}
 
 			item := types.CleanableItem{
 				Path:        fc.path,
-				Size:        size,
-				FileCount:   count,
+				Size:        m.size,
+				FileCount:   m.count,
 				Name:        filepath.Base(fc.path),
-				DisplayName: formatDisplayName(t.scanRoot, fc.path),
+				DisplayName: t.displayName(fc),
 				IsDirectory: true,
-				ModifiedAt:  newestFileTime(fc.path),
+				ModifiedAt:  modifiedAt,
+			}
+			if fc.protectedBy != nil {
+				item.Status = types.ItemStatusProtected
 			}
 
 			mu.Lock()
 			items = append(items, item)
-			totalSize += size
-			totalCount += count
+			totalSize += m.size
+			totalCount += m.count
 			mu.Unlock()
 		}(fc)
 	}
@@ -253,6 +365,71 @@ func newestFileTime(dir string) time.Time {
 	return newest
 }
 
+// cacheMeasure is what one walk over a cache tree learns about it.
+type cacheMeasure struct {
+	size   int64
+	count  int64
+	newest time.Time // newest file mtime
+	// fresh is set when a file modified at or after the cutoff was found;
+	// the walk stops there, so size and count are incomplete.
+	fresh bool
+}
+
+// measureCache walks dir once for its size, file count and newest file
+// mtime. File mtimes are read on every scan because they decide staleness:
+// a cache whose files are rewritten in place is in use even though no
+// directory in it changed. The walk stops at the first file modified at or
+// after cutoff, since such a cache is never offered.
+func measureCache(dir string, cutoff time.Time) (cacheMeasure, error) {
+	var m cacheMeasure
+	if _, err := os.Lstat(dir); err != nil {
+		return m, err
+	}
+	_ = filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		m.size += info.Size()
+		m.count++
+		if mt := info.ModTime(); mt.After(m.newest) {
+			m.newest = mt
+		}
+		if !m.newest.Before(cutoff) {
+			m.fresh = true
+			return fs.SkipAll
+		}
+		return nil
+	})
+	return m, nil
+}
+
+// displayName shows caches under $HOME relative to it and caches under an
+// extra root by their full path, so the two cannot be confused. Caches in a
+// git repository also show the git signals they passed, and protected caches
+// the file that protects them.
+func (t *ProjectCacheTarget) displayName(fc foundCache) string {
+	name := t.relativeName(fc.root, fc.path)
+	if fc.repo != nil {
+		name += " (" + fc.repo.gitLabel() + ")"
+	}
+	if fc.protectedBy != nil {
+		name += " (protected by " + t.relativeName(fc.root, fc.protectedBy.source) + ")"
+	}
+	return name
+}
+
+// relativeName shortens paths under $HOME to be relative to it.
+func (t *ProjectCacheTarget) relativeName(root, path string) string {
+	if root != t.scanRoot {
+		return path
+	}
+	return formatDisplayName(root, path)
+}
+
 func formatDisplayName(scanRoot, cachePath string) string {
 	rel, err := filepath.Rel(scanRoot, cachePath)
 	if err != nil {
@@ -0,0 +1,160 @@
+package target
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/2ykwang/mac-cleanup-go/internal/logger"
+	"github.com/2ykwang/mac-cleanup-go/internal/utils"
+)
+
+// gitRepo is what a project cache scan learned about the repository that
+// encloses one or more found caches.
+type gitRepo struct {
+	dir   string
+	dirty bool
+	// ignored holds the cache paths git would ignore.
+	ignored map[string]bool
+	// lastActivity is the later of the last commit and the last checkout.
+	lastActivity time.Time
+}
+
+// findRepoRoot returns the nearest directory from dir up to stop (inclusive)
+// that holds a .git entry, or "" when dir is not inside a repository.
+func findRepoRoot(dir, stop string) string {
+	for {
+		if _, err := os.Lstat(filepath.Join(dir, ".git")); err == nil {
+			return dir
+		}
+		if dir == stop {
+			return ""
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// filterGitCaches drops caches that git says are not build output and
+// attaches the enclosing repository to the rest. In a repository, a cache
+// is kept only when it is git-ignored and the working tree is clean, so
+// committed dist/ or build/ directories and work in progress are never
+// offered. Caches outside a repository, or when git is missing, are kept.
+func filterGitCaches(found []foundCache) []foundCache {
+	if len(found) == 0 || !utils.CommandExists("git") {
+		return found
+	}
+
+	byRepo := make(map[string][]string)
+	repoOf := make([]string, len(found))
+	for i, fc := range found {
+		repoOf[i] = findRepoRoot(filepath.Dir(fc.path), fc.root)
+		if repoOf[i] != "" {
+			byRepo[repoOf[i]] = append(byRepo[repoOf[i]], fc.path)
+		}
+	}
+
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		repos = make(map[string]*gitRepo, len(byRepo))
+		sem   = make(chan struct{}, utils.DefaultWorkers())
+	)
+	for dir, paths := range byRepo {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(dir string, paths []string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			repo, err := inspectRepo(dir, paths)
+			if err != nil {
+				logger.Debug("git inspection failed, using file times only", "repo", dir, "error", err)
+				return
+			}
+			mu.Lock()
+			repos[dir] = repo
+			mu.Unlock()
+		}(dir, paths)
+	}
+	wg.Wait()
+
+	kept := found[:0]
+	for i, fc := range found {
+		repo := repos[repoOf[i]]
+		switch {
+		case repo == nil:
+		case repo.dirty:
+			logger.Debug("project cache skipped: uncommitted changes", "path", fc.path, "repo", repo.dir)
+			continue
+		case !repo.ignored[fc.path]:
+			logger.Debug("project cache skipped: not git-ignored", "path", fc.path, "repo", repo.dir)
+			continue
+		default:
+			fc.repo = repo
+		}
+		kept = append(kept, fc)
+	}
+	return kept
+}
+
+// inspectRepo asks git whether the working tree in dir is clean, which of
+// paths it ignores and when the repository was last committed to or checked out.
+func inspectRepo(dir string, paths []string) (*gitRepo, error) {
+	repo := &gitRepo{dir: dir, ignored: make(map[string]bool, len(paths))}
+
+	status, err := execCommand("git", "-C", dir, "status", "--porcelain").Output()
+	if err != nil {
+		return nil, fmt.Errorf("git status: %w", err)
+	}
+	repo.dirty = strings.TrimSpace(string(status)) != ""
+
+	// Paths go through stdin, NUL-separated, so git neither quotes unusual
+	// names nor hits the argument limit in big repositories.
+	// check-ignore exits 1 when none of the paths are ignored.
+	cmd := execCommand("git", "-C", dir, "check-ignore", "-z", "--stdin")
+	cmd.Stdin = strings.NewReader(strings.Join(paths, "\x00") + "\x00")
+	ignored, err := cmd.Output()
+	var exitErr *exec.ExitError
+	if err != nil && !(errors.As(err, &exitErr) && exitErr.ExitCode() == 1) {
+		return nil, fmt.Errorf("git check-ignore: %w", err)
+	}
+	for _, path := range strings.Split(string(ignored), "\x00") {
+		if path != "" {
+			repo.ignored[filepath.Clean(path)] = true
+		}
+	}
+
+	// A repository without commits has no log; checkout time still applies.
+	if out, err := execCommand("git", "-C", dir, "log", "-1", "--format=%ct").Output(); err == nil {
+		if sec, err := strconv.ParseInt(strings.TrimSpace(string(out)), 10, 64); err == nil {
+			repo.lastActivity = time.Unix(sec, 0)
+		}
+	}
+	// The HEAD reflog is rewritten on every commit, checkout, pull and reset.
+	if out, err := execCommand("git", "-C", dir, "rev-parse", "--absolute-git-dir").Output(); err == nil {
+		gitDir := strings.TrimSpace(string(out))
+		if info, err := os.Stat(filepath.Join(gitDir, "logs", "HEAD")); err == nil && info.ModTime().After(repo.lastActivity) {
+			repo.lastActivity = info.ModTime()
+		}
+	}
+
+	return repo, nil
+}
+
+// gitLabel describes the git signals behind a cache, e.g. "git-ignored, repo active 3mo ago".
+func (r *gitRepo) gitLabel() string {
+	if r.lastActivity.IsZero() {
+		return "git-ignored"
+	}
+	return fmt.Sprintf("git-ignored, repo active %s ago", utils.FormatAge(r.lastActivity))
+}
@@ -0,0 +1,112 @@
+package target
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// initRepo creates a git repository with a committed .gitignore and package.json.
+func initRepo(t *testing.T, dir, gitignore string) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".gitignore"), []byte(gitignore), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "package.json"), []byte("{}"), 0o644))
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "."},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "init"},
+	} {
+		out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+}
+
+func TestFindRepoRoot(t *testing.T) {
+	root := t.TempDir()
+	repo := filepath.Join(root, "app")
+	require.NoError(t, os.MkdirAll(filepath.Join(repo, ".git"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(repo, "web", "src"), 0o755))
+
+	assert.Equal(t, repo, findRepoRoot(filepath.Join(repo, "web", "src"), root))
+	assert.Equal(t, repo, findRepoRoot(repo, root))
+	assert.Empty(t, findRepoRoot(root, root))
+}
+
+func TestScan_GitIgnoredCacheShowsRepoActivity(t *testing.T) {
+	root := t.TempDir()
+	repo := filepath.Join(root, "app")
+	initRepo(t, repo, "node_modules/\n")
+	require.NoError(t, os.MkdirAll(filepath.Join(repo, "node_modules", "lib"), 0o755))
+
+	result, err := newTestProjectCacheTarget(root).Scan()
+
+	require.NoError(t, err)
+	require.Len(t, result.Items, 1)
+	assert.Regexp(t, `^app/node_modules \(git-ignored, repo active .+ ago\)$`, result.Items[0].DisplayName)
+	assert.WithinDuration(t, time.Now(), result.Items[0].ModifiedAt, time.Minute)
+}
+
+func TestScan_GitIgnoredCacheWithNonASCIIName(t *testing.T) {
+	root := t.TempDir()
+	repo := filepath.Join(root, "프로젝트 ü")
+	initRepo(t, repo, "node_modules/\n")
+	require.NoError(t, os.MkdirAll(filepath.Join(repo, "node_modules", "lib"), 0o755))
+
+	result, err := newTestProjectCacheTarget(root).Scan()
+
+	require.NoError(t, err)
+	require.Len(t, result.Items, 1)
+	assert.Equal(t, filepath.Join(repo, "node_modules"), result.Items[0].Path)
+}
+
+func TestScan_SkipsCommittedBuildOutput(t *testing.T) {
+	root := t.TempDir()
+	repo := filepath.Join(root, "site")
+	require.NoError(t, os.MkdirAll(filepath.Join(repo, "dist"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "dist", "index.html"), []byte(""), 0o644))
+	initRepo(t, repo, "")
+
+	result, err := newTestProjectCacheTarget(root).Scan()
+
+	require.NoError(t, err)
+	assert.Empty(t, result.Items, "dist/ is tracked, not a cache")
+}
+
+func TestScan_SkipsRepoWithUncommittedChanges(t *testing.T) {
+	root := t.TempDir()
+	repo := filepath.Join(root, "app")
+	initRepo(t, repo, "node_modules/\n")
+	require.NoError(t, os.MkdirAll(filepath.Join(repo, "node_modules"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "package.json"), []byte(`{"name":"wip"}`), 0o644))
+
+	result, err := newTestProjectCacheTarget(root).Scan()
+
+	require.NoError(t, err)
+	assert.Empty(t, result.Items)
+}
+
+func TestScan_RecentRepoActivityKeepsCacheFresh(t *testing.T) {
+	root := t.TempDir()
+	repo := filepath.Join(root, "app")
+	initRepo(t, repo, "node_modules/\n")
+	file := filepath.Join(repo, "node_modules", "lib", "index.js")
+	require.NoError(t, os.MkdirAll(filepath.Dir(file), 0o755))
+	require.NoError(t, os.WriteFile(file, []byte(""), 0o644))
+	makeStale(t, file)
+
+	target := newTestProjectCacheTarget(root)
+	target.staleDays = 7
+	result, err := target.Scan()
+
+	require.NoError(t, err)
+	assert.Empty(t, result.Items, "the repo was committed to just now")
+}
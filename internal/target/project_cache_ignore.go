@@ -0,0 +1,148 @@
+package target
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+const (
+	// ignoreFileName marks a directory whose project caches are never cleaned.
+	// Each non-comment line names a cache directory to keep; an empty file keeps them all.
+	ignoreFileName = ".mac-cleanup-ignore"
+	// pyprojectFileName may declare the same with a [tool.mac-cleanup] table,
+	// optionally with keep = ["name", ...].
+	pyprojectFileName = "pyproject.toml"
+	pyprojectSection  = "[tool.mac-cleanup]"
+)
+
+var tomlStringRe = regexp.MustCompile(`"([^"]*)"|'([^']*)'`)
+
+// cacheProtection is a project's request to keep its caches.
+type cacheProtection struct {
+	source string   // file that declared it
+	keep   []string // cache directory names kept; empty keeps all
+}
+
+func (p *cacheProtection) covers(name string) bool {
+	return len(p.keep) == 0 || slices.Contains(p.keep, name)
+}
+
+// protectionFinder looks up protections in a directory and its ancestors,
+// reading each directory's files once per scan.
+type protectionFinder struct {
+	byDir map[string][]*cacheProtection
+}
+
+func newProtectionFinder() *protectionFinder {
+	return &protectionFinder{byDir: make(map[string][]*cacheProtection)}
+}
+
+// find returns the nearest protection from dir up to stop (inclusive) that
+// covers a cache directory called name, or nil.
+func (f *protectionFinder) find(dir, stop, name string) *cacheProtection {
+	for {
+		protections, ok := f.byDir[dir]
+		if !ok {
+			protections = readProtections(dir)
+			f.byDir[dir] = protections
+		}
+		for _, p := range protections {
+			if p.covers(name) {
+				return p
+			}
+		}
+		if dir == stop {
+			return nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil
+		}
+		dir = parent
+	}
+}
+
+// readProtections returns the protections declared directly in dir.
+func readProtections(dir string) []*cacheProtection {
+	var protections []*cacheProtection
+	if p := readIgnoreFile(filepath.Join(dir, ignoreFileName)); p != nil {
+		protections = append(protections, p)
+	}
+	if p := readPyprojectProtection(filepath.Join(dir, pyprojectFileName)); p != nil {
+		protections = append(protections, p)
+	}
+	return protections
+}
+
+func readIgnoreFile(path string) *cacheProtection {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	p := &cacheProtection{source: path}
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		p.keep = append(p.keep, strings.TrimSuffix(line, "/"))
+	}
+	return p
+}
+
+// readPyprojectProtection reads the [tool.mac-cleanup] table of a
+// pyproject.toml. Only its keep array is understood; it may span lines.
+func readPyprojectProtection(path string) *cacheProtection {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	var (
+		p       *cacheProtection
+		keep    strings.Builder
+		inKeep  bool
+		scanner = bufio.NewScanner(file)
+	)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if i := strings.Index(line, "#"); i >= 0 && !strings.ContainsAny(line[:i], `"'`) {
+			line = strings.TrimSpace(line[:i])
+		}
+		switch {
+		case inKeep:
+			keep.WriteString(line)
+			inKeep = !strings.Contains(line, "]")
+		case strings.HasPrefix(line, "["):
+			if p != nil {
+				return withKeep(p, keep.String())
+			}
+			if line == pyprojectSection {
+				p = &cacheProtection{source: path}
+			}
+		case p != nil && strings.HasPrefix(line, "keep"):
+			key, value, ok := strings.Cut(line, "=")
+			if !ok || strings.TrimSpace(key) != "keep" {
+				continue
+			}
+			keep.WriteString(value)
+			inKeep = !strings.Contains(value, "]")
+		}
+	}
+	if p == nil {
+		return nil
+	}
+	return withKeep(p, keep.String())
+}
+
+func withKeep(p *cacheProtection, array string) *cacheProtection {
+	for _, m := range tomlStringRe.FindAllStringSubmatch(array, -1) {
+		p.keep = append(p.keep, m[1]+m[2])
+	}
+	return p
+}
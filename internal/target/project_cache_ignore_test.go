@@ -0,0 +1,105 @@
+package target
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/2ykwang/mac-cleanup-go/internal/types"
+)
+
+// makeProject creates a project with a package.json, node_modules and dist.
+func makeProject(t *testing.T, dir string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "node_modules"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "dist"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "package.json"), []byte("{}"), 0o644))
+}
+
+func TestScan_IgnoreFileProtectsAllCaches(t *testing.T) {
+	root := t.TempDir()
+	makeProject(t, filepath.Join(root, "work", "app"))
+	makeProject(t, filepath.Join(root, "other"))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "work", ignoreFileName), nil, 0o644))
+
+	result, err := newTestProjectCacheTarget(root).Scan()
+
+	require.NoError(t, err)
+	require.Len(t, result.Items, 4)
+	byName := make(map[string]types.CleanableItem)
+	for _, item := range result.Items {
+		byName[item.DisplayName] = item
+	}
+	protected := byName["work/app/node_modules (protected by work/.mac-cleanup-ignore)"]
+	assert.Equal(t, types.ItemStatusProtected, protected.Status)
+	assert.Contains(t, byName, "work/app/dist (protected by work/.mac-cleanup-ignore)")
+	assert.True(t, byName["other/node_modules"].Status.Cleanable())
+	assert.Equal(t, byName["other/node_modules"].Size+byName["other/dist"].Size, result.TotalSize)
+}
+
+func TestScan_IgnoreFileKeepsListedNames(t *testing.T) {
+	root := t.TempDir()
+	project := filepath.Join(root, "app")
+	makeProject(t, project)
+	require.NoError(t, os.WriteFile(filepath.Join(project, ignoreFileName), []byte("# keep deps\nnode_modules/\n"), 0o644))
+
+	result, err := newTestProjectCacheTarget(root).Scan()
+
+	require.NoError(t, err)
+	require.Len(t, result.Items, 2)
+	assert.Equal(t, "app/dist", result.Items[0].DisplayName)
+	assert.True(t, result.Items[0].Status.Cleanable())
+	assert.Equal(t, types.ItemStatusProtected, result.Items[1].Status)
+}
+
+func TestReadPyprojectProtection(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    *cacheProtection
+	}{
+		{"no table", "[project]\nname = \"x\"\n", nil},
+		{"keeps all", "[project]\nname = \"x\"\n\n[tool.mac-cleanup]\n", &cacheProtection{}},
+		{"inline keep", "[tool.mac-cleanup]\nkeep = [\".venv\", 'node_modules'] # deps\n[tool.ruff]\n",
+			&cacheProtection{keep: []string{".venv", "node_modules"}}},
+		{"multi-line keep", "[tool.mac-cleanup]\nkeep = [\n  \".tox\",\n  \".venv\",\n]\n",
+			&cacheProtection{keep: []string{".tox", ".venv"}}},
+		{"keep in other table", "[tool.other]\nkeep = [\".venv\"]\n[tool.mac-cleanup]\n", &cacheProtection{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), pyprojectFileName)
+			require.NoError(t, os.WriteFile(path, []byte(tt.content), 0o644))
+
+			got := readPyprojectProtection(path)
+
+			if tt.want == nil {
+				assert.Nil(t, got)
+				return
+			}
+			require.NotNil(t, got)
+			assert.Equal(t, path, got.source)
+			assert.Equal(t, tt.want.keep, got.keep)
+		})
+	}
+}
+
+func TestScan_PyprojectProtectsVenv(t *testing.T) {
+	root := t.TempDir()
+	project := filepath.Join(root, "api")
+	require.NoError(t, os.MkdirAll(filepath.Join(project, ".venv"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(project, ".pytest_cache"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(project, pyprojectFileName),
+		[]byte("[tool.mac-cleanup]\nkeep = [\".venv\"]\n"), 0o644))
+
+	result, err := newTestProjectCacheTarget(root).Scan()
+
+	require.NoError(t, err)
+	require.Len(t, result.Items, 2)
+	assert.True(t, result.Items[0].Status.Cleanable(), result.Items[0].DisplayName)
+	assert.Equal(t, "api/.venv (protected by api/pyproject.toml)", result.Items[1].DisplayName)
+	assert.Equal(t, types.ItemStatusProtected, result.Items[1].Status)
+}
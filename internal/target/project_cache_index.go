@@ -0,0 +1,167 @@
+package target
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/2ykwang/mac-cleanup-go/internal/logger"
+)
+
+const (
+	// projectIndexFile is the project cache index, relative to $HOME.
+	projectIndexFile = ".config/mac-cleanup-go/project-cache-index.json"
+	// projectIndexVersion is bumped whenever the index layout changes;
+	// an index with another version is rebuilt.
+	projectIndexVersion = 1
+)
+
+// indexedDir records the subdirectories of a directory as of its mtime.
+// A directory's mtime changes whenever an entry is added, removed or
+// renamed, so an unchanged mtime means the recorded names are still valid.
+type indexedDir struct {
+	ModTime int64    `json:"mtime"`
+	Subdirs []string `json:"subdirs,omitempty"`
+}
+
+type projectIndexData struct {
+	Version int                   `json:"version"`
+	Dirs    map[string]indexedDir `json:"dirs"`
+}
+
+// projectCacheIndex lets a scan skip reading directories that have not
+// changed since the previous scan. Entries not visited by a scan are dropped
+// when it is saved. Found caches are not indexed: their file mtimes decide
+// staleness and are read on every scan.
+type projectCacheIndex struct {
+	path string // empty keeps the index in memory only
+
+	mu   sync.Mutex
+	prev projectIndexData
+	next projectIndexData
+}
+
+func newProjectIndexData() projectIndexData {
+	return projectIndexData{
+		Version: projectIndexVersion,
+		Dirs:    make(map[string]indexedDir),
+	}
+}
+
+// defaultProjectIndexPath returns where the index is kept, or "" without a home directory.
+func defaultProjectIndexPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, projectIndexFile)
+}
+
+// ResetProjectCacheIndex deletes the project cache index so the next scan
+// walks every directory again.
+func ResetProjectCacheIndex() error {
+	path := defaultProjectIndexPath()
+	if path == "" {
+		return nil
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	logger.Info("project cache index reset", "path", path)
+	return nil
+}
+
+// loadProjectCacheIndex reads the index at path. A missing, unreadable,
+// corrupt or outdated index is discarded and the scan starts from scratch.
+func loadProjectCacheIndex(path string) *projectCacheIndex {
+	idx := &projectCacheIndex{path: path, prev: newProjectIndexData(), next: newProjectIndexData()}
+	if path == "" {
+		return idx
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			logger.Warn("project cache index unreadable, rebuilding", "path", path, "error", err)
+		}
+		return idx
+	}
+	var prev projectIndexData
+	if err := json.Unmarshal(data, &prev); err != nil || prev.Version != projectIndexVersion || prev.Dirs == nil {
+		logger.Warn("project cache index invalid, rebuilding", "path", path, "version", prev.Version, "error", err)
+		if err := os.Remove(path); err != nil {
+			logger.Debug("project cache index not removed", "error", err)
+		}
+		return idx
+	}
+	idx.prev = prev
+	return idx
+}
+
+// subdirs returns the names of dir's subdirectories, reading the directory
+// only when it changed since the last scan. Symlinks are not followed.
+func (idx *projectCacheIndex) subdirs(dir string) []string {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil
+	}
+	mtime := info.ModTime().UnixNano()
+
+	idx.mu.Lock()
+	entry, ok := idx.prev.Dirs[dir]
+	idx.mu.Unlock()
+	if !ok || entry.ModTime != mtime {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return nil
+		}
+		entry = indexedDir{ModTime: mtime}
+		for _, e := range entries {
+			if e.IsDir() {
+				entry.Subdirs = append(entry.Subdirs, e.Name())
+			}
+		}
+	}
+
+	idx.mu.Lock()
+	idx.next.Dirs[dir] = entry
+	idx.mu.Unlock()
+	return entry.Subdirs
+}
+
+// save writes what this scan visited, replacing the previous index atomically.
+func (idx *projectCacheIndex) save() error {
+	if idx.path == "" {
+		return nil
+	}
+	idx.mu.Lock()
+	data, err := json.Marshal(idx.next)
+	idx.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return writeIndexFile(idx.path, data)
+}
+
+// writeIndexFile replaces the index at path atomically, so an interrupted
+// write never leaves a truncated index behind.
+func writeIndexFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,180 @@
+package target
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectCacheIndex_ReusesUnchangedDirectories(t *testing.T) {
+	root := t.TempDir()
+	indexPath := filepath.Join(t.TempDir(), "index.json")
+	project := filepath.Join(root, "app")
+	require.NoError(t, os.MkdirAll(filepath.Join(project, "node_modules"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(project, "package.json"), []byte("{}"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(project, "node_modules", "a.js"), []byte("12345"), 0o644))
+
+	target := newTestProjectCacheTarget(root)
+	target.indexPath = indexPath
+	first, err := target.Scan()
+	require.NoError(t, err)
+	require.Len(t, first.Items, 1)
+	assert.Equal(t, int64(5), first.Items[0].Size)
+
+	idx := loadProjectCacheIndex(indexPath)
+	assert.Contains(t, idx.prev.Dirs, root)
+	assert.Contains(t, idx.prev.Dirs, project)
+
+	// A directory hidden from the index is not seen while its parent is unchanged.
+	rootInfo, err := os.Stat(root)
+	require.NoError(t, err)
+	other := filepath.Join(root, "other")
+	require.NoError(t, os.MkdirAll(filepath.Join(other, "node_modules"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(other, "package.json"), []byte("{}"), 0o644))
+	require.NoError(t, os.Chtimes(root, rootInfo.ModTime(), rootInfo.ModTime()))
+
+	second, err := target.Scan()
+	require.NoError(t, err)
+	assert.Len(t, second.Items, 1)
+
+	// Once the parent changes, the new project is picked up.
+	later := rootInfo.ModTime().Add(time.Second)
+	require.NoError(t, os.Chtimes(root, later, later))
+
+	third, err := target.Scan()
+	require.NoError(t, err)
+	assert.Len(t, third.Items, 2)
+}
+
+func TestProjectCacheIndex_RemeasuresChangedCache(t *testing.T) {
+	root := t.TempDir()
+	indexPath := filepath.Join(t.TempDir(), "index.json")
+	project := filepath.Join(root, "app")
+	cache := filepath.Join(project, "node_modules")
+	require.NoError(t, os.MkdirAll(cache, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(project, "package.json"), []byte("{}"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(cache, "a.js"), []byte("12345"), 0o644))
+
+	target := newTestProjectCacheTarget(root)
+	target.indexPath = indexPath
+	_, err := target.Scan()
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(filepath.Join(cache, "b.js"), []byte("123"), 0o644))
+	later := time.Now().Add(time.Second)
+	require.NoError(t, os.Chtimes(cache, later, later))
+
+	result, err := target.Scan()
+	require.NoError(t, err)
+	require.Len(t, result.Items, 1)
+	assert.Equal(t, int64(8), result.Items[0].Size)
+	assert.Equal(t, int64(2), result.Items[0].FileCount)
+}
+
+func TestProjectCacheIndex_NestedRebuildKeepsCacheFresh(t *testing.T) {
+	root := t.TempDir()
+	indexPath := filepath.Join(t.TempDir(), "index.json")
+	project := filepath.Join(root, "app")
+	cache := filepath.Join(project, "node_modules")
+	nested := filepath.Join(cache, "pkg", "a.js")
+	require.NoError(t, os.MkdirAll(filepath.Dir(nested), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(project, "package.json"), []byte("{}"), 0o644))
+	require.NoError(t, os.WriteFile(nested, []byte("12345"), 0o644))
+	old := time.Now().AddDate(0, 0, -60)
+	require.NoError(t, os.Chtimes(nested, old, old))
+	require.NoError(t, os.Chtimes(cache, old, old))
+
+	target := newTestProjectCacheTarget(root)
+	target.indexPath = indexPath
+	target.staleDays = 30
+	first, err := target.Scan()
+	require.NoError(t, err)
+	require.Len(t, first.Items, 1, "untouched cache is stale")
+
+	// Rewriting a nested file in place changes no directory mtime.
+	pkg := filepath.Dir(nested)
+	pkgInfo, err := os.Stat(pkg)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(nested, []byte("1234567"), 0o644))
+	require.NoError(t, os.Chtimes(pkg, pkgInfo.ModTime(), pkgInfo.ModTime()))
+	require.NoError(t, os.Chtimes(cache, old, old))
+
+	second, err := target.Scan()
+	require.NoError(t, err)
+	assert.Empty(t, second.Items, "recently rebuilt cache is no longer stale")
+}
+
+func TestProjectCacheIndex_RemeasuresNestedChange(t *testing.T) {
+	root := t.TempDir()
+	indexPath := filepath.Join(t.TempDir(), "index.json")
+	project := filepath.Join(root, "app")
+	cache := filepath.Join(project, "node_modules")
+	pkg := filepath.Join(cache, "pkg")
+	require.NoError(t, os.MkdirAll(pkg, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(project, "package.json"), []byte("{}"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(pkg, "a.js"), []byte("12345"), 0o644))
+	old := time.Now().Add(-time.Hour)
+	require.NoError(t, os.Chtimes(pkg, old, old))
+	require.NoError(t, os.Chtimes(cache, old, old))
+
+	target := newTestProjectCacheTarget(root)
+	target.indexPath = indexPath
+	_, err := target.Scan()
+	require.NoError(t, err)
+
+	// A new file deep in the tree moves only its own directory's mtime.
+	require.NoError(t, os.WriteFile(filepath.Join(pkg, "b.js"), []byte("123"), 0o644))
+	require.NoError(t, os.Chtimes(cache, old, old))
+
+	result, err := target.Scan()
+	require.NoError(t, err)
+	require.Len(t, result.Items, 1)
+	assert.Equal(t, int64(8), result.Items[0].Size)
+	assert.Equal(t, int64(2), result.Items[0].FileCount)
+}
+
+func TestLoadProjectCacheIndex_RecoversFromCorruption(t *testing.T) {
+	for name, content := range map[string]string{
+		"invalid json":  "{not json",
+		"wrong version": `{"version":999,"dirs":{}}`,
+		"missing dirs":  `{"version":1}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "index.json")
+			require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+
+			idx := loadProjectCacheIndex(path)
+
+			assert.Empty(t, idx.prev.Dirs)
+			assert.NoFileExists(t, path, "corrupt index is removed")
+			require.NoError(t, idx.save())
+			assert.FileExists(t, path)
+		})
+	}
+}
+
+func TestProjectCacheIndex_SaveDropsVanishedEntries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "index.json")
+	idx := loadProjectCacheIndex(path)
+	idx.prev.Dirs["/gone"] = indexedDir{ModTime: 1}
+
+	require.NoError(t, idx.save())
+
+	assert.NotContains(t, loadProjectCacheIndex(path).prev.Dirs, "/gone")
+}
+
+func TestResetProjectCacheIndex(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	path := filepath.Join(home, projectIndexFile)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte("{}"), 0o644))
+
+	require.NoError(t, ResetProjectCacheIndex())
+	assert.NoFileExists(t, path)
+	assert.NoError(t, ResetProjectCacheIndex(), "missing index is fine")
+}
@@ -3,6 +3,7 @@ package target
 import (
 	"os"
 	"path/filepath"
+	"slices"
 	"testing"
 	"time"
 
@@ -221,6 +222,101 @@ func TestScan_FiltersStaleCachesOnly(t *testing.T) {
 	assert.Contains(t, result.Items[0].DisplayName, "stale")
 }
 
+// --- Scan: Configured options ---
+
+func TestNewProjectCacheTarget_AppliesOptions(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	target := NewProjectCacheTarget(types.Category{ProjectCache: types.ProjectCacheOptions{
+		Roots:       []string{"/Volumes/Work", "~/src"},
+		Patterns:    []types.CachePattern{{Dir: "_build", Markers: []string{"mix.exs"}}},
+		ExcludeDirs: []string{"third_party", "~/src/vendored"},
+		MaxDepth:    12,
+		StaleDays:   30,
+	}})
+
+	assert.Equal(t, []string{"/Volumes/Work", filepath.Join(home, "src")}, target.extraRoots)
+	assert.Len(t, target.patterns, len(defaultPatterns)+1)
+	assert.Equal(t, cachePattern{DirName: "_build", MarkerFiles: []string{"mix.exs"}}, target.patterns[len(target.patterns)-1])
+	assert.Contains(t, target.excludeNames, "third_party")
+	assert.Equal(t, []string{filepath.Join(home, "src", "vendored")}, target.excludePaths)
+	assert.Equal(t, 12, target.maxDepth)
+	assert.Equal(t, 30, target.staleDays)
+
+	defaults := NewProjectCacheTarget(types.Category{})
+	assert.Equal(t, maxScanDepth, defaults.maxDepth)
+	assert.Equal(t, defaultStaleDays, defaults.staleDays)
+}
+
+func TestScan_ExtraRootAndPattern(t *testing.T) {
+	home := t.TempDir()
+	work := t.TempDir()
+	project := filepath.Join(work, "api")
+	require.NoError(t, os.MkdirAll(filepath.Join(project, "_build", "dev"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(project, "mix.exs"), []byte(""), 0o644))
+
+	target := newTestProjectCacheTarget(home)
+	target.extraRoots = []string{work, filepath.Join(work, "missing")}
+	target.patterns = append(slices.Clone(defaultPatterns), cachePattern{DirName: "_build", MarkerFiles: []string{"mix.exs"}})
+
+	result, err := target.Scan()
+
+	require.NoError(t, err)
+	require.Len(t, result.Items, 1)
+	assert.Equal(t, filepath.Join(project, "_build"), result.Items[0].DisplayName)
+}
+
+func TestScan_OverlappingRootsReportOnce(t *testing.T) {
+	root := t.TempDir()
+	project := filepath.Join(root, "src", "app")
+	require.NoError(t, os.MkdirAll(filepath.Join(project, "node_modules"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(project, "package.json"), []byte("{}"), 0o644))
+
+	target := newTestProjectCacheTarget(root)
+	target.extraRoots = []string{filepath.Join(root, "src")}
+
+	result, err := target.Scan()
+
+	require.NoError(t, err)
+	require.Len(t, result.Items, 1)
+	assert.Equal(t, "src/app/node_modules", result.Items[0].DisplayName)
+}
+
+func TestScan_ExcludeDirs(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"a/third_party/lib", "vendored/app", "app"} {
+		project := filepath.Join(root, dir)
+		require.NoError(t, os.MkdirAll(filepath.Join(project, "node_modules"), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(project, "package.json"), []byte("{}"), 0o644))
+	}
+
+	target := newTestProjectCacheTarget(root)
+	target.excludeNames = map[string]struct{}{"third_party": {}}
+	target.excludePaths = []string{filepath.Join(root, "vendored")}
+
+	result, err := target.Scan()
+
+	require.NoError(t, err)
+	require.Len(t, result.Items, 1)
+	assert.Equal(t, "app/node_modules", result.Items[0].DisplayName)
+}
+
+func TestScan_ConfiguredMaxDepth(t *testing.T) {
+	root := t.TempDir()
+	project := filepath.Join(root, "a", "b", "project")
+	require.NoError(t, os.MkdirAll(filepath.Join(project, "node_modules"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(project, "package.json"), []byte("{}"), 0o644))
+
+	target := newTestProjectCacheTarget(root)
+	target.maxDepth = 3
+
+	result, err := target.Scan()
+
+	require.NoError(t, err)
+	assert.Empty(t, result.Items, "node_modules sits at depth 4")
+}
+
 // --- Scan: Edge cases ---
 
 func TestScan_EmptyScanRoot_ReturnsEmpty(t *testing.T) {
@@ -327,3 +423,25 @@ func TestClean_EmptyItems_ReturnsZero(t *testing.T) {
 	assert.Equal(t, 0, result.CleanedItems)
 	assert.Empty(t, result.Errors)
 }
+
+func TestMeasureCache_StopsAtRecentFile(t *testing.T) {
+	dir := t.TempDir()
+	old := time.Now().AddDate(0, 0, -30)
+	for _, name := range []string{"a", "b"} {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte("12345"), 0o644))
+		require.NoError(t, os.Chtimes(path, old, old))
+	}
+
+	stale, err := measureCache(dir, time.Now().AddDate(0, 0, -7))
+	require.NoError(t, err)
+	assert.False(t, stale.fresh)
+	assert.Equal(t, int64(10), stale.size)
+	assert.Equal(t, int64(2), stale.count)
+	assert.WithinDuration(t, old, stale.newest, time.Second)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a"), []byte("1"), 0o644))
+	fresh, err := measureCache(dir, time.Now().AddDate(0, 0, -7))
+	require.NoError(t, err)
+	assert.True(t, fresh.fresh)
+}
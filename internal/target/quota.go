@@ -0,0 +1,85 @@
+package target
+
+import (
+	"io/fs"
+	"path/filepath"
+	"sort"
+
+	"github.com/2ykwang/mac-cleanup-go/internal/types"
+	"github.com/2ykwang/mac-cleanup-go/internal/utils"
+)
+
+// scanFiles lists every file under paths as its own item, with ModifiedAt set
+// to the file's last use. Nested paths owned by other categories are skipped.
+func (s *PathTarget) scanFiles(paths []string) []types.CleanableItem {
+	var items []types.CleanableItem
+	for _, root := range paths {
+		holes := make(map[string]bool)
+		for _, hole := range s.ownership.holesIn(s.category.ID, root) {
+			holes[hole] = true
+		}
+
+		_ = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			if holes[path] || (path != root && s.isExcludedByPattern(path)) {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if d.IsDir() {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return nil
+			}
+			items = append(items, types.CleanableItem{
+				Path:       path,
+				Size:       info.Size(),
+				FileCount:  1,
+				Name:       filepath.Base(path),
+				ModifiedAt: utils.LastUsedTime(path, info),
+			})
+			return nil
+		})
+	}
+	return items
+}
+
+// applyQuota offers the least recently used files until the rest fits within
+// the category quota. Files that stay are marked and left out of the totals.
+// Files outside the category's age and size limits always stay, so older
+// files are offered in their place.
+func (s *PathTarget) applyQuota(items []types.CleanableItem) ([]types.CleanableItem, int64, int64) {
+	sort.Slice(items, func(i, j int) bool {
+		if !items[i].ModifiedAt.Equal(items[j].ModifiedAt) {
+			return items[i].ModifiedAt.Before(items[j].ModifiedAt)
+		}
+		return items[i].Path < items[j].Path
+	})
+
+	var total int64
+	for _, item := range items {
+		total += item.Size
+	}
+
+	quota := int64(s.category.Quota)
+	passes := s.thresholdFilter()
+	var offeredSize, offeredCount int64
+	for i := range items {
+		if !items[i].Status.Cleanable() {
+			continue // stays, and already counted in total
+		}
+		if total <= quota || (passes != nil && !passes(items[i])) {
+			items[i].Status = types.ItemStatusWithinQuota
+			continue
+		}
+		total -= items[i].Size
+		offeredSize += items[i].Size
+		offeredCount++
+	}
+	return items, offeredSize, offeredCount
+}
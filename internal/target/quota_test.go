@@ -0,0 +1,114 @@
+package target
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/2ykwang/mac-cleanup-go/internal/types"
+)
+
+func TestScan_Quota_OffersLeastRecentlyUsedFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	cache := filepath.Join(tmpDir, "cache")
+	now := time.Now()
+
+	files := []struct {
+		name string
+		used time.Time
+	}{
+		{"a/oldest", now.Add(-72 * time.Hour)},
+		{"b/older", now.Add(-48 * time.Hour)},
+		{"a/recent", now.Add(-time.Hour)},
+		{"newest", now},
+	}
+	for _, f := range files {
+		path := filepath.Join(cache, f.name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, make([]byte, 100), 0o644))
+		require.NoError(t, os.Chtimes(path, f.used, f.used))
+	}
+
+	cat := types.Category{
+		ID:    "pip",
+		Paths: []string{cache},
+		Quota: 250,
+	}
+
+	result, err := NewPathTarget(cat).Scan()
+	require.NoError(t, err)
+
+	require.Len(t, result.Items, 4, "files that stay are listed too")
+	assert.Equal(t, "oldest", result.Items[0].Name)
+	assert.Equal(t, types.ItemStatusAvailable, result.Items[0].Status)
+	assert.Equal(t, "older", result.Items[1].Name)
+	assert.Equal(t, types.ItemStatusAvailable, result.Items[1].Status)
+	assert.Equal(t, types.ItemStatusWithinQuota, result.Items[2].Status)
+	assert.Equal(t, types.ItemStatusWithinQuota, result.Items[3].Status)
+	assert.Equal(t, int64(200), result.TotalSize)
+	assert.Equal(t, int64(2), result.TotalFileCount)
+}
+
+func TestScan_Quota_SkipsFilesOutsideThresholds(t *testing.T) {
+	tmpDir := t.TempDir()
+	now := time.Now()
+
+	files := []struct {
+		name string
+		size int
+		used time.Time
+	}{
+		{"small", 10, now.Add(-96 * time.Hour)},
+		{"oldest", 100, now.Add(-72 * time.Hour)},
+		{"older", 100, now.Add(-48 * time.Hour)},
+		{"newest", 100, now},
+	}
+	for _, f := range files {
+		path := filepath.Join(tmpDir, f.name)
+		require.NoError(t, os.WriteFile(path, make([]byte, f.size), 0o644))
+		require.NoError(t, os.Chtimes(path, f.used, f.used))
+	}
+
+	cat := types.Category{
+		ID:      "pip",
+		Paths:   []string{tmpDir},
+		Quota:   150,
+		MinSize: 50,
+	}
+
+	result, err := NewPathTarget(cat).Scan()
+	require.NoError(t, err)
+
+	require.Len(t, result.Items, 4)
+	statuses := make(map[string]types.ItemStatus)
+	for _, item := range result.Items {
+		statuses[item.Name] = item.Status
+	}
+	assert.Equal(t, types.ItemStatusWithinQuota, statuses["small"], "too small to offer")
+	assert.Equal(t, types.ItemStatusAvailable, statuses["oldest"])
+	assert.Equal(t, types.ItemStatusAvailable, statuses["older"], "offered so the rest fits the quota")
+	assert.Equal(t, types.ItemStatusWithinQuota, statuses["newest"])
+	assert.Equal(t, int64(200), result.TotalSize)
+}
+
+func TestScan_Quota_UnderQuotaOffersNothing(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "data"), make([]byte, 100), 0o644))
+
+	cat := types.Category{
+		ID:    "pip",
+		Paths: []string{tmpDir},
+		Quota: 1 << 20,
+	}
+
+	result, err := NewPathTarget(cat).Scan()
+	require.NoError(t, err)
+
+	require.Len(t, result.Items, 1)
+	assert.Equal(t, types.ItemStatusWithinQuota, result.Items[0].Status)
+	assert.Zero(t, result.TotalSize)
+}
@@ -13,11 +13,14 @@ type BuiltinFactory func(cat types.Category, categories []types.Category) Target
 // builtinIDs is the static set of known builtin target IDs.
 // Used for config validation independent of factory registration.
 var builtinIDs = map[string]struct{}{
-	"homebrew":      {},
-	"docker":        {},
-	"old-downloads": {},
-	"system-cache":  {},
-	"project-cache": {},
+	"homebrew":            {},
+	"homebrew-autoremove": {},
+	"docker":              {},
+	"old-downloads":       {},
+	"system-cache":        {},
+	"project-cache":       {},
+	"duplicates":          {},
+	"large-files":         {},
 }
 
 var builtinFactories = map[string]BuiltinFactory{}
@@ -38,11 +41,18 @@ func registerAllBuiltins() {
 	RegisterBuiltin("homebrew", func(cat types.Category, _ []types.Category) Target {
 		return NewBrewTarget(cat)
 	})
+	RegisterBuiltin("homebrew-autoremove", func(cat types.Category, _ []types.Category) Target {
+		return NewBrewAutoremoveTarget(cat)
+	})
 	RegisterBuiltin("docker", func(cat types.Category, _ []types.Category) Target {
 		return NewDockerTarget(cat)
 	})
 	RegisterBuiltin("old-downloads", func(cat types.Category, _ []types.Category) Target {
-		return NewOldDownloadTarget(cat, defaultDaysOld)
+		daysOld := defaultDaysOld
+		if cat.MinAgeDays > 0 {
+			daysOld = cat.MinAgeDays
+		}
+		return NewOldDownloadTarget(cat, daysOld)
 	})
 	RegisterBuiltin("system-cache", func(cat types.Category, categories []types.Category) Target {
 		return NewSystemCacheTarget(cat, categories)
@@ -50,17 +60,26 @@ func registerAllBuiltins() {
 	RegisterBuiltin("project-cache", func(cat types.Category, _ []types.Category) Target {
 		return NewProjectCacheTarget(cat)
 	})
+	RegisterBuiltin("duplicates", func(cat types.Category, _ []types.Category) Target {
+		return NewDuplicatesTarget(cat)
+	})
+	RegisterBuiltin("large-files", func(cat types.Category, categories []types.Category) Target {
+		return NewLargeFilesTarget(cat, categories)
+	})
 }
 
 func DefaultRegistry(cfg *types.Config) (*Registry, error) {
 	registerAllBuiltins()
 	r := NewRegistry()
+	cfg = ExpandConfig(cfg)
 
 	builtinCount := 0
 	pathCount := 0
+	commandCount := 0
+	var matchers []pathMatcher
 	for _, cat := range cfg.Categories {
 		var s Target
-		if factory, ok := builtinFactories[cat.ID]; ok {
+		if factory, ok := builtinFactories[builtinID(cat.ID)]; ok {
 			// Use registered factory regardless of method type
 			s = factory(cat, cfg.Categories)
 			builtinCount++
@@ -68,17 +87,31 @@ func DefaultRegistry(cfg *types.Config) (*Registry, error) {
 			// method: builtin but no factory registered
 			logger.Warn("unknown builtin target", "id", cat.ID)
 			return nil, fmt.Errorf("unknown builtin target id: %s", cat.ID)
+		} else if cat.Method == types.MethodCommand {
+			s = NewCommandTarget(cat)
+			commandCount++
 		} else {
 			s = NewPathTarget(cat)
 			pathCount++
 		}
 		r.Register(s)
+		if m, ok := s.(pathMatcher); ok {
+			matchers = append(matchers, m)
+		}
+	}
+
+	r.ownership = newOwnership(matchers)
+	for _, m := range matchers {
+		if aware, ok := m.(ownershipAware); ok {
+			aware.setOwnership(r.ownership)
+		}
 	}
 
 	logger.Info("registry initialized",
 		"total", len(cfg.Categories),
 		"builtin", builtinCount,
-		"path", pathCount)
+		"path", pathCount,
+		"command", commandCount)
 
 	return r, nil
 }
@@ -0,0 +1,117 @@
+package target
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/2ykwang/mac-cleanup-go/internal/logger"
+	"github.com/2ykwang/mac-cleanup-go/internal/types"
+	"github.com/2ykwang/mac-cleanup-go/internal/utils"
+)
+
+// pathCmdTimeout bounds how long a path_cmd may take to print its directory.
+const pathCmdTimeout = 15 * time.Second
+
+var (
+	pathCmdMu    sync.Mutex
+	pathCmdCache = make(map[string]string)
+)
+
+// ResolvePaths returns the paths cat declares, as its target scans them.
+func ResolvePaths(cat types.Category) []string {
+	return resolvePaths(cat)
+}
+
+// resolvePaths returns the category's path patterns with variables expanded.
+// When PathCmd is set, relative patterns are joined to the directory it
+// prints; with no patterns the directory itself is used. Relative patterns are
+// dropped if the command fails.
+func resolvePaths(cat types.Category) []string {
+	if len(cat.PathCmd) == 0 {
+		return expandPathVars(cat.Paths)
+	}
+
+	root := resolvePathCmd(cat.PathCmd)
+	if len(cat.Paths) == 0 {
+		if root == "" {
+			return nil
+		}
+		return []string{root}
+	}
+
+	paths := make([]string, 0, len(cat.Paths))
+	for _, p := range cat.Paths {
+		if filepath.IsAbs(p) || strings.HasPrefix(p, "~") || strings.HasPrefix(p, "$") {
+			paths = append(paths, p)
+			continue
+		}
+		if root != "" {
+			paths = append(paths, filepath.Join(root, p))
+		}
+	}
+	return expandPathVars(paths)
+}
+
+// expandPathVars expands variables in patterns and drops any pattern that is
+// not rooted afterwards, such as "${GOCACHE:-...}/*" with GOCACHE=off, so a
+// pattern never resolves against the working directory.
+func expandPathVars(patterns []string) []string {
+	expanded := make([]string, 0, len(patterns))
+	for _, p := range patterns {
+		e := utils.ExpandVars(p)
+		if !filepath.IsAbs(e) && !strings.HasPrefix(e, "~/") {
+			logger.Warn("path pattern skipped: not absolute after expansion", "pattern", p, "expanded", e)
+			continue
+		}
+		expanded = append(expanded, e)
+	}
+	return expanded
+}
+
+// resolvePathCmd runs argv and returns the absolute directory it prints.
+// Results, including failures, are cached so each command runs once per run.
+func resolvePathCmd(argv []string) string {
+	key := strings.Join(argv, "\x00")
+
+	pathCmdMu.Lock()
+	defer pathCmdMu.Unlock()
+
+	if root, ok := pathCmdCache[key]; ok {
+		return root
+	}
+
+	root := runPathCmd(argv)
+	pathCmdCache[key] = root
+	return root
+}
+
+func runPathCmd(argv []string) string {
+	if len(argv) == 0 || !utils.CommandExists(argv[0]) {
+		return ""
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), pathCmdTimeout)
+	defer cancel()
+
+	output, err := execCommandContext(ctx, argv[0], argv[1:]...).Output()
+	if err != nil {
+		logger.Warn("path_cmd failed", "command", strings.Join(argv, " "), "error", err)
+		return ""
+	}
+
+	root := strings.TrimSpace(string(output))
+	if i := strings.IndexByte(root, '\n'); i >= 0 {
+		root = strings.TrimSpace(root[:i])
+	}
+	root = utils.ExpandPath(root)
+	if !filepath.IsAbs(root) {
+		logger.Warn("path_cmd returned a non-absolute path", "command", strings.Join(argv, " "), "output", root)
+		return ""
+	}
+
+	logger.Debug("path_cmd resolved", "command", strings.Join(argv, " "), "path", root)
+	return filepath.Clean(root)
+}
@@ -0,0 +1,115 @@
+package target
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/2ykwang/mac-cleanup-go/internal/types"
+	"github.com/2ykwang/mac-cleanup-go/internal/utils"
+)
+
+func resetPathCmdCache(t *testing.T) {
+	t.Helper()
+	pathCmdMu.Lock()
+	pathCmdCache = make(map[string]string)
+	pathCmdMu.Unlock()
+	t.Cleanup(func() {
+		pathCmdMu.Lock()
+		pathCmdCache = make(map[string]string)
+		pathCmdMu.Unlock()
+	})
+}
+
+func TestResolvePaths_WithoutPathCmd_ReturnsPaths(t *testing.T) {
+	cat := types.Category{Paths: []string{"~/a/*", "/b/*"}}
+
+	assert.Equal(t, []string{"~/a/*", "/b/*"}, resolvePaths(cat))
+}
+
+func TestResolvePaths_JoinsRelativePathsToCommandOutput(t *testing.T) {
+	resetPathCmdCache(t)
+	root := t.TempDir()
+	cat := types.Category{
+		PathCmd: []string{"echo", root},
+		Paths:   []string{"_cacache/*", "/abs/*"},
+	}
+
+	assert.Equal(t, []string{filepath.Join(root, "_cacache/*"), "/abs/*"}, resolvePaths(cat))
+}
+
+func TestResolvePaths_NoPaths_UsesCommandOutput(t *testing.T) {
+	resetPathCmdCache(t)
+	root := t.TempDir()
+	cat := types.Category{PathCmd: []string{"echo", root}}
+
+	assert.Equal(t, []string{root}, resolvePaths(cat))
+}
+
+func TestResolvePaths_CommandFailure_DropsRelativePaths(t *testing.T) {
+	resetPathCmdCache(t)
+	cat := types.Category{
+		PathCmd: []string{"false"},
+		Paths:   []string{"sub/*", "~/keep/*"},
+	}
+
+	assert.Equal(t, []string{"~/keep/*"}, resolvePaths(cat))
+}
+
+func TestResolvePaths_RejectsRelativeOutput(t *testing.T) {
+	resetPathCmdCache(t)
+	cat := types.Category{PathCmd: []string{"echo", "relative/dir"}}
+
+	assert.Empty(t, resolvePaths(cat))
+}
+
+func TestResolvePathCmd_RunsOncePerRun(t *testing.T) {
+	resetPathCmdCache(t)
+	dir := t.TempDir()
+	counter := filepath.Join(dir, "count")
+	argv := []string{"sh", "-c", "echo x >> " + counter + "; echo " + dir}
+
+	assert.Equal(t, dir, resolvePathCmd(argv))
+	assert.Equal(t, dir, resolvePathCmd(argv))
+
+	data, err := os.ReadFile(counter)
+	require.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(string(data), "x"))
+}
+
+func TestPathTarget_Scan_UsesPathCmdRoot(t *testing.T) {
+	resetPathCmdCache(t)
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "cache", "entry"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "cache", "entry", "blob"), []byte("data"), 0o644))
+
+	cat := types.Category{
+		ID:      "npm",
+		PathCmd: []string{"echo", root},
+		Paths:   []string{"cache/*"},
+	}
+
+	result, err := NewPathTarget(cat).Scan()
+
+	require.NoError(t, err)
+	require.Len(t, result.Items, 1)
+	assert.Equal(t, filepath.Join(root, "cache", "entry"), result.Items[0].Path)
+}
+
+func TestResolvePaths_ExpandsVariablesAndDropsRelativeResults(t *testing.T) {
+	utils.ResetEnvCache()
+	t.Cleanup(utils.ResetEnvCache)
+	t.Setenv("MCG_TEST_GOCACHE", "off")
+	t.Setenv("MCG_TEST_PIP", "/custom/pip")
+	cat := types.Category{Paths: []string{
+		"${MCG_TEST_GOCACHE:-~/Library/Caches/go-build}/*",
+		"${MCG_TEST_PIP:-~/Library/Caches/pip}/*",
+		"$MCG_TEST_UNSET_VAR/*",
+	}}
+
+	assert.Equal(t, []string{"/custom/pip/*"}, resolvePaths(cat))
+}
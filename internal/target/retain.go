@@ -0,0 +1,79 @@
+package target
+
+import (
+	"path/filepath"
+	"regexp"
+	"sort"
+
+	"github.com/2ykwang/mac-cleanup-go/internal/logger"
+	"github.com/2ykwang/mac-cleanup-go/internal/types"
+	"github.com/2ykwang/mac-cleanup-go/internal/utils"
+)
+
+// applyRetention marks the newest Retain.Keep versions in each group as
+// retained and leaves them out of the totals. Retained items stay in the
+// result so previews can show what is kept.
+func (s *PathTarget) applyRetention(items []types.CleanableItem, totalSize, totalCount int64) ([]types.CleanableItem, int64, int64) {
+	retain := s.category.Retain
+	if !retain.Enabled() || len(items) == 0 {
+		return items, totalSize, totalCount
+	}
+
+	re, err := regexp.Compile(retain.Pattern)
+	if err != nil {
+		logger.Warn("invalid retain pattern", "id", s.category.ID, "pattern", retain.Pattern, "error", err)
+		return items, totalSize, totalCount
+	}
+
+	type versioned struct {
+		index   int
+		version string
+	}
+	// Versions are compared among siblings, so the same release under
+	// Caches and Logs is kept in both places.
+	groups := make(map[[2]string][]versioned)
+	for i, item := range items {
+		name, version, ok := extractVersion(re, item.Name)
+		if !ok {
+			continue
+		}
+		key := [2]string{filepath.Dir(item.Path), name}
+		groups[key] = append(groups[key], versioned{index: i, version: version})
+	}
+
+	for _, members := range groups {
+		sort.SliceStable(members, func(i, j int) bool {
+			return utils.CompareVersions(members[i].version, members[j].version) > 0
+		})
+		for _, m := range members[:min(retain.Keep, len(members))] {
+			item := &items[m.index]
+			if item.Status != types.ItemStatusAvailable {
+				continue
+			}
+			item.Status = types.ItemStatusRetained
+			totalSize -= item.Size
+			totalCount -= item.FileCount
+		}
+	}
+	return items, totalSize, totalCount
+}
+
+// extractVersion applies re to name and returns the group key and version.
+func extractVersion(re *regexp.Regexp, name string) (group, version string, ok bool) {
+	match := re.FindStringSubmatch(name)
+	if match == nil {
+		return "", "", false
+	}
+
+	version = match[0]
+	if len(match) > 1 {
+		version = match[1]
+	}
+	if i := re.SubexpIndex("version"); i >= 0 {
+		version = match[i]
+	}
+	if i := re.SubexpIndex("name"); i >= 0 {
+		group = match[i]
+	}
+	return group, version, true
+}
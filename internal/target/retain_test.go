@@ -0,0 +1,67 @@
+package target
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/2ykwang/mac-cleanup-go/internal/types"
+)
+
+func TestScan_Retain_KeepsNewestVersions(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, name := range []string{"gradle-8.10-bin", "gradle-8.9-bin", "gradle-8.5-bin", "gradle-8.10-rc-1-bin", "gradle-7.6-all", "notes"} {
+		os.MkdirAll(filepath.Join(tmpDir, name), 0o755)
+		os.WriteFile(filepath.Join(tmpDir, name, "data"), []byte("data"), 0o644)
+	}
+
+	cat := types.Category{
+		ID:     "gradle-wrapper",
+		Paths:  []string{filepath.Join(tmpDir, "*")},
+		Retain: types.Retention{Pattern: `^gradle-(?P<version>.+)-(?P<name>bin|all)$`, Keep: 2},
+	}
+
+	result, err := NewPathTarget(cat).Scan()
+	require.NoError(t, err)
+	statuses := make(map[string]types.ItemStatus, len(result.Items))
+	for _, item := range result.Items {
+		statuses[item.Name] = item.Status
+	}
+
+	assert.Equal(t, types.ItemStatusRetained, statuses["gradle-8.10-bin"])
+	assert.Equal(t, types.ItemStatusRetained, statuses["gradle-8.10-rc-1-bin"], "rc of 8.10 is newer than 8.9")
+	assert.Equal(t, types.ItemStatusAvailable, statuses["gradle-8.9-bin"])
+	assert.Equal(t, types.ItemStatusAvailable, statuses["gradle-8.5-bin"])
+	assert.Equal(t, types.ItemStatusRetained, statuses["gradle-7.6-all"], "each distribution type keeps its own versions")
+	assert.Equal(t, types.ItemStatusAvailable, statuses["notes"], "items without a version are unaffected")
+	assert.Len(t, result.Items, 6, "retained items stay visible")
+	assert.Equal(t, int64(12), result.TotalSize, "retained items are not reclaimable")
+}
+
+func TestScan_Retain_ComparesSiblingsOnly(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, dir := range []string{"Caches/IntelliJIdea2024.1", "Caches/IntelliJIdea2023.3", "Caches/GoLand2024.1", "Logs/IntelliJIdea2024.1"} {
+		os.MkdirAll(filepath.Join(tmpDir, dir), 0o755)
+		os.WriteFile(filepath.Join(tmpDir, dir, "data"), []byte("data"), 0o644)
+	}
+
+	cat := types.Category{
+		ID:     "jetbrains",
+		Paths:  []string{filepath.Join(tmpDir, "Caches", "*"), filepath.Join(tmpDir, "Logs", "*")},
+		Retain: types.Retention{Pattern: `^(?P<name>[A-Za-z]+)(?P<version>\d{4}\.\d+)$`, Keep: 1},
+	}
+
+	result, err := NewPathTarget(cat).Scan()
+	require.NoError(t, err)
+
+	var available []string
+	for _, item := range result.Items {
+		if item.Status == types.ItemStatusAvailable {
+			available = append(available, item.Path)
+		}
+	}
+	assert.Equal(t, []string{filepath.Join(tmpDir, "Caches", "IntelliJIdea2023.3")}, available)
+}
@@ -14,7 +14,8 @@ type BuiltinCleaner interface {
 }
 
 type Registry struct {
-	targets map[string]Target
+	targets   map[string]Target
+	ownership *Ownership
 }
 
 func NewRegistry() *Registry {
@@ -47,3 +48,9 @@ func (r *Registry) Available() []Target {
 	}
 	return result
 }
+
+// Overlaps lists categories whose expanded paths overlap. Each overlapping path
+// is counted only by its owner. Empty for registries built without DefaultRegistry.
+func (r *Registry) Overlaps() []Overlap {
+	return r.ownership.Overlaps()
+}
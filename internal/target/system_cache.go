@@ -1,7 +1,9 @@
 package target
 
 import (
+	"path/filepath"
 	"strings"
+	"sync"
 
 	"github.com/2ykwang/mac-cleanup-go/internal/logger"
 	"github.com/2ykwang/mac-cleanup-go/internal/types"
@@ -12,33 +14,43 @@ import (
 type SystemCacheTarget struct {
 	*PathTarget
 	excludePaths []string
+
+	// commandCategories use path_cmd; their excludes are resolved on first scan
+	// so building the registry never runs external tools.
+	commandCategories []types.Category
+	commandOnce       sync.Once
 }
 
 var getLockedPaths = utils.GetLockedPaths
 
 func NewSystemCacheTarget(cat types.Category, allCategories []types.Category) *SystemCacheTarget {
-	var excludes []string
+	s := &SystemCacheTarget{PathTarget: NewPathTarget(cat)}
 	for _, other := range allCategories {
 		if other.ID == cat.ID {
 			continue
 		}
-		for _, p := range other.Paths {
-			expanded := utils.ExpandPath(p)
-			// Remove trailing patterns for prefix matching
-			expanded = strings.TrimSuffix(expanded, "/**")
-			expanded = strings.TrimSuffix(expanded, "/*")
-			expanded = strings.TrimSuffix(expanded, "*")
-			// Ensure path ends with / for proper prefix matching
-			if !strings.HasSuffix(expanded, "/") {
-				expanded = expanded + "/"
-			}
-			excludes = append(excludes, expanded)
+		if len(other.PathCmd) > 0 {
+			s.commandCategories = append(s.commandCategories, other)
+			continue
 		}
+		s.addExcludes(expandPathVars(other.Paths))
 	}
+	return s
+}
 
-	return &SystemCacheTarget{
-		PathTarget:   NewPathTarget(cat),
-		excludePaths: excludes,
+func (s *SystemCacheTarget) addExcludes(patterns []string) {
+	for _, p := range patterns {
+		expanded := utils.ExpandPath(p)
+		// Drop the trailing wildcard so the owning directory itself is
+		// excluded along with everything the pattern matches inside it.
+		expanded = strings.TrimSuffix(expanded, "/**")
+		expanded = strings.TrimSuffix(expanded, "/*")
+		expanded = strings.TrimSuffix(expanded, "*")
+		expanded = strings.TrimSuffix(expanded, "/")
+		if expanded == "" {
+			continue
+		}
+		s.excludePaths = append(s.excludePaths, expanded)
 	}
 }
 
@@ -53,38 +65,44 @@ func (s *SystemCacheTarget) Scan() (*types.ScanResult, error) {
 	if len(paths) == 0 {
 		return result, nil
 	}
-	result.Items, result.TotalSize, result.TotalFileCount = s.scanPathsParallel(paths)
+	if s.category.Quota > 0 {
+		// Files deeper inside another category's paths stay with that category.
+		files := s.scanFiles(paths)
+		owned := files[:0]
+		for _, f := range files {
+			if !s.isExcluded(f.Path) {
+				owned = append(owned, f)
+			}
+		}
+		// Locked files cannot be freed, so they count toward what stays.
+		s.markLocked(owned)
+		result.Items, result.TotalSize, result.TotalFileCount = s.applyQuota(owned)
+		return result, nil
+	}
+	result.Items, result.TotalSize, result.TotalFileCount = s.applyRetention(s.applyThresholds(s.scanPathsParallel(paths)))
 	s.markLockedItems(result)
 	return result, nil
 }
 
 // collectFilteredPaths gathers paths excluding those defined in other categories
 func (s *SystemCacheTarget) collectFilteredPaths() []string {
-	var paths []string
-	for _, pattern := range s.category.Paths {
-		matched, err := utils.GlobPaths(pattern)
-		if err != nil {
-			continue
-		}
-		for _, p := range matched {
-			if !s.isExcluded(p) {
-				paths = append(paths, p)
-			}
+	return s.ownedPaths(s.matchPaths())
+}
+
+func (s *SystemCacheTarget) matchPaths() []pathMatch {
+	s.commandOnce.Do(func() {
+		for _, other := range s.commandCategories {
+			s.addExcludes(resolvePaths(other))
 		}
-	}
-	return paths
+	})
+	return s.expandPatterns(s.isExcluded)
 }
 
+// isExcluded reports whether path, or a directory containing it, is matched by
+// another category's pattern. Patterns may contain `**` anywhere.
 func (s *SystemCacheTarget) isExcluded(path string) bool {
-	// Normalize path to end with / for consistent matching
-	pathWithSlash := path
-	if !strings.HasSuffix(pathWithSlash, "/") {
-		pathWithSlash = path + "/"
-	}
-
 	for _, exclude := range s.excludePaths {
-		// Check if path is the exclude dir itself or a child of it
-		if strings.HasPrefix(pathWithSlash, exclude) {
+		if utils.MatchPathOrAncestor(exclude, path) {
 			return true
 		}
 	}
@@ -92,30 +110,57 @@ func (s *SystemCacheTarget) isExcluded(path string) bool {
 }
 
 func (s *SystemCacheTarget) markLockedItems(result *types.ScanResult) {
-	if len(result.Items) == 0 {
-		return
+	size, count := s.markLocked(result.Items)
+	result.TotalSize -= size
+	result.TotalFileCount -= count
+}
+
+// markLocked marks the cleanable items in use by a process and returns
+// their total size and file count.
+func (s *SystemCacheTarget) markLocked(items []types.CleanableItem) (int64, int64) {
+	if len(items) == 0 {
+		return 0, 0
 	}
 
 	basePath := ""
-	for _, pattern := range s.category.Paths {
+	for _, pattern := range resolvePaths(s.category) {
 		basePath = utils.StripGlobPattern(pattern)
 		if basePath != "" {
 			break
 		}
 	}
 	if basePath == "" {
-		return
+		return 0, 0
 	}
 
 	lockedPaths, err := getLockedPaths(basePath)
 	if err != nil {
 		logger.Warn("system cache lock check failed", "error", err)
-		return
+		return 0, 0
 	}
 
-	for i := range result.Items {
-		if lockedPaths[result.Items[i].Path] {
-			result.Items[i].Status = types.ItemStatusProcessLocked
+	// Locks are reported per top-level directory; with a quota the items
+	// are the files inside them.
+	basePath = filepath.Clean(utils.ExpandPath(basePath))
+	var size, count int64
+	for i := range items {
+		item := &items[i]
+		if !item.Status.Cleanable() || !isLockedPath(lockedPaths, basePath, item.Path) {
+			continue
 		}
+		item.Status = types.ItemStatusProcessLocked
+		size += item.Size
+		count += item.FileCount
 	}
+	return size, count
+}
+
+// isLockedPath reports whether path or one of its parents below basePath is locked.
+func isLockedPath(lockedPaths map[string]bool, basePath, path string) bool {
+	for p := path; p != basePath && p != "/" && p != "."; p = filepath.Dir(p) {
+		if lockedPaths[p] {
+			return true
+		}
+	}
+	return false
 }
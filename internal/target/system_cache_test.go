@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -341,3 +342,85 @@ func TestMarkLockedItems_WhenLockCheckFails_DoesNotUpdate(t *testing.T) {
 
 	assert.Equal(t, types.ItemStatusAvailable, result.Items[0].Status)
 }
+
+func TestIsExcluded_WhenDoubleStarPattern(t *testing.T) {
+	systemCache := types.Category{
+		ID:    "system-cache",
+		Paths: []string{"/tmp/test/Support/*"},
+	}
+	otherCategories := []types.Category{
+		{ID: "app-caches", Paths: []string{"/tmp/test/Support/*/**/Cache"}},
+	}
+	s := NewSystemCacheTarget(systemCache, append([]types.Category{systemCache}, otherCategories...))
+
+	assert.True(t, s.isExcluded("/tmp/test/Support/App/deep/Cache"))
+	assert.True(t, s.isExcluded("/tmp/test/Support/App/Cache/file"))
+	assert.False(t, s.isExcluded("/tmp/test/Support/App/Data"))
+}
+
+func TestScan_AppliesExcludePatterns(t *testing.T) {
+	cachesDir := t.TempDir()
+	for _, name := range []string{"com.apple.Safari", "RandomApp"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(cachesDir, name), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(cachesDir, name, "cache.dat"), []byte("test"), 0o644))
+	}
+
+	systemCache := types.Category{
+		ID:      "system-cache",
+		Paths:   []string{filepath.Join(cachesDir, "*")},
+		Exclude: []string{filepath.Join(cachesDir, "com.apple.*")},
+	}
+	s := NewSystemCacheTarget(systemCache, []types.Category{systemCache})
+
+	result, err := s.Scan()
+	require.NoError(t, err)
+	require.Len(t, result.Items, 1)
+	assert.Equal(t, "RandomApp", result.Items[0].Name)
+}
+
+func TestScan_Quota_AppliesToSystemCache(t *testing.T) {
+	cachesDir := filepath.Join(t.TempDir(), "Caches")
+	now := time.Now()
+	for i, name := range []string{"App/old", "Other/older", "Locked/oldest", "App/new", "App/newest", "Arc/data"} {
+		path := filepath.Join(cachesDir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, make([]byte, 100), 0o644))
+		used := now.Add(-time.Duration(10-i) * time.Hour)
+		if name == "Locked/oldest" {
+			used = now.Add(-100 * time.Hour)
+		}
+		require.NoError(t, os.Chtimes(path, used, used))
+	}
+	systemCache := types.Category{
+		ID:    "system-cache",
+		Paths: []string{filepath.Join(cachesDir, "*")},
+		Quota: 250,
+	}
+	s := NewSystemCacheTarget(systemCache, []types.Category{
+		systemCache,
+		{ID: "browser-arc", Paths: []string{filepath.Join(cachesDir, "Arc", "*")}},
+	})
+	originalGetLockedPaths := getLockedPaths
+	getLockedPaths = func(string) (map[string]bool, error) {
+		return map[string]bool{filepath.Join(cachesDir, "Locked"): true}, nil
+	}
+	defer func() { getLockedPaths = originalGetLockedPaths }()
+
+	result, err := s.Scan()
+
+	require.NoError(t, err)
+	statuses := make(map[string]types.ItemStatus)
+	for _, item := range result.Items {
+		rel, _ := filepath.Rel(cachesDir, item.Path)
+		statuses[rel] = item.Status
+	}
+	assert.Equal(t, map[string]types.ItemStatus{
+		"Locked/oldest": types.ItemStatusProcessLocked,
+		"App/old":       types.ItemStatusAvailable,
+		"Other/older":   types.ItemStatusAvailable,
+		"App/new":       types.ItemStatusAvailable,
+		"App/newest":    types.ItemStatusWithinQuota,
+	}, statuses, "Arc files belong to browser-arc")
+	assert.Equal(t, int64(300), result.TotalSize, "the locked file counts toward what stays")
+	assert.Equal(t, int64(3), result.TotalFileCount)
+}
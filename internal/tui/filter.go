@@ -36,7 +36,40 @@ func (m *Model) sortItems(items []types.CleanableItem) []types.CleanableItem {
 		})
 	}
 
-	return sorted
+	return groupItems(sorted)
+}
+
+// groupItems moves the members of each item group next to the member that
+// sorts first, with the group's locked member (e.g. the kept original)
+// leading. Items without a group keep their place.
+func groupItems(items []types.CleanableItem) []types.CleanableItem {
+	members := make(map[string][]types.CleanableItem)
+	for _, item := range items {
+		if item.Group != "" {
+			members[item.Group] = append(members[item.Group], item)
+		}
+	}
+	if len(members) == 0 {
+		return items
+	}
+
+	grouped := make([]types.CleanableItem, 0, len(items))
+	for _, item := range items {
+		if item.Group == "" {
+			grouped = append(grouped, item)
+			continue
+		}
+		group, ok := members[item.Group]
+		if !ok {
+			continue // already placed
+		}
+		sort.SliceStable(group, func(i, j int) bool {
+			return !group[i].Status.Cleanable() && group[j].Status.Cleanable()
+		})
+		grouped = append(grouped, group...)
+		delete(members, item.Group)
+	}
+	return grouped
 }
 
 // currentFilterQuery returns the active filter query text.
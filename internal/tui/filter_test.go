@@ -81,6 +81,25 @@ func TestSortItems_DoesNotModifyOriginal(t *testing.T) {
 	assert.Equal(t, "large", items[1].Name)
 }
 
+func TestSortItems_KeepsGroupsTogether(t *testing.T) {
+	m := newTestModelWithSortOrder(types.SortByName)
+	items := []types.CleanableItem{
+		{Name: "d-copy", Group: "asset"},
+		{Name: "b-other"},
+		{Name: "c-original", Group: "asset", Status: types.ItemStatusOriginal},
+		{Name: "a-copy", Group: "asset"},
+		{Name: "e-other"},
+	}
+
+	result := m.sortItems(items)
+
+	names := make([]string, len(result))
+	for i, item := range result {
+		names[i] = item.Name
+	}
+	assert.Equal(t, []string{"c-original", "a-copy", "d-copy", "b-other", "e-other"}, names)
+}
+
 func TestFilterItems_EmptyQuery(t *testing.T) {
 	m := &Model{}
 	items := []types.CleanableItem{
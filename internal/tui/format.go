@@ -6,6 +6,7 @@ import (
 
 	"charm.land/lipgloss/v2"
 
+	"github.com/2ykwang/mac-cleanup-go/internal/types"
 	"github.com/2ykwang/mac-cleanup-go/internal/utils"
 )
 
@@ -158,6 +159,17 @@ func formatSize(bytes int64) string {
 	return utils.FormatSize(bytes)
 }
 
+// unknownSizeText stands in for sizes that could not be estimated.
+const unknownSizeText = "?"
+
+// formatItemSize formats an item's size, or unknownSizeText when it is unknown.
+func formatItemSize(item types.CleanableItem) string {
+	if item.SizeUnknown {
+		return unknownSizeText
+	}
+	return utils.FormatSize(item.Size)
+}
+
 // shortenPath truncates path to fit within maxWidth display columns.
 func shortenPath(path string, maxWidth int) string {
 	home, _ := filepath.Abs(utils.ExpandPath("~"))
@@ -9,9 +9,51 @@ import (
 	"github.com/2ykwang/mac-cleanup-go/internal/utils"
 )
 
-const lockedItemStatusMessage = "In use by another process. Can't select."
+const (
+	lockedItemStatusMessage    = "In use by another process. Can't select."
+	retainedItemStatusMessage  = "Kept by the target's retain rule. Can't select."
+	quotaItemStatusMessage     = "Kept within the target's quota. Can't select."
+	pinnedItemStatusMessage    = "Pinned in your config or with brew pin. Can't select."
+	protectedItemStatusMessage = "Protected by a .mac-cleanup-ignore file or pyproject.toml in the project. Can't select."
+	originalItemStatusMessage  = "The copy that is kept; its duplicates are listed below it. Can't select."
+)
+
+// itemStatusMessage explains why an item with the given status can't be selected.
+func itemStatusMessage(status types.ItemStatus) string {
+	switch status {
+	case types.ItemStatusProcessLocked:
+		return lockedItemStatusMessage
+	case types.ItemStatusRetained:
+		return retainedItemStatusMessage
+	case types.ItemStatusWithinQuota:
+		return quotaItemStatusMessage
+	case types.ItemStatusPinned:
+		return pinnedItemStatusMessage
+	case types.ItemStatusProtected:
+		return protectedItemStatusMessage
+	case types.ItemStatusOriginal:
+		return originalItemStatusMessage
+	}
+	return ""
+}
+
+func isItemStatusMessage(msg string) bool {
+	switch msg {
+	case lockedItemStatusMessage, retainedItemStatusMessage, quotaItemStatusMessage, pinnedItemStatusMessage,
+		protectedItemStatusMessage, originalItemStatusMessage:
+		return true
+	}
+	return false
+}
 
 func (m *Model) handleKey(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
+	if m.err != nil {
+		switch msg.String() {
+		case "ctrl+c", "q":
+			return m, tea.Quit
+		}
+		return m, nil
+	}
 	if m.showHelp {
 		return m.handleHelpKey(msg)
 	}
@@ -59,6 +101,7 @@ func (m *Model) handleListKey(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 	}
+	m.statusMessage = ""
 	switch msg.String() {
 	case "?":
 		return m.toggleHelp()
@@ -113,6 +156,8 @@ func (m *Model) handleListKey(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 	case "d", "D":
 		// Deselect all
 		m.clearSelections()
+	case "tab":
+		m.cycleProfile()
 	case "enter", "p":
 		if m.hasSelection() {
 			m.drillDownStack = m.drillDownStack[:0]
@@ -189,12 +234,12 @@ func (m *Model) handlePreviewKey(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 		r := m.getPreviewCatResult()
 		item := m.getCurrentPreviewItem()
 		if r != nil && item != nil {
-			if item.Status == types.ItemStatusProcessLocked {
-				m.statusMessage = lockedItemStatusMessage
+			if !item.Status.Cleanable() {
+				m.statusMessage = itemStatusMessage(item.Status)
 				return m, nil
 			}
 			m.toggleExclude(r.Category.ID, item.Path)
-			if m.statusMessage == lockedItemStatusMessage {
+			if isItemStatusMessage(m.statusMessage) {
 				m.statusMessage = ""
 			}
 		}
@@ -475,6 +520,7 @@ func (m *Model) resetForRescan() {
 	m.view = ViewList
 	m.clearSelections()
 	m.excluded = make(map[string]map[string]bool)
+	m.policyProtected = 0
 	m.results = make([]*types.ScanResult, 0)
 	m.resultMap = make(map[string]*types.ScanResult)
 	m.cursor = 0
@@ -679,17 +725,17 @@ func (m *Model) setPreviewCursor(index int, max int) {
 
 func (m *Model) updatePreviewStatusMessage() {
 	if m.isSectionCollapsed(m.previewCatID) {
-		if m.statusMessage == lockedItemStatusMessage {
+		if isItemStatusMessage(m.statusMessage) {
 			m.statusMessage = ""
 		}
 		return
 	}
 	item := m.getCurrentPreviewItem()
-	if item != nil && item.Status == types.ItemStatusProcessLocked {
-		m.statusMessage = lockedItemStatusMessage
+	if item != nil && !item.Status.Cleanable() {
+		m.statusMessage = itemStatusMessage(item.Status)
 		return
 	}
-	if m.statusMessage == lockedItemStatusMessage {
+	if isItemStatusMessage(m.statusMessage) {
 		m.statusMessage = ""
 	}
 }
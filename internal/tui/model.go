@@ -1,6 +1,8 @@
 package tui
 
 import (
+	"errors"
+	"fmt"
 	"strings"
 
 	"charm.land/bubbles/v2/progress"
@@ -11,6 +13,7 @@ import (
 
 	"github.com/2ykwang/mac-cleanup-go/internal/cleaner"
 	"github.com/2ykwang/mac-cleanup-go/internal/logger"
+	"github.com/2ykwang/mac-cleanup-go/internal/policy"
 	"github.com/2ykwang/mac-cleanup-go/internal/styles"
 	"github.com/2ykwang/mac-cleanup-go/internal/target"
 	"github.com/2ykwang/mac-cleanup-go/internal/types"
@@ -58,18 +61,40 @@ func NewModel(cfg *types.Config, currentVersion string) *Model {
 	ti.CharLimit = 100
 	ti.SetWidth(30)
 
-	// Load user config
-	userCfg, _ := userconfig.Load()
+	// Load user config. An invalid file is reported instead of being
+	// replaced, so nothing is saved over it.
+	userCfg, userCfgErr := userconfig.Load()
+	if userCfgErr != nil {
+		logger.Warn("user config load failed", "error", userCfgErr)
+		userCfg = &userconfig.UserConfig{ExcludedPaths: make(map[string][]string)}
+	}
 
 	// Initialize excluded from saved config
 	excluded := userCfg.ExcludedPathsMap()
 
+	// The organization policy hides categories before anything is scanned.
+	pol, policyErr := policy.Load()
+	if policyErr != nil {
+		logger.Warn("policy load failed", "error", policyErr)
+	}
+	removals := pol.Apply(cfg)
+	userCfg.Apply(cfg)
+	// Docker contexts show as their own categories without changing cfg.
+	cfg = target.ExpandConfig(cfg)
+
 	registry, err := target.DefaultRegistry(cfg)
 	if err != nil {
 		logger.Warn("registry initialization failed", "error", err)
 		// Prevent nil registry when we surface a fatal config error.
 		registry = target.NewRegistry()
 	}
+	err = errors.Join(err, policyErr)
+	if userCfgErr != nil {
+		err = errors.Join(err, fmt.Errorf("failed to load user config: %w", userCfgErr))
+	}
+
+	cleanService := cleaner.NewCleanService(registry)
+	cleanService.SetPolicy(pol)
 
 	logger.Info("model initialized",
 		"categories", len(cfg.Categories),
@@ -85,9 +110,11 @@ func NewModel(cfg *types.Config, currentVersion string) *Model {
 		configState: configState{
 			config:            cfg,
 			registry:          registry,
-			cleanService:      cleaner.NewCleanService(registry),
+			cleanService:      cleanService,
 			hasFullDiskAccess: utils.CheckFullDiskAccess(),
 			userConfig:        userCfg,
+			policy:            pol,
+			policyRemovals:    removals,
 			err:               err,
 		},
 		dataState: dataState{
@@ -15,6 +15,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/2ykwang/mac-cleanup-go/internal/policy"
 	"github.com/2ykwang/mac-cleanup-go/internal/styles"
 	"github.com/2ykwang/mac-cleanup-go/internal/target"
 	"github.com/2ykwang/mac-cleanup-go/internal/types"
@@ -72,6 +73,31 @@ func TestNewModel_InvalidBuiltin_ShowsError(t *testing.T) {
 	assert.True(t, strings.HasPrefix(m.View().Content, "Error:"), "expected error view")
 }
 
+// writeInvalidUserConfig points HOME at a user config that fails to load.
+func writeInvalidUserConfig(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	path := filepath.Join(home, ".config", "mac-cleanup-go", "config.yaml")
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte("min_age_days:\n  pip: -1\n"), 0o644))
+	return path
+}
+
+func TestNewModel_InvalidUserConfig_ShowsError(t *testing.T) {
+	writeInvalidUserConfig(t)
+
+	m := NewModel(&types.Config{}, "test")
+
+	require.Error(t, m.err)
+	assert.Contains(t, m.err.Error(), "failed to load user config")
+	assert.True(t, strings.HasPrefix(m.View().Content, "Error:"), "expected error view")
+
+	_, cmd := m.Update(tea.KeyPressMsg{Code: 'q', Text: "q"})
+	require.NotNil(t, cmd)
+	assert.IsType(t, tea.QuitMsg{}, cmd())
+}
+
 func TestHandleReportKey_ScrollClamped(t *testing.T) {
 	m := newTestModel()
 	m.view = ViewReport
@@ -525,6 +551,16 @@ func TestToggleExclude(t *testing.T) {
 	assert.False(t, m.excluded["cat1"]["/path/1"])
 }
 
+func TestToggleExclude_DoesNotSaveCommandPath(t *testing.T) {
+	m := newTestModel()
+	m.userConfig = &userconfig.UserConfig{ExcludedPaths: make(map[string][]string)}
+
+	m.toggleExclude("go-cache", "command:go-cache")
+
+	assert.True(t, m.excluded["go-cache"]["command:go-cache"])
+	assert.Empty(t, m.userConfig.ExcludedPaths["go-cache"])
+}
+
 // View state tests
 
 func TestHandleListKey_EnterPreview(t *testing.T) {
@@ -790,6 +826,27 @@ func TestHandleScanResult_FinalizeRemovesZeroSize(t *testing.T) {
 	assert.Empty(t, m.resultMap)
 }
 
+func TestHandleScanResult_FinalizeKeepsUnknownSize(t *testing.T) {
+	cat := types.Category{ID: "cat1", Name: "Cat 1", Safety: types.SafetyLevelSafe, Method: types.MethodCommand}
+	m := newTestModel()
+	m.config = &types.Config{Categories: []types.Category{cat}}
+	m.scanning = true
+	m.scanTotal = 1
+
+	available := []target.Target{
+		testTarget{category: cat, available: true},
+	}
+	m.initScanResults(available)
+
+	result := types.NewScanResult(cat)
+	result.Items = []types.CleanableItem{{Path: "command:cat1", SizeUnknown: true}}
+	m.handleScanResult(result)
+
+	assert.Len(t, m.results, 1)
+	assert.Contains(t, m.resultMap, "cat1")
+	assert.Equal(t, "?", formatItemSize(result.Items[0]))
+}
+
 func TestInitScanResults_UsesAvailableTargetsWhenNoConfig(t *testing.T) {
 	m := newTestModel()
 	m.config = nil
@@ -1030,6 +1087,31 @@ func TestViewPreview_UsesDisplayNameWhenPresent(t *testing.T) {
 	assert.NotContains(t, output, "/path/1")
 }
 
+func TestViewPreview_ShowsGroupHeaders(t *testing.T) {
+	m := newTestModelWithResults()
+	m.view = ViewPreview
+	m.selected["cat1"] = true
+	m.previewCatID = "cat1"
+	m.initializePreviewSections()
+	m.expandCurrentSection()
+	m.results[0].Items = []types.CleanableItem{
+		{Path: "/home/Documents/a.iso", DisplayName: "Documents/a.iso", Size: 900, Group: "~/Documents"},
+		{Path: "/home/Desktop/b.zip", DisplayName: "Desktop/b.zip", Size: 800, Group: "~/Desktop"},
+		{Path: "/home/Documents/c.mov", DisplayName: "Documents/c.mov", Size: 700, Group: "~/Documents"},
+	}
+
+	output := ansi.Strip(m.viewPreview())
+
+	docs := strings.Index(output, "~/Documents (2)")
+	desktop := strings.Index(output, "~/Desktop (1)")
+	require.NotEqual(t, -1, docs)
+	require.NotEqual(t, -1, desktop)
+	assert.Less(t, docs, strings.Index(output, "Documents/a.iso"))
+	assert.Less(t, strings.Index(output, "Documents/c.mov"), desktop, "members are listed under one header")
+	assert.Less(t, desktop, strings.Index(output, "Desktop/b.zip"))
+	assert.Equal(t, 1, strings.Count(output, "~/Documents ("))
+}
+
 func TestViewPreview_ShowsLockedItemIndicator(t *testing.T) {
 	m := newTestModelWithResults()
 	m.view = ViewPreview
@@ -1897,6 +1979,30 @@ func TestHandlePreviewKey_SpaceSkipsLockedItem(t *testing.T) {
 	assert.Equal(t, lockedItemStatusMessage, m.statusMessage)
 }
 
+func TestHandlePreviewKey_SpaceSkipsRetainedItem(t *testing.T) {
+	m := newTestModelForPreview()
+	item := &m.results[0].Items[0]
+	item.Status = types.ItemStatusRetained
+
+	m.handlePreviewKey(tea.KeyPressMsg{Code: tea.KeySpace})
+
+	assert.False(t, m.isExcluded("cat1", item.Path))
+	assert.Equal(t, retainedItemStatusMessage, m.statusMessage)
+	assert.Contains(t, m.renderPreviewItemLine("cat1", *item, false, 60, 10, 8), "(retained)")
+}
+
+func TestHandlePreviewKey_SpaceSkipsProtectedItem(t *testing.T) {
+	m := newTestModelForPreview()
+	item := &m.results[0].Items[0]
+	item.Status = types.ItemStatusProtected
+
+	m.handlePreviewKey(tea.KeyPressMsg{Code: tea.KeySpace})
+
+	assert.False(t, m.isExcluded("cat1", item.Path))
+	assert.Equal(t, protectedItemStatusMessage, m.statusMessage)
+	assert.Contains(t, m.renderPreviewItemLine("cat1", *item, false, 60, 10, 8), "(protected)")
+}
+
 func TestHandlePreviewKey_SpaceAtCollapsedSectionExpandsSection(t *testing.T) {
 	m := newTestModelForPreview()
 	m.collapseCurrentSection()
@@ -2512,3 +2618,77 @@ func TestViewPreview_ClampsCurrentIndexWhenItemCountShrinks(t *testing.T) {
 
 	assert.Equal(t, 0, m.previewItemIndex)
 }
+
+func TestCycleProfile_LoadsProfileSelection(t *testing.T) {
+	m := newTestModelWithResults()
+	m.userConfig = &userconfig.UserConfig{
+		ExcludedPaths:   make(map[string][]string),
+		SelectedTargets: []string{"cat1"},
+		Profiles: map[string]*userconfig.Profile{
+			"deep": {
+				SelectedTargets: []string{"cat2", "cat3"},
+				ExcludedPaths:   map[string][]string{"cat2": {"/path/3"}},
+				MaxSafety:       types.SafetyLevelModerate,
+			},
+		},
+	}
+
+	m.Update(tea.KeyPressMsg{Code: tea.KeyTab})
+
+	assert.Equal(t, "deep", m.profileLabel())
+	assert.True(t, m.selected["cat2"])
+	assert.False(t, m.selected["cat3"], "risky target is above the profile ceiling")
+	assert.True(t, m.isExcluded("cat2", "/path/3"))
+	assert.Contains(t, m.listHeader(true), "Profile: deep")
+
+	m.Update(tea.KeyPressMsg{Code: tea.KeyTab})
+
+	assert.Equal(t, "default", m.profileLabel())
+	assert.True(t, m.selected["cat1"])
+	assert.False(t, m.selected["cat2"])
+}
+
+func TestCycleProfile_NoProfiles_KeepsSelection(t *testing.T) {
+	m := newTestModelWithResults()
+	m.addSelected("cat1")
+
+	m.Update(tea.KeyPressMsg{Code: tea.KeyTab})
+
+	assert.True(t, m.selected["cat1"])
+	assert.NotContains(t, m.listHeader(true), "Profile:")
+}
+
+func TestPolicy_MandatoryTargetStaysSelected(t *testing.T) {
+	m := newTestModelWithResults()
+	m.policy = &policy.Policy{Mandatory: []string{"cat3"}}
+	m.selectMandatory()
+
+	assert.True(t, m.selected["cat3"], "mandatory target is selected despite being risky")
+
+	m.removeSelected("cat3")
+	assert.True(t, m.selected["cat3"])
+	assert.Equal(t, "Can't deselect Xcode Archives: required by policy", m.statusMessage)
+
+	m.addSelected("cat1")
+	m.clearSelections()
+	assert.False(t, m.selected["cat1"])
+	assert.True(t, m.selected["cat3"])
+}
+
+func TestPolicy_ScanResultDropsProtectedItems(t *testing.T) {
+	m := newTestModel()
+	m.policy = &policy.Policy{ProtectedPaths: []string{"/keep"}}
+	m.policyRemovals = []policy.Removal{{CategoryID: "docker", CategoryName: "Docker", Rule: "forbidden by policy"}}
+
+	m.handleScanResult(&types.ScanResult{
+		Category:  types.Category{ID: "cat1", Name: "Cache"},
+		Items:     []types.CleanableItem{{Path: "/keep/a", Size: 10}, {Path: "/tmp/b", Size: 5}},
+		TotalSize: 15,
+	})
+
+	require.Len(t, m.resultMap["cat1"].Items, 1)
+	assert.Equal(t, int64(5), m.resultMap["cat1"].TotalSize)
+	header := m.listHeader(true)
+	assert.Contains(t, header, "Policy: 1 hidden (Docker: forbidden by policy)")
+	assert.Contains(t, header, "1 item(s) protected")
+}
@@ -1,6 +1,11 @@
 package tui
 
-import "github.com/2ykwang/mac-cleanup-go/internal/types"
+import (
+	"fmt"
+
+	"github.com/2ykwang/mac-cleanup-go/internal/target"
+	"github.com/2ykwang/mac-cleanup-go/internal/types"
+)
 
 // Selection state management
 
@@ -59,7 +64,7 @@ func (m *Model) getEffectiveSize(r *types.ScanResult) int64 {
 	excludedMap := m.excluded[r.Category.ID]
 	var total int64
 	for _, item := range r.Items {
-		if item.Status == types.ItemStatusProcessLocked {
+		if !item.Status.Cleanable() {
 			continue
 		}
 		if excludedMap == nil || !excludedMap[item.Path] {
@@ -83,11 +88,19 @@ func (m *Model) addSelected(id string) {
 	if m.selected[id] {
 		return
 	}
+	if m.policy.IsMandatory(id) {
+		m.selected[id] = true
+		m.selectedOrder = append(m.selectedOrder, id)
+		return
+	}
 	if m.scanning {
 		if r, ok := m.resultMap[id]; ok && r.Category.Safety == types.SafetyLevelRisky {
 			return
 		}
 	}
+	if r, ok := m.resultMap[id]; ok && r.Category.Safety.Exceeds(m.userConfig.GetMaxSafety()) {
+		return
+	}
 	m.selected[id] = true
 	m.selectedOrder = append(m.selectedOrder, id)
 }
@@ -96,6 +109,10 @@ func (m *Model) removeSelected(id string) {
 	if !m.selected[id] {
 		return
 	}
+	if m.policy.IsMandatory(id) {
+		m.statusMessage = fmt.Sprintf("Can't deselect %s: %s", m.categoryName(id), m.policy.MandatoryRule(id))
+		return
+	}
 	m.selected[id] = false
 	for i, existing := range m.selectedOrder {
 		if existing == id {
@@ -105,9 +122,27 @@ func (m *Model) removeSelected(id string) {
 	}
 }
 
+// clearSelections deselects everything except categories the policy requires.
 func (m *Model) clearSelections() {
 	m.selected = make(map[string]bool)
 	m.selectedOrder = nil
+	m.selectMandatory()
+}
+
+// selectMandatory selects every scanned category the policy requires.
+func (m *Model) selectMandatory() {
+	for _, id := range m.policy.MandatoryIDs() {
+		if r, ok := m.resultMap[id]; ok && r.Category.Method != types.MethodManual {
+			m.addSelected(id)
+		}
+	}
+}
+
+func (m *Model) categoryName(id string) string {
+	if r, ok := m.resultMap[id]; ok {
+		return r.Category.Name
+	}
+	return id
 }
 
 // Exclusion management
@@ -127,11 +162,13 @@ func (m *Model) toggleExclude(catID, path string) {
 	m.saveExcludedPaths()
 }
 
+// saveExcludedPaths stores the exclusions in the user config. Command items
+// are excluded for this session only: their pseudo-path names no file.
 func (m *Model) saveExcludedPaths() {
 	for catID, pathMap := range m.excluded {
 		var paths []string
 		for path, excluded := range pathMap {
-			if excluded {
+			if excluded && !target.IsCommandPath(path) {
 				paths = append(paths, path)
 			}
 		}
@@ -145,7 +182,7 @@ func (m *Model) autoExcludeCategory(catID string, r *types.ScanResult) {
 		m.excluded[catID] = make(map[string]bool)
 	}
 	for _, item := range r.Items {
-		if item.Status == types.ItemStatusProcessLocked {
+		if !item.Status.Cleanable() {
 			continue
 		}
 		m.excluded[catID][item.Path] = true
@@ -159,3 +196,45 @@ func (m *Model) clearExcludeCategory(catID string) {
 		m.saveExcludedPaths()
 	}
 }
+
+// Profile management
+
+// cycleProfile switches to the next user config profile (default first, then
+// named profiles in order) and loads its selection and exclusions.
+func (m *Model) cycleProfile() {
+	if m.userConfig == nil || len(m.userConfig.ProfileNames()) == 0 {
+		return
+	}
+
+	names := append([]string{""}, m.userConfig.ProfileNames()...)
+	next := 0
+	for i, name := range names {
+		if name == m.userConfig.ActiveProfile() {
+			next = (i + 1) % len(names)
+			break
+		}
+	}
+	m.userConfig.UseProfile(names[next])
+	m.applyProfile()
+}
+
+// applyProfile replaces the selection and exclusions with the active profile's.
+func (m *Model) applyProfile() {
+	m.excluded = m.userConfig.ExcludedPathsMap()
+	m.clearSelections()
+	for _, id := range m.userConfig.GetSelectedTargets() {
+		r, ok := m.resultMap[id]
+		if !ok || r.Category.Method == types.MethodManual {
+			continue
+		}
+		m.addSelected(id)
+	}
+}
+
+// profileLabel returns the active profile name for display.
+func (m *Model) profileLabel() string {
+	if m.userConfig == nil || m.userConfig.ActiveProfile() == "" {
+		return "default"
+	}
+	return m.userConfig.ActiveProfile()
+}
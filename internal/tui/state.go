@@ -9,6 +9,7 @@ import (
 	"charm.land/bubbles/v2/textinput"
 
 	"github.com/2ykwang/mac-cleanup-go/internal/cleaner"
+	"github.com/2ykwang/mac-cleanup-go/internal/policy"
 	"github.com/2ykwang/mac-cleanup-go/internal/styles"
 	"github.com/2ykwang/mac-cleanup-go/internal/target"
 	"github.com/2ykwang/mac-cleanup-go/internal/types"
@@ -25,6 +26,9 @@ type configState struct {
 	cleanService      *cleaner.CleanService
 	hasFullDiskAccess bool
 	userConfig        *userconfig.UserConfig
+	policy            *policy.Policy
+	policyRemovals    []policy.Removal
+	policyProtected   int // items dropped because they overlap protected paths
 	err               error
 }
 
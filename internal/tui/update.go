@@ -182,6 +182,8 @@ func (m *Model) handleScanResult(result *types.ScanResult) {
 			})
 		}
 
+		m.policyProtected += m.policy.FilterProtected(result)
+
 		if len(result.Items) > 0 {
 			sort.Slice(result.Items, func(i, j int) bool {
 				return result.Items[i].Size > result.Items[j].Size
@@ -197,6 +199,9 @@ func (m *Model) handleScanResult(result *types.ScanResult) {
 			m.results = append(m.results, result)
 			m.resultMap[result.Category.ID] = result
 		}
+		if m.policy.IsMandatory(result.Category.ID) && result.Category.Method != types.MethodManual {
+			m.addSelected(result.Category.ID)
+		}
 
 		sort.Slice(m.results, func(i, j int) bool {
 			return m.results[i].TotalSize > m.results[j].TotalSize
@@ -214,7 +219,7 @@ func (m *Model) finalizeScanResults() {
 	m.resultMap = make(map[string]*types.ScanResult)
 	var totalSize int64
 	for _, result := range m.results {
-		if result.TotalSize <= 0 {
+		if result.TotalSize <= 0 && !result.HasUnknownSize() {
 			continue
 		}
 		filtered = append(filtered, result)
@@ -1,12 +1,14 @@
 package tui
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 
 	tea "charm.land/bubbletea/v2"
 	"charm.land/lipgloss/v2"
 
+	"github.com/2ykwang/mac-cleanup-go/internal/policy"
 	"github.com/2ykwang/mac-cleanup-go/internal/styles"
 	"github.com/2ykwang/mac-cleanup-go/internal/target"
 	"github.com/2ykwang/mac-cleanup-go/internal/types"
@@ -36,6 +38,14 @@ type ConfigModel struct {
 	cursor   int
 	scroll   int
 
+	// profile is the named profile being edited; empty for the default profile.
+	profile string
+	// ceiling is the highest safety level the profile may select.
+	ceiling types.SafetyLevel
+
+	policy         *policy.Policy
+	policyRemovals []policy.Removal
+
 	width  int
 	height int
 
@@ -46,14 +56,34 @@ type ConfigModel struct {
 	styles styles.Styles
 }
 
-// NewConfigModel creates a new config TUI model.
+// NewConfigModel creates a new config TUI model for the default profile.
 func NewConfigModel(cfg *types.Config) *ConfigModel {
-	userCfg, _ := userconfig.Load()
+	return NewConfigModelForProfile(cfg, "")
+}
+
+// NewConfigModelForProfile creates a config TUI model that edits the named
+// profile, creating it on save if it does not exist yet.
+func NewConfigModelForProfile(cfg *types.Config, profile string) *ConfigModel {
+	userCfg, userCfgErr := userconfig.Load()
+	if userCfgErr != nil {
+		userCfg = &userconfig.UserConfig{ExcludedPaths: make(map[string][]string)}
+	}
+	userCfg.UseProfile(profile)
+
+	pol, policyErr := policy.Load()
+	removals := pol.Apply(cfg)
+	userCfg.Apply(cfg)
+	// Docker contexts show as their own categories without changing cfg.
+	cfg = target.ExpandConfig(cfg)
 
 	registry, err := target.DefaultRegistry(cfg)
 	if err != nil {
 		registry = target.NewRegistry()
 	}
+	err = errors.Join(err, policyErr)
+	if userCfgErr != nil {
+		err = errors.Join(err, fmt.Errorf("failed to load user config: %w", userCfgErr))
+	}
 
 	m := &ConfigModel{
 		cfg:      cfg,
@@ -63,6 +93,11 @@ func NewConfigModel(cfg *types.Config) *ConfigModel {
 		selected: make(map[string]bool),
 		err:      err,
 		styles:   styles.New(true),
+		profile:  profile,
+		ceiling:  userCfg.GetMaxSafety(),
+
+		policy:         pol,
+		policyRemovals: removals,
 	}
 
 	m.initItems()
@@ -88,7 +123,7 @@ func (m *ConfigModel) initItems() {
 func (m *ConfigModel) initSelection() {
 	itemIDs := make(map[string]bool, len(m.items))
 	for _, item := range m.items {
-		if !item.disabled {
+		if m.selectable(item) {
 			itemIDs[item.category.ID] = true
 		}
 	}
@@ -98,6 +133,11 @@ func (m *ConfigModel) initSelection() {
 			m.selected[id] = true
 		}
 	}
+	for _, id := range m.policy.MandatoryIDs() {
+		if itemIDs[id] {
+			m.selected[id] = true
+		}
+	}
 }
 
 // Init implements tea.Model.
@@ -132,6 +172,9 @@ func (m *ConfigModel) startScan() tea.Cmd {
 func (m *ConfigModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyPressMsg:
+		if m.err != nil {
+			return m.handleErrorKey(msg)
+		}
 		if m.showIntro {
 			return m.handleIntroKey(msg)
 		}
@@ -153,6 +196,16 @@ func (m *ConfigModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// handleErrorKey only lets the user quit, so a selection is never saved
+// over a config that failed to load.
+func (m *ConfigModel) handleErrorKey(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c", "q", "esc":
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
 func (m *ConfigModel) handleIntroKey(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "enter", "space", "esc", "q":
@@ -179,6 +232,8 @@ func (m *ConfigModel) handleKey(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 		m.showIntro = true
 	case "space":
 		m.toggleSelection()
+	case "c":
+		m.cycleCeiling()
 	case "enter", "s":
 		if err := m.saveSelection(); err != nil {
 			m.status = "Save failed: " + err.Error()
@@ -198,6 +253,14 @@ func (m *ConfigModel) toggleSelection() {
 		m.status = "This target can't be selected."
 		return
 	}
+	if rule := m.policy.MandatoryRule(item.category.ID); rule != "" {
+		m.status = fmt.Sprintf("%s can't be deselected: %s.", item.category.Name, rule)
+		return
+	}
+	if item.category.Safety.Exceeds(m.ceiling) {
+		m.status = fmt.Sprintf("Above the %s safety ceiling (press c to change).", m.ceiling)
+		return
+	}
 	id := item.category.ID
 	if m.selected[id] {
 		delete(m.selected, id)
@@ -206,6 +269,35 @@ func (m *ConfigModel) toggleSelection() {
 	}
 }
 
+// selectable reports whether item can be selected under the current ceiling.
+// Targets required by policy ignore the ceiling.
+func (m *ConfigModel) selectable(item configItem) bool {
+	if item.disabled {
+		return false
+	}
+	return m.policy.IsMandatory(item.category.ID) || !item.category.Safety.Exceeds(m.ceiling)
+}
+
+// cycleCeiling steps the safety ceiling through none, safe and moderate,
+// deselecting targets above the new ceiling.
+func (m *ConfigModel) cycleCeiling() {
+	switch m.ceiling {
+	case "":
+		m.ceiling = types.SafetyLevelSafe
+	case types.SafetyLevelSafe:
+		m.ceiling = types.SafetyLevelModerate
+	default:
+		m.ceiling = ""
+	}
+
+	for _, item := range m.items {
+		if !m.selectable(item) {
+			delete(m.selected, item.category.ID)
+		}
+	}
+	m.status = "Max safety: " + ceilingLabel(m.ceiling)
+}
+
 func (m *ConfigModel) saveSelection() error {
 	var selected []string
 	for _, item := range m.items {
@@ -214,9 +306,17 @@ func (m *ConfigModel) saveSelection() error {
 		}
 	}
 	m.userCfg.SetSelectedTargets(selected)
+	m.userCfg.SetMaxSafety(m.ceiling)
 	return m.userCfg.Save()
 }
 
+func ceilingLabel(ceiling types.SafetyLevel) string {
+	if ceiling == "" {
+		return "any"
+	}
+	return string(ceiling)
+}
+
 // View implements tea.Model.
 func (m *ConfigModel) View() tea.View {
 	if m.err != nil {
@@ -245,7 +345,14 @@ func (m *ConfigModel) viewList() string {
 
 	var header strings.Builder
 	header.WriteString(m.styles.HeaderStyle.Render("Target Selection") + "\n")
-	header.WriteString(m.styles.MutedStyle.Render("Select cleanup targets") + "\n")
+	subtitle := "Select cleanup targets"
+	if m.profile != "" {
+		subtitle += " · profile: " + m.profile
+	}
+	header.WriteString(m.styles.MutedStyle.Render(subtitle) + "\n")
+	if notice := formatPolicyNotice(m.policyRemovals, 0); notice != "" {
+		header.WriteString(m.styles.MutedStyle.Render(notice) + "\n")
+	}
 	header.WriteString(m.styles.Divider(clampWidth(width-4, 30)) + "\n")
 	colHeader := fmt.Sprintf("%*s%-*s %*s",
 		listPrefixWidth, "", nameWidth, "Name", sizeWidth, "Size")
@@ -254,7 +361,7 @@ func (m *ConfigModel) viewList() string {
 
 	var footer strings.Builder
 	footer.WriteString(m.styles.Divider(clampWidth(width-4, 30)) + "\n")
-	footer.WriteString(m.styles.MutedStyle.Render(fmt.Sprintf("Selected: %d", m.selectedCount())) + "\n")
+	footer.WriteString(m.styles.MutedStyle.Render(fmt.Sprintf("Selected: %d · Max safety: %s", m.selectedCount(), ceilingLabel(m.ceiling))) + "\n")
 	if m.status != "" {
 		footer.WriteString(m.styles.WarningStyle.Render(m.status) + "\n")
 	}
@@ -302,7 +409,7 @@ func (m *ConfigModel) renderItemLine(index int, item configItem, width int) stri
 	}
 
 	checkbox := m.styles.MutedStyle.Render("[ ]")
-	if item.disabled {
+	if !m.selectable(item) {
 		checkbox = m.styles.MutedStyle.Render(" - ")
 	} else if m.selected[item.category.ID] {
 		checkbox = m.styles.SuccessStyle.Render("[✓]")
@@ -313,8 +420,15 @@ func (m *ConfigModel) renderItemLine(index int, item configItem, width int) stri
 	sizeWidth := colSize
 	nameWidth := max(min(width-listPrefixWidth-sizeWidth-1, colName), 10)
 
-	name := padToWidth(truncateToWidth(item.category.Name, nameWidth, false), nameWidth)
-	if item.disabled {
+	label := item.category.Name
+	if item.category.IsUserDefined() {
+		label += " [" + item.category.SourceLabel() + "]"
+	}
+	if m.policy.IsMandatory(item.category.ID) {
+		label += " [required]"
+	}
+	name := padToWidth(truncateToWidth(label, nameWidth, false), nameWidth)
+	if !m.selectable(item) {
 		name = m.styles.MutedStyle.Render(name)
 	}
 
@@ -350,6 +464,7 @@ func clampWidth(width, min int) int {
 var configShortcuts = []Shortcut{
 	{"↑/↓", "Move"},
 	{"space", "Select"},
+	{"c", "Max safety"},
 	{"s", "Save"},
 	{"?", "Help"},
 	{"q", "Cancel"},
@@ -403,9 +518,13 @@ func (m *ConfigModel) introDialog() string {
 
 	// Commands
 	infoLabel := lipgloss.NewStyle().Foreground(m.styles.Muted).Width(10)
-	b.WriteString(infoLabel.Render("Clean") + m.styles.MutedStyle.Render("mac-cleanup ") + m.styles.SelectedStyle.Render("--clean"))
+	profileFlag := ""
+	if m.profile != "" {
+		profileFlag = " --profile " + m.profile
+	}
+	b.WriteString(infoLabel.Render("Clean") + m.styles.MutedStyle.Render("mac-cleanup ") + m.styles.SelectedStyle.Render("--clean"+profileFlag))
 	b.WriteString("\n")
-	b.WriteString(infoLabel.Render("Dry run") + m.styles.MutedStyle.Render("mac-cleanup ") + m.styles.SelectedStyle.Render("--clean --dry-run"))
+	b.WriteString(infoLabel.Render("Dry run") + m.styles.MutedStyle.Render("mac-cleanup ") + m.styles.SelectedStyle.Render("--clean --dry-run"+profileFlag))
 	b.WriteString("\n\n")
 
 	// Button
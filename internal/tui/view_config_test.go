@@ -9,6 +9,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/2ykwang/mac-cleanup-go/internal/policy"
 	"github.com/2ykwang/mac-cleanup-go/internal/types"
 	"github.com/2ykwang/mac-cleanup-go/internal/userconfig"
 )
@@ -180,3 +181,94 @@ func TestConfigModel_RenderItemLine_ShowsSize(t *testing.T) {
 	line := m.renderItemLine(0, m.items[0], m.width)
 	assert.Contains(t, line, "1 MB")
 }
+
+func TestConfigModel_Profile_SavesToNamedProfile(t *testing.T) {
+	cfg := newTestConfig(t)
+
+	m := NewConfigModelForProfile(cfg, "daily")
+	m.selected["safe"] = true
+	require.NoError(t, m.saveSelection())
+
+	loaded, err := userconfig.Load()
+	require.NoError(t, err)
+	assert.Empty(t, loaded.SelectedTargets, "default profile untouched")
+	loaded.UseProfile("daily")
+	assert.Equal(t, []string{"safe"}, loaded.GetSelectedTargets())
+}
+
+func TestConfigModel_CycleCeiling_DeselectsAboveCeiling(t *testing.T) {
+	cfg := newTestConfig(t)
+
+	m := NewConfigModelForProfile(cfg, "daily")
+	m.selected["safe"] = true
+	m.selected["moderate"] = true
+
+	m.cycleCeiling()
+
+	assert.Equal(t, types.SafetyLevelSafe, m.ceiling)
+	assert.True(t, m.selected["safe"])
+	assert.False(t, m.selected["moderate"])
+
+	// Moderate targets can't be selected while the ceiling is safe.
+	for i, item := range m.items {
+		if item.category.ID == "moderate" {
+			m.cursor = i
+		}
+	}
+	m.toggleSelection()
+	assert.False(t, m.selected["moderate"])
+	assert.Contains(t, m.status, "safety ceiling")
+
+	require.NoError(t, m.saveSelection())
+	loaded, err := userconfig.Load()
+	require.NoError(t, err)
+	loaded.UseProfile("daily")
+	assert.Equal(t, types.SafetyLevelSafe, loaded.GetMaxSafety())
+}
+
+func TestConfigModel_Policy_HidesForbiddenAndLocksMandatory(t *testing.T) {
+	cfg := newTestConfig(t)
+	policyPath := filepath.Join(t.TempDir(), "policy.yaml")
+	require.NoError(t, os.WriteFile(policyPath, []byte("forbidden: [safe]\nmandatory: [moderate]\n"), 0o644))
+	t.Setenv(policy.EnvVar, policyPath)
+
+	m := NewConfigModel(cfg)
+	require.NoError(t, m.err)
+
+	for _, item := range m.items {
+		assert.NotEqual(t, "safe", item.category.ID, "forbidden target is hidden")
+	}
+	assert.True(t, m.selected["moderate"])
+
+	m.ceiling = types.SafetyLevelSafe
+	for i, item := range m.items {
+		if item.category.ID == "moderate" {
+			m.cursor = i
+		}
+	}
+	m.toggleSelection()
+	assert.True(t, m.selected["moderate"])
+	assert.Equal(t, "Moderate can't be deselected: required by policy.", m.status)
+	assert.Contains(t, m.viewList(), "Safe: forbidden by policy")
+}
+
+func TestConfigModel_InvalidUserConfig_ShowsErrorAndKeepsFile(t *testing.T) {
+	path := writeInvalidUserConfig(t)
+	before, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	m := NewConfigModelForProfile(&types.Config{}, "daily")
+
+	require.Error(t, m.err)
+	assert.Contains(t, m.View().Content, "failed to load user config")
+
+	_, cmd := m.Update(tea.KeyPressMsg{Code: 's', Text: "s"})
+	assert.Nil(t, cmd)
+	after, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, before, after, "nothing is saved over the invalid config")
+
+	_, cmd = m.Update(tea.KeyPressMsg{Code: 'q', Text: "q"})
+	require.NotNil(t, cmd)
+	assert.IsType(t, tea.QuitMsg{}, cmd())
+}
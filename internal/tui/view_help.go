@@ -65,6 +65,7 @@ func (m *Model) helpContent(contentWidth int) string {
 		{"space", "Select category"},
 		{"enter", "Preview selected"},
 		{"a / d", "Select all / Deselect all"},
+		{"tab", "Switch profile"},
 		{"o", "Open GitHub"},
 		{"q", "Quit"},
 	} {
@@ -7,6 +7,7 @@ import (
 
 	"charm.land/lipgloss/v2"
 
+	"github.com/2ykwang/mac-cleanup-go/internal/policy"
 	"github.com/2ykwang/mac-cleanup-go/internal/types"
 	"github.com/2ykwang/mac-cleanup-go/internal/utils"
 )
@@ -90,10 +91,41 @@ func (m *Model) pendingScanNames() string {
 	return result
 }
 
+func (m *Model) policyNotice() string {
+	return formatPolicyNotice(m.policyRemovals, m.policyProtected)
+}
+
+// formatPolicyNotice summarizes what the organization policy removed, naming
+// the rule behind each hidden category.
+func formatPolicyNotice(removals []policy.Removal, protected int) string {
+	var parts []string
+	if len(removals) > 0 {
+		rules := make([]string, 0, len(removals))
+		for _, r := range removals {
+			rules = append(rules, fmt.Sprintf("%s: %s", r.CategoryName, r.Rule))
+		}
+		parts = append(parts, fmt.Sprintf("%d hidden (%s)", len(removals), strings.Join(rules, "; ")))
+	}
+	if protected > 0 {
+		parts = append(parts, fmt.Sprintf("%d item(s) protected", protected))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "[i] Policy: " + strings.Join(parts, " · ")
+}
+
 func (m *Model) listHeader(showSummary bool) string {
 	var b strings.Builder
 
 	b.WriteString(m.styles.HeaderStyle.Render("Mac Cleanup"))
+	if m.userConfig != nil && len(m.userConfig.ProfileNames()) > 0 {
+		profile := "Profile: " + m.profileLabel()
+		if ceiling := m.userConfig.GetMaxSafety(); ceiling != "" {
+			profile += " (max " + string(ceiling) + ")"
+		}
+		b.WriteString("  " + m.styles.MutedStyle.Render(profile+" · tab to switch"))
+	}
 	b.WriteString("\n")
 	if m.scanning {
 		b.WriteString(fmt.Sprintf("%s Scanning...  %s\n",
@@ -118,6 +150,12 @@ func (m *Model) listHeader(showSummary bool) string {
 		b.WriteString(m.styles.WarningStyle.Render("[!] Limited access: Grant Full Disk Access in System Settings for complete scan"))
 		b.WriteString("\n")
 	}
+
+	// Organization policy notice
+	if notice := m.policyNotice(); notice != "" {
+		b.WriteString(m.styles.MutedStyle.Render(notice))
+		b.WriteString("\n")
+	}
 	b.WriteString("\n")
 
 	// Legend
@@ -166,6 +204,11 @@ func (m *Model) listFooter(includeHelp bool) string {
 		}
 	}
 
+	if m.statusMessage != "" {
+		b.WriteString(m.styles.WarningStyle.Render(m.statusMessage))
+		b.WriteString("\n")
+	}
+
 	if includeHelp {
 		b.WriteString("\n")
 		b.WriteString(m.help.View(ListKeyMap))
@@ -274,6 +317,9 @@ func (m *Model) renderListItem(idx int, r *types.ScanResult, nameWidth, sizeWidt
 	case types.MethodManual:
 		name += " [Manual]"
 	}
+	if r.Category.IsUserDefined() {
+		name += " [" + r.Category.SourceLabel() + "]"
+	}
 	// Truncate and pad using display width for consistent alignment
 	name = padToWidth(truncateToWidth(name, nameWidth, false), nameWidth)
 	if isManual {
@@ -285,6 +331,8 @@ func (m *Model) renderListItem(idx int, r *types.ScanResult, nameWidth, sizeWidt
 	if m.scanning && r.TotalSize == 0 && r.TotalFileCount == 0 && len(r.Items) == 0 {
 		sizeText = "-"
 		countText = "-"
+	} else if r.TotalSize == 0 && r.HasUnknownSize() {
+		sizeText = unknownSizeText
 	}
 
 	size := fmt.Sprintf("%*s", sizeWidth, sizeText)
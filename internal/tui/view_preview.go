@@ -269,6 +269,9 @@ func (m *Model) renderSectionLine(r *types.ScanResult, isCurrentSection bool, is
 	if r.Category.Method == types.MethodManual {
 		badgeText += " [Manual]"
 	}
+	if r.Category.Quota > 0 {
+		badgeText += " [Quota " + formatSize(int64(r.Category.Quota)) + "]"
+	}
 	badgeText = padToWidth(truncateToWidth(badgeText, badgeWidth, false), badgeWidth)
 	badge := m.styles.MutedStyle.Render(badgeText)
 	switch r.Category.Safety {
@@ -367,7 +370,7 @@ func (m *Model) renderItemRow(opts itemRowOpts) string {
 		paddedName = m.styles.MutedStyle.Render(paddedName)
 	}
 
-	size := fmt.Sprintf("%*s", opts.sizeWidth, utils.FormatSize(item.Size))
+	size := fmt.Sprintf("%*s", opts.sizeWidth, formatItemSize(item))
 	age := fmt.Sprintf("%*s", opts.ageWidth, utils.FormatAge(item.ModifiedAt))
 	if opts.isLocked || opts.isExcluded {
 		size = m.styles.MutedStyle.Render(size)
@@ -380,9 +383,26 @@ func (m *Model) renderItemRow(opts itemRowOpts) string {
 	return fmt.Sprintf("%s%s%s %s %s %s\n", cursor, checkbox, icon, paddedName, size, age)
 }
 
+// itemStatusMarker labels items that are shown but held back from deletion.
+func itemStatusMarker(status types.ItemStatus) string {
+	switch status {
+	case types.ItemStatusRetained:
+		return " (retained)"
+	case types.ItemStatusWithinQuota:
+		return " (kept, within quota)"
+	case types.ItemStatusPinned:
+		return " (pinned)"
+	case types.ItemStatusProtected:
+		return " (protected)"
+	case types.ItemStatusOriginal:
+		return " (original)"
+	}
+	return ""
+}
+
 func (m *Model) renderPreviewItemLine(catID string, item types.CleanableItem, isCurrent bool, pathWidth, sizeWidth, ageWidth int) string {
 	isExcluded := m.isExcluded(catID, item.Path)
-	isLocked := item.Status == types.ItemStatusProcessLocked
+	isLocked := !item.Status.Cleanable()
 
 	cursor := "  "
 	if isCurrent {
@@ -411,7 +431,15 @@ func (m *Model) renderPreviewItemLine(catID string, item types.CleanableItem, is
 	}
 
 	var truncated string
-	if displayPath == item.Path {
+	if marker := itemStatusMarker(item.Status); marker != "" {
+		// Keep the marker visible however narrow the column is.
+		width := max(pathWidth-len(marker), 1)
+		if displayPath == item.Path {
+			truncated = shortenPath(displayPath, width) + marker
+		} else {
+			truncated = truncateToWidth(displayPath, width, false) + marker
+		}
+	} else if displayPath == item.Path {
 		truncated = shortenPath(displayPath, pathWidth)
 	} else {
 		truncated = truncateToWidth(displayPath, pathWidth, false)
@@ -422,7 +450,7 @@ func (m *Model) renderPreviewItemLine(catID string, item types.CleanableItem, is
 		paddedPath = m.styles.MutedStyle.Render(paddedPath)
 	}
 
-	size := fmt.Sprintf("%*s", sizeWidth, utils.FormatSize(item.Size))
+	size := fmt.Sprintf("%*s", sizeWidth, formatItemSize(item))
 	age := fmt.Sprintf("%*s", ageWidth, utils.FormatAge(item.ModifiedAt))
 	if isLocked || isExcluded {
 		size = m.styles.MutedStyle.Render(size)
@@ -435,6 +463,13 @@ func (m *Model) renderPreviewItemLine(catID string, item types.CleanableItem, is
 	return fmt.Sprintf("%s%s %s %s %s %s", cursor, checkbox, icon, paddedPath, size, age)
 }
 
+// renderGroupLine labels the members of an item group listed below it,
+// e.g. the large files in ~/Documents or the copies of one file.
+func (m *Model) renderGroupLine(group string, count int, pathWidth int) string {
+	label := fmt.Sprintf("%s (%d)", group, count)
+	return m.styles.MutedStyle.Render("    " + truncateToWidth(label, pathWidth+previewIconWidth+4, false))
+}
+
 func (m *Model) viewPreview() string {
 	if len(m.drillDownStack) > 0 {
 		return m.viewDrillDown()
@@ -500,7 +535,19 @@ func (m *Model) viewPreview() string {
 			continue
 		}
 
+		groupSizes := make(map[string]int)
+		for _, item := range items {
+			if item.Group != "" {
+				groupSizes[item.Group]++
+			}
+		}
+		prevGroup := ""
 		for itemIdx, item := range items {
+			if item.Group != "" && item.Group != prevGroup {
+				addLine(m.renderGroupLine(item.Group, groupSizes[item.Group], pathWidth), false)
+			}
+			prevGroup = item.Group
+
 			isCurrentItem := isCurrentSection && m.previewItemIndex == itemIdx
 			addLine("  "+m.renderPreviewItemLine(r.Category.ID, item, isCurrentItem, pathWidth, sizeWidth, ageWidth), isCurrentItem)
 		}
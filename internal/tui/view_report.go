@@ -8,6 +8,9 @@ import (
 	"github.com/2ykwang/mac-cleanup-go/internal/utils"
 )
 
+// reportOutputLines caps how much command output a failed result shows.
+const reportOutputLines = 5
+
 func (m *Model) reportHeader() string {
 	var b strings.Builder
 
@@ -147,6 +150,11 @@ func (m *Model) buildReportLines() []string {
 				}
 				lines = append(lines, m.styles.MutedStyle.Render(fmt.Sprintf("    └ %s", displayErr)))
 			}
+
+			// Command cleanups explain their failure in their own output.
+			for _, line := range result.OutputTail(reportOutputLines) {
+				lines = append(lines, m.styles.MutedStyle.Render("      "+truncateToWidth(line, 60, false)))
+			}
 		}
 	}
 
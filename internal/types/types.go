@@ -1,6 +1,12 @@
 package types
 
-import "time"
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
 
 type SafetyLevel string
 
@@ -10,6 +16,21 @@ const (
 	SafetyLevelRisky    SafetyLevel = "risky"
 )
 
+var safetyRank = map[SafetyLevel]int{
+	SafetyLevelSafe:     0,
+	SafetyLevelModerate: 1,
+	SafetyLevelRisky:    2,
+}
+
+// Exceeds reports whether s is above the given safety ceiling.
+// An empty ceiling allows every level.
+func (s SafetyLevel) Exceeds(ceiling SafetyLevel) bool {
+	if ceiling == "" {
+		return false
+	}
+	return safetyRank[s] > safetyRank[ceiling]
+}
+
 type CleanupMethod string
 
 const (
@@ -17,6 +38,7 @@ const (
 	MethodPermanent CleanupMethod = "permanent"
 	MethodBuiltin   CleanupMethod = "builtin"
 	MethodManual    CleanupMethod = "manual"
+	MethodCommand   CleanupMethod = "command"
 )
 
 // SortOrder represents the sorting criterion for items
@@ -65,8 +87,211 @@ type Category struct {
 	Paths    []string      `yaml:"paths,omitempty"`
 	CheckCmd string        `yaml:"check_cmd,omitempty"`
 
+	// PathCmd is an optional argv that prints a directory (e.g. ["npm", "config", "get", "cache"]).
+	// Relative Paths are resolved against it; with no Paths the directory itself is used.
+	PathCmd []string `yaml:"path_cmd,omitempty"`
+
+	// Exclude lists glob patterns for paths that must never be collected,
+	// even when they match Paths (e.g. "~/Library/Caches/com.apple.*").
+	Exclude []string `yaml:"exclude,omitempty"`
+
+	// Command is the argv run by the command method (e.g. ["go", "clean", "-cache"]).
+	Command []string `yaml:"command,omitempty"`
+	// SizeCmd is an optional argv whose output estimates reclaimable bytes.
+	// Paths are measured instead when it is not set.
+	SizeCmd []string `yaml:"size_cmd,omitempty"`
+	// Timeout limits how long Command may run. Zero uses the default.
+	Timeout time.Duration `yaml:"timeout,omitempty"`
+
+	// MinAgeDays skips items whose newest file was modified within this many days.
+	// For the docker target it skips images and build cache records created or
+	// used within this many days.
+	MinAgeDays int `yaml:"min_age_days,omitempty"`
+	// MinSize and MaxSize skip items smaller or larger than the given size. Zero disables.
+	MinSize ByteSize `yaml:"min_size,omitempty"`
+	MaxSize ByteSize `yaml:"max_size,omitempty"`
+
+	// Quota switches the scan to individual files and offers only the least
+	// recently used ones, until what remains fits within the quota. Files
+	// outside MinAgeDays, MinSize or MaxSize are never offered but still count
+	// toward the quota. Zero disables.
+	Quota ByteSize `yaml:"quota,omitempty"`
+
+	// Retain keeps the newest versions of versioned items (one directory per
+	// release or toolchain) out of cleanup.
+	Retain Retention `yaml:"retain,omitempty"`
+	// Pinned lists names a builtin target never removes, such as formulae
+	// for homebrew-autoremove. The user config adds its pinned_formulae here.
+	Pinned []string `yaml:"pinned,omitempty"`
+
+	// Runtime selects the container engine used by the docker target: "docker",
+	// "podman" or "nerdctl". Empty or "auto" uses the first one installed.
+	Runtime string `yaml:"runtime,omitempty"`
+	// Contexts lists the Docker contexts to scan, each as its own section.
+	// Empty scans the current context.
+	Contexts []string `yaml:"contexts,omitempty"`
+	// DanglingOnly limits the docker target to untagged images. Its other
+	// resources are unaffected.
+	DanglingOnly bool `yaml:"dangling_only,omitempty"`
+
+	// ProjectCache extends the project-cache scan with extra roots, cache
+	// patterns and excluded directories, and overrides its depth and staleness.
+	ProjectCache ProjectCacheOptions `yaml:"project_cache,omitempty"`
+
+	// Installers makes old-downloads also offer .dmg, .pkg and .zip files
+	// whose app is already installed, whatever their age.
+	Installers bool `yaml:"installers,omitempty"`
+
 	// BlockedByProcesses lists process names that, when running, make this target unavailable.
 	BlockedByProcesses []string `yaml:"blocked_by_processes,omitempty"`
+
+	// Source is the user target file that added or overrode this category.
+	// Empty for categories defined only in the embedded targets.yaml.
+	Source string `yaml:"-"`
+	// Line is the line in Source (or the embedded targets.yaml) where the category is defined.
+	Line int `yaml:"-"`
+}
+
+// IsUserDefined reports whether the category was added or modified by a user target file.
+func (c Category) IsUserDefined() bool {
+	return c.Source != ""
+}
+
+// SourceLabel returns a short label describing where the category was defined.
+func (c Category) SourceLabel() string {
+	if c.Source == "" {
+		return "built-in"
+	}
+	return filepath.Base(c.Source)
+}
+
+// HasThresholds reports whether age or size thresholds are configured.
+func (c Category) HasThresholds() bool {
+	return c.MinAgeDays > 0 || c.MinSize > 0 || c.MaxSize > 0
+}
+
+// Retention describes which versioned items a category keeps.
+type Retention struct {
+	// Pattern is a regular expression matched against item names. The version
+	// is taken from the "version" named group, else the first group, else the
+	// whole match. Versions are compared among items in the same directory
+	// and with the same "name" named group, if any. Items that do not match
+	// are not affected.
+	Pattern string `yaml:"pattern,omitempty"`
+	// Keep is how many of the newest versions to keep in each group.
+	Keep int `yaml:"keep,omitempty"`
+}
+
+// Enabled reports whether a retention rule is configured.
+func (r Retention) Enabled() bool {
+	return r.Pattern != ""
+}
+
+// ProjectCacheOptions configures the project-cache target. Lists extend the
+// built-in defaults; zero numbers keep the default.
+type ProjectCacheOptions struct {
+	// Roots are directories scanned in addition to $HOME (e.g. "/Volumes/Work").
+	Roots []string `yaml:"roots,omitempty"`
+	// Patterns are extra cache directories recognised by their marker files.
+	Patterns []CachePattern `yaml:"patterns,omitempty"`
+	// ExcludeDirs are directory names skipped at any depth, or paths
+	// (starting with / or ~/) skipped with everything below them.
+	ExcludeDirs []string `yaml:"exclude_dirs,omitempty"`
+	// MaxDepth is how many directories below a root the scan descends.
+	MaxDepth int `yaml:"max_depth,omitempty"`
+	// StaleDays skips caches whose newest file changed within this many days.
+	StaleDays int `yaml:"stale_days,omitempty"`
+}
+
+// CachePattern is a build artifact directory that counts as a project cache
+// only when one of its marker files sits next to it (e.g. "_build" beside "mix.exs").
+type CachePattern struct {
+	Dir     string   `yaml:"dir"`
+	Markers []string `yaml:"markers"`
+}
+
+// IsZero reports whether no option is set.
+func (o ProjectCacheOptions) IsZero() bool {
+	return len(o.Roots) == 0 && len(o.Patterns) == 0 && len(o.ExcludeDirs) == 0 &&
+		o.MaxDepth == 0 && o.StaleDays == 0
+}
+
+// Validate returns every problem with the options, or nil.
+func (o ProjectCacheOptions) Validate() []error {
+	var errs []error
+	for _, root := range o.Roots {
+		if !filepath.IsAbs(root) && !strings.HasPrefix(root, "~/") && !strings.HasPrefix(root, "$") {
+			errs = append(errs, fmt.Errorf("project_cache root '%s' must be absolute or start with ~/", root))
+		}
+	}
+	for i, p := range o.Patterns {
+		if !isPlainName(p.Dir) {
+			errs = append(errs, fmt.Errorf("project_cache pattern %d: dir '%s' must be a single directory name", i+1, p.Dir))
+		}
+		if len(p.Markers) == 0 {
+			errs = append(errs, fmt.Errorf("project_cache pattern %d: markers must not be empty", i+1))
+		}
+		for _, m := range p.Markers {
+			if !isPlainName(m) {
+				errs = append(errs, fmt.Errorf("project_cache pattern %d: marker '%s' must be a single file name", i+1, m))
+			}
+		}
+	}
+	for _, dir := range o.ExcludeDirs {
+		if strings.TrimSpace(dir) == "" {
+			errs = append(errs, fmt.Errorf("project_cache exclude_dirs must not contain empty entries"))
+		}
+	}
+	if o.MaxDepth < 0 {
+		errs = append(errs, fmt.Errorf("project_cache max_depth must not be negative"))
+	}
+	if o.StaleDays < 0 {
+		errs = append(errs, fmt.Errorf("project_cache stale_days must not be negative"))
+	}
+	return errs
+}
+
+// isPlainName reports whether s names a single directory entry.
+func isPlainName(s string) bool {
+	return s != "" && s != "." && s != ".." && !strings.ContainsRune(s, filepath.Separator)
+}
+
+// ByteSize is a size in bytes that can be written in YAML as a plain number
+// or with a binary unit suffix (e.g. "500MB", "1.5GB").
+type ByteSize int64
+
+// UnmarshalText parses a byte size such as "1048576", "512KB" or "2GB".
+func (b *ByteSize) UnmarshalText(text []byte) error {
+	s := strings.ToUpper(strings.TrimSpace(string(text)))
+	if s == "" {
+		*b = 0
+		return nil
+	}
+
+	multiplier := 1.0
+	for _, unit := range []struct {
+		suffix string
+		factor float64
+	}{
+		{"TB", 1 << 40},
+		{"GB", 1 << 30},
+		{"MB", 1 << 20},
+		{"KB", 1 << 10},
+		{"B", 1},
+	} {
+		if strings.HasSuffix(s, unit.suffix) {
+			multiplier = unit.factor
+			s = strings.TrimSpace(strings.TrimSuffix(s, unit.suffix))
+			break
+		}
+	}
+
+	value, err := strconv.ParseFloat(s, 64)
+	if err != nil || value < 0 {
+		return fmt.Errorf("invalid size %q", string(text))
+	}
+	*b = ByteSize(value * multiplier)
+	return nil
 }
 
 type Group struct {
@@ -86,8 +311,24 @@ type ItemStatus int
 const (
 	ItemStatusAvailable ItemStatus = iota
 	ItemStatusProcessLocked
+	// ItemStatusRetained marks items kept by the category's retain rule.
+	ItemStatusRetained
+	// ItemStatusWithinQuota marks files kept because the category fits its quota.
+	ItemStatusWithinQuota
+	// ItemStatusPinned marks items the user pinned, such as Homebrew formulae.
+	ItemStatusPinned
+	// ItemStatusProtected marks items a project asked to keep, such as with
+	// a .mac-cleanup-ignore file.
+	ItemStatusProtected
+	// ItemStatusOriginal marks the copy of a duplicated file that is kept.
+	ItemStatusOriginal
 )
 
+// Cleanable reports whether items with this status may be offered for deletion.
+func (s ItemStatus) Cleanable() bool {
+	return s == ItemStatusAvailable
+}
+
 type CleanableItem struct {
 	Path        string
 	Size        int64
@@ -97,6 +338,16 @@ type CleanableItem struct {
 	IsDirectory bool
 	ModifiedAt  time.Time
 	Status      ItemStatus
+	// KeepPaths are paths inside a directory item that belong to another
+	// category. Cleaning removes everything in the item except them.
+	KeepPaths []string
+	// SizeUnknown marks items whose size could not be estimated, such as a
+	// cleanup command without size_cmd or paths. Size is 0 for them.
+	SizeUnknown bool
+	// Group ties items that belong together, such as the copies of one
+	// file or the large files in one folder; the preview lists the members
+	// of a group together under its name.
+	Group string
 }
 
 type ScanResult struct {
@@ -113,6 +364,7 @@ type CleanResult struct {
 	SkippedItems int // SIP protected paths skipped during cleanup
 	FreedSpace   int64
 	Errors       []string
+	Output       string // Captured output of command-method cleanups
 }
 
 // Merge accumulates another CleanResult's counters and errors into this one.
@@ -121,6 +373,26 @@ func (r *CleanResult) Merge(other *CleanResult) {
 	r.SkippedItems += other.SkippedItems
 	r.FreedSpace += other.FreedSpace
 	r.Errors = append(r.Errors, other.Errors...)
+	if other.Output != "" {
+		if r.Output != "" {
+			r.Output += "\n"
+		}
+		r.Output += other.Output
+	}
+}
+
+// OutputTail returns up to limit trailing non-empty lines of the captured output.
+func (r *CleanResult) OutputTail(limit int) []string {
+	var lines []string
+	for _, line := range strings.Split(r.Output, "\n") {
+		if line = strings.TrimRight(line, " \t\r"); strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+	if limit > 0 && len(lines) > limit {
+		lines = lines[len(lines)-limit:]
+	}
+	return lines
 }
 
 func NewScanResult(category Category) *ScanResult {
@@ -130,6 +402,16 @@ func NewScanResult(category Category) *ScanResult {
 	}
 }
 
+// HasUnknownSize reports whether any item's size could not be estimated.
+func (r *ScanResult) HasUnknownSize() bool {
+	for _, item := range r.Items {
+		if item.SizeUnknown {
+			return true
+		}
+	}
+	return false
+}
+
 func NewCleanResult(category Category) *CleanResult {
 	return &CleanResult{
 		Category: category,
@@ -125,3 +125,74 @@ func TestNewCleanResult_InitializesDefaults(t *testing.T) {
 	assert.Zero(t, result.SkippedItems)
 	assert.Zero(t, result.FreedSpace)
 }
+
+func TestCategory_SourceLabel(t *testing.T) {
+	builtin := Category{ID: "cache"}
+	custom := Category{ID: "team", Source: "/home/u/.config/mac-cleanup-go/targets.d/team.yaml"}
+
+	assert.False(t, builtin.IsUserDefined())
+	assert.Equal(t, "built-in", builtin.SourceLabel())
+	assert.True(t, custom.IsUserDefined())
+	assert.Equal(t, "team.yaml", custom.SourceLabel())
+}
+
+func TestCleanResult_Merge_JoinsOutput(t *testing.T) {
+	r := NewCleanResult(Category{ID: "a"})
+
+	r.Merge(&CleanResult{CleanedItems: 1, Output: "first"})
+	r.Merge(&CleanResult{CleanedItems: 1})
+	r.Merge(&CleanResult{CleanedItems: 1, Output: "second"})
+
+	assert.Equal(t, 3, r.CleanedItems)
+	assert.Equal(t, "first\nsecond", r.Output)
+}
+
+func TestCleanResult_OutputTail(t *testing.T) {
+	r := &CleanResult{Output: "one\n\ntwo\nthree\n"}
+
+	assert.Equal(t, []string{"two", "three"}, r.OutputTail(2))
+	assert.Equal(t, []string{"one", "two", "three"}, r.OutputTail(0))
+	assert.Empty(t, (&CleanResult{}).OutputTail(3))
+}
+
+func TestByteSize_UnmarshalText(t *testing.T) {
+	tests := []struct {
+		in   string
+		want ByteSize
+	}{
+		{"1024", 1024},
+		{"512KB", 512 << 10},
+		{"500 mb", 500 << 20},
+		{"1.5GB", 3 << 29},
+		{"2TB", 2 << 40},
+		{"10B", 10},
+		{"", 0},
+	}
+
+	for _, tt := range tests {
+		var b ByteSize
+		assert.NoError(t, b.UnmarshalText([]byte(tt.in)), tt.in)
+		assert.Equal(t, tt.want, b, tt.in)
+	}
+}
+
+func TestByteSize_UnmarshalText_Invalid(t *testing.T) {
+	for _, in := range []string{"lots", "-5MB", "GB"} {
+		var b ByteSize
+		assert.Error(t, b.UnmarshalText([]byte(in)), in)
+	}
+}
+
+func TestCategory_HasThresholds(t *testing.T) {
+	assert.False(t, Category{}.HasThresholds())
+	assert.True(t, Category{MinAgeDays: 7}.HasThresholds())
+	assert.True(t, Category{MaxSize: 1}.HasThresholds())
+}
+
+func TestSafetyLevel_Exceeds(t *testing.T) {
+	assert.False(t, SafetyLevelRisky.Exceeds(""), "empty ceiling allows everything")
+	assert.False(t, SafetyLevelSafe.Exceeds(SafetyLevelSafe))
+	assert.True(t, SafetyLevelModerate.Exceeds(SafetyLevelSafe))
+	assert.False(t, SafetyLevelModerate.Exceeds(SafetyLevelModerate))
+	assert.True(t, SafetyLevelRisky.Exceeds(SafetyLevelModerate))
+}
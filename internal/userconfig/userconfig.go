@@ -1,10 +1,16 @@
 package userconfig
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
 
 	"gopkg.in/yaml.v3"
+
+	"github.com/2ykwang/mac-cleanup-go/internal/types"
 )
 
 const (
@@ -23,8 +29,34 @@ var (
 type UserConfig struct {
 	// ExcludedPaths maps category ID to list of excluded paths
 	ExcludedPaths map[string][]string `yaml:"excluded_paths,omitempty"`
+	// ExcludePatterns maps category ID to extra glob patterns appended to the
+	// category's exclude list (e.g. "~/Library/Caches/com.vpn.*")
+	ExcludePatterns map[string][]string `yaml:"exclude_patterns,omitempty"`
 	// SelectedTargets stores CLI-selected category IDs
 	SelectedTargets []string `yaml:"selected_targets,omitempty"`
+	// MaxSafety is the highest safety level cleaned by the default profile. Empty means no ceiling.
+	MaxSafety types.SafetyLevel `yaml:"max_safety,omitempty"`
+	// Profiles maps a profile name to its own selection, exclusions and safety ceiling
+	Profiles map[string]*Profile `yaml:"profiles,omitempty"`
+	// PinnedFormulae are Homebrew formulae never offered for removal
+	PinnedFormulae []string `yaml:"pinned_formulae,omitempty"`
+	// MinAgeDays maps category ID to the age in days below which items are
+	// skipped (e.g. old-downloads: 60), replacing the target's own threshold
+	MinAgeDays map[string]int `yaml:"min_age_days,omitempty"`
+	// ProjectCache adds roots, cache patterns and excluded directories to the
+	// project-cache target and overrides its depth and staleness
+	ProjectCache types.ProjectCacheOptions `yaml:"project_cache,omitempty"`
+
+	// active is the profile the accessors read and write. Empty means the
+	// default profile stored in the top-level fields.
+	active string
+}
+
+// Profile is a named set of cleanup settings, e.g. a light daily clean and a deep monthly one.
+type Profile struct {
+	SelectedTargets []string            `yaml:"selected_targets,omitempty"`
+	ExcludedPaths   map[string][]string `yaml:"excluded_paths,omitempty"`
+	MaxSafety       types.SafetyLevel   `yaml:"max_safety,omitempty"`
 }
 
 // configPath returns the full path to the config file
@@ -55,6 +87,15 @@ func Load() (*UserConfig, error) {
 	if err := yaml.Unmarshal(data, &cfg); err != nil {
 		return nil, err
 	}
+	errs := cfg.ProjectCache.Validate()
+	for id, days := range cfg.MinAgeDays {
+		if days < 0 {
+			errs = append(errs, fmt.Errorf("min_age_days for '%s' must not be negative", id))
+		}
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("%s: %w", path, errors.Join(errs...))
+	}
 
 	if cfg.ExcludedPaths == nil {
 		cfg.ExcludedPaths = make(map[string][]string)
@@ -87,34 +128,120 @@ func (c *UserConfig) Save() error {
 	return osWriteFile(path, data, 0o644)
 }
 
+// UseProfile makes name the active profile, creating it if needed.
+// An empty name selects the default profile.
+func (c *UserConfig) UseProfile(name string) {
+	c.active = name
+	if name == "" {
+		return
+	}
+	if c.Profiles == nil {
+		c.Profiles = make(map[string]*Profile)
+	}
+	if c.Profiles[name] == nil {
+		c.Profiles[name] = &Profile{}
+	}
+}
+
+// HasProfile reports whether a named profile exists.
+func (c *UserConfig) HasProfile(name string) bool {
+	return c.Profiles[name] != nil
+}
+
+// ActiveProfile returns the active profile name, or "" for the default profile.
+func (c *UserConfig) ActiveProfile() string {
+	return c.active
+}
+
+// ProfileNames returns the named profiles in sorted order.
+func (c *UserConfig) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (c *UserConfig) activeProfile() *Profile {
+	if c.active == "" {
+		return nil
+	}
+	return c.Profiles[c.active]
+}
+
+// excludedPaths returns the active profile's excluded paths map.
+func (c *UserConfig) excludedPaths() map[string][]string {
+	if p := c.activeProfile(); p != nil {
+		if p.ExcludedPaths == nil {
+			p.ExcludedPaths = make(map[string][]string)
+		}
+		return p.ExcludedPaths
+	}
+	if c.ExcludedPaths == nil {
+		c.ExcludedPaths = make(map[string][]string)
+	}
+	return c.ExcludedPaths
+}
+
 // SetExcludedPaths sets excluded paths for a category
 func (c *UserConfig) SetExcludedPaths(categoryID string, paths []string) {
+	excluded := c.excludedPaths()
 	if len(paths) == 0 {
-		delete(c.ExcludedPaths, categoryID)
+		delete(excluded, categoryID)
 	} else {
-		c.ExcludedPaths[categoryID] = paths
+		excluded[categoryID] = paths
 	}
 }
 
 // SetSelectedTargets sets selected target IDs for CLI runs.
 func (c *UserConfig) SetSelectedTargets(targets []string) {
-	c.SelectedTargets = append([]string(nil), targets...)
+	targets = append([]string(nil), targets...)
+	if p := c.activeProfile(); p != nil {
+		p.SelectedTargets = targets
+		return
+	}
+	c.SelectedTargets = targets
 }
 
 // GetSelectedTargets returns selected target IDs for CLI runs.
 func (c *UserConfig) GetSelectedTargets() []string {
+	if p := c.activeProfile(); p != nil {
+		return p.SelectedTargets
+	}
 	return c.SelectedTargets
 }
 
+// SetMaxSafety sets the active profile's safety ceiling. Empty removes it.
+func (c *UserConfig) SetMaxSafety(level types.SafetyLevel) {
+	if p := c.activeProfile(); p != nil {
+		p.MaxSafety = level
+		return
+	}
+	c.MaxSafety = level
+}
+
+// GetMaxSafety returns the active profile's safety ceiling, or "" for none.
+func (c *UserConfig) GetMaxSafety() types.SafetyLevel {
+	if c == nil {
+		return ""
+	}
+	if p := c.activeProfile(); p != nil {
+		return p.MaxSafety
+	}
+	return c.MaxSafety
+}
+
 // GetExcludedPaths gets excluded paths for a category
 func (c *UserConfig) GetExcludedPaths(categoryID string) []string {
-	return c.ExcludedPaths[categoryID]
+	return c.excludedPaths()[categoryID]
 }
 
 // ExcludedPathsMap returns excluded paths as a nested bool map
 func (c *UserConfig) ExcludedPathsMap() map[string]map[string]bool {
-	result := make(map[string]map[string]bool, len(c.ExcludedPaths))
-	for catID, paths := range c.ExcludedPaths {
+	excluded := c.excludedPaths()
+	result := make(map[string]map[string]bool, len(excluded))
+	for catID, paths := range excluded {
 		result[catID] = make(map[string]bool, len(paths))
 		for _, p := range paths {
 			result[catID][p] = true
@@ -125,10 +252,111 @@ func (c *UserConfig) ExcludedPathsMap() map[string]map[string]bool {
 
 // IsExcluded checks if a path is excluded for a category
 func (c *UserConfig) IsExcluded(categoryID, path string) bool {
-	for _, p := range c.ExcludedPaths[categoryID] {
+	for _, p := range c.excludedPaths()[categoryID] {
 		if p == path {
 			return true
 		}
 	}
 	return false
 }
+
+// pinnedFormulaeTarget is the category that honours PinnedFormulae.
+const pinnedFormulaeTarget = "homebrew-autoremove"
+
+// ApplyPinnedFormulae adds the user's pinned formulae to the category that
+// removes formulae.
+func (c *UserConfig) ApplyPinnedFormulae(cfg *types.Config) {
+	if c == nil || cfg == nil {
+		return
+	}
+	for i := range cfg.Categories {
+		cat := &cfg.Categories[i]
+		if cat.ID != pinnedFormulaeTarget {
+			continue
+		}
+		for _, name := range c.PinnedFormulae {
+			if !slices.Contains(cat.Pinned, name) {
+				cat.Pinned = append(cat.Pinned, name)
+			}
+		}
+	}
+}
+
+// Apply merges every user setting that changes category definitions into
+// cfg. It must run before the registry is built from cfg.
+func (c *UserConfig) Apply(cfg *types.Config) {
+	c.ApplyExcludePatterns(cfg)
+	c.ApplyPinnedFormulae(cfg)
+	c.ApplyProjectCache(cfg)
+	c.ApplyMinAgeDays(cfg)
+}
+
+// ApplyMinAgeDays sets the user's age thresholds on the matching categories.
+func (c *UserConfig) ApplyMinAgeDays(cfg *types.Config) {
+	if c == nil || cfg == nil {
+		return
+	}
+	for i := range cfg.Categories {
+		cat := &cfg.Categories[i]
+		if days, ok := c.MinAgeDays[cat.ID]; ok && days > 0 {
+			cat.MinAgeDays = days
+		}
+	}
+}
+
+// projectCacheTarget is the category that honours ProjectCache.
+const projectCacheTarget = "project-cache"
+
+// ApplyProjectCache merges the user's project cache options into the
+// project-cache category: lists are appended, non-zero numbers win.
+func (c *UserConfig) ApplyProjectCache(cfg *types.Config) {
+	if c == nil || cfg == nil || c.ProjectCache.IsZero() {
+		return
+	}
+	for i := range cfg.Categories {
+		cat := &cfg.Categories[i]
+		if cat.ID != projectCacheTarget {
+			continue
+		}
+		opts := &cat.ProjectCache
+		for _, root := range c.ProjectCache.Roots {
+			if !slices.Contains(opts.Roots, root) {
+				opts.Roots = append(opts.Roots, root)
+			}
+		}
+		for _, p := range c.ProjectCache.Patterns {
+			if !slices.ContainsFunc(opts.Patterns, func(q types.CachePattern) bool {
+				return q.Dir == p.Dir && slices.Equal(q.Markers, p.Markers)
+			}) {
+				opts.Patterns = append(opts.Patterns, p)
+			}
+		}
+		for _, dir := range c.ProjectCache.ExcludeDirs {
+			if !slices.Contains(opts.ExcludeDirs, dir) {
+				opts.ExcludeDirs = append(opts.ExcludeDirs, dir)
+			}
+		}
+		if c.ProjectCache.MaxDepth > 0 {
+			opts.MaxDepth = c.ProjectCache.MaxDepth
+		}
+		if c.ProjectCache.StaleDays > 0 {
+			opts.StaleDays = c.ProjectCache.StaleDays
+		}
+	}
+}
+
+// ApplyExcludePatterns appends the user's exclude patterns to the matching
+// categories in cfg. Patterns already present are not added again.
+func (c *UserConfig) ApplyExcludePatterns(cfg *types.Config) {
+	if c == nil || cfg == nil {
+		return
+	}
+	for i := range cfg.Categories {
+		cat := &cfg.Categories[i]
+		for _, pattern := range c.ExcludePatterns[cat.ID] {
+			if !slices.Contains(cat.Exclude, pattern) {
+				cat.Exclude = append(cat.Exclude, pattern)
+			}
+		}
+	}
+}
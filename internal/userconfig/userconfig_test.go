@@ -9,6 +9,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/2ykwang/mac-cleanup-go/internal/types"
 )
 
 func TestLoad_NoConfigFile(t *testing.T) {
@@ -206,3 +208,215 @@ func TestSave_WriteFileError(t *testing.T) {
 
 	assert.Error(t, err)
 }
+
+func TestLoad_ExcludePatterns(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+
+	configDir := filepath.Join(tmpDir, ".config", "mac-cleanup-go")
+	require.NoError(t, os.MkdirAll(configDir, 0o755))
+	data := []byte("exclude_patterns:\n  system-cache:\n    - \"~/Library/Caches/com.vpn.*\"\n")
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "config.yaml"), data, 0o644))
+
+	cfg, err := Load()
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"~/Library/Caches/com.vpn.*"}, cfg.ExcludePatterns["system-cache"])
+}
+
+func TestUserConfig_ApplyExcludePatterns(t *testing.T) {
+	userCfg := &UserConfig{
+		ExcludePatterns: map[string][]string{
+			"system-cache": {"~/Library/Caches/com.apple.*", "~/Library/Caches/com.vpn.*"},
+		},
+	}
+	cfg := &types.Config{
+		Categories: []types.Category{
+			{ID: "system-cache", Exclude: []string{"~/Library/Caches/com.apple.*"}},
+			{ID: "other"},
+		},
+	}
+
+	userCfg.ApplyExcludePatterns(cfg)
+	userCfg.ApplyExcludePatterns(cfg)
+
+	assert.Equal(t, []string{"~/Library/Caches/com.apple.*", "~/Library/Caches/com.vpn.*"}, cfg.Categories[0].Exclude)
+	assert.Empty(t, cfg.Categories[1].Exclude)
+}
+
+func TestUserConfig_ApplyPinnedFormulae(t *testing.T) {
+	userCfg := &UserConfig{PinnedFormulae: []string{"python@3.11", "openssl@3"}}
+	cfg := &types.Config{
+		Categories: []types.Category{
+			{ID: "homebrew-autoremove", Pinned: []string{"openssl@3"}},
+			{ID: "homebrew"},
+		},
+	}
+
+	userCfg.ApplyPinnedFormulae(cfg)
+
+	assert.Equal(t, []string{"openssl@3", "python@3.11"}, cfg.Categories[0].Pinned)
+	assert.Empty(t, cfg.Categories[1].Pinned)
+}
+
+func TestLoad_ProjectCache(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+
+	configDir := filepath.Join(tmpDir, ".config", "mac-cleanup-go")
+	require.NoError(t, os.MkdirAll(configDir, 0o755))
+	data := []byte(`project_cache:
+  roots: ["/Volumes/Work"]
+  patterns:
+    - dir: .turbo
+      markers: [turbo.json]
+  max_depth: 10
+`)
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "config.yaml"), data, 0o644))
+
+	cfg, err := Load()
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/Volumes/Work"}, cfg.ProjectCache.Roots)
+	assert.Equal(t, []types.CachePattern{{Dir: ".turbo", Markers: []string{"turbo.json"}}}, cfg.ProjectCache.Patterns)
+	assert.Equal(t, 10, cfg.ProjectCache.MaxDepth)
+}
+
+func TestLoad_InvalidProjectCachePattern(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+
+	configDir := filepath.Join(tmpDir, ".config", "mac-cleanup-go")
+	require.NoError(t, os.MkdirAll(configDir, 0o755))
+	data := []byte("project_cache:\n  patterns:\n    - dir: bazel-out\n")
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "config.yaml"), data, 0o644))
+
+	cfg, err := Load()
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "markers must not be empty")
+	assert.Nil(t, cfg)
+}
+
+func TestUserConfig_ApplyProjectCache(t *testing.T) {
+	userCfg := &UserConfig{ProjectCache: types.ProjectCacheOptions{
+		Roots:       []string{"/Volumes/Work"},
+		Patterns:    []types.CachePattern{{Dir: "_build", Markers: []string{"mix.exs"}}},
+		ExcludeDirs: []string{"third_party"},
+		StaleDays:   14,
+	}}
+	cfg := &types.Config{
+		Categories: []types.Category{
+			{ID: "project-cache", ProjectCache: types.ProjectCacheOptions{
+				Roots:     []string{"~/src"},
+				MaxDepth:  6,
+				StaleDays: 3,
+			}},
+			{ID: "other"},
+		},
+	}
+
+	userCfg.ApplyProjectCache(cfg)
+	userCfg.ApplyProjectCache(cfg)
+
+	opts := cfg.Categories[0].ProjectCache
+	assert.Equal(t, []string{"~/src", "/Volumes/Work"}, opts.Roots)
+	assert.Len(t, opts.Patterns, 1)
+	assert.Equal(t, []string{"third_party"}, opts.ExcludeDirs)
+	assert.Equal(t, 6, opts.MaxDepth)
+	assert.Equal(t, 14, opts.StaleDays)
+	assert.True(t, cfg.Categories[1].ProjectCache.IsZero())
+}
+
+func TestUserConfig_ApplyExcludePatterns_NilSafe(t *testing.T) {
+	var userCfg *UserConfig
+
+	assert.NotPanics(t, func() { userCfg.ApplyExcludePatterns(&types.Config{}) })
+	assert.NotPanics(t, func() { userCfg.Apply(&types.Config{}) })
+}
+
+func TestUserConfig_ApplyMinAgeDays(t *testing.T) {
+	userCfg := &UserConfig{MinAgeDays: map[string]int{"old-downloads": 60, "unknown": 5}}
+	cfg := &types.Config{
+		Categories: []types.Category{
+			{ID: "old-downloads"},
+			{ID: "xcode-derived", MinAgeDays: 14},
+		},
+	}
+
+	userCfg.Apply(cfg)
+
+	assert.Equal(t, 60, cfg.Categories[0].MinAgeDays)
+	assert.Equal(t, 14, cfg.Categories[1].MinAgeDays)
+}
+
+func TestLoad_NegativeMinAgeDays(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+
+	configDir := filepath.Join(tmpDir, ".config", "mac-cleanup-go")
+	require.NoError(t, os.MkdirAll(configDir, 0o755))
+	data := []byte("min_age_days:\n  old-downloads: -1\n")
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "config.yaml"), data, 0o644))
+
+	_, err := Load()
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "min_age_days for 'old-downloads' must not be negative")
+}
+
+func TestUserConfig_Profiles_IsolateSettings(t *testing.T) {
+	cfg := &UserConfig{
+		ExcludedPaths:   map[string][]string{"logs": {"/keep"}},
+		SelectedTargets: []string{"logs"},
+	}
+
+	cfg.UseProfile("deep")
+	assert.Equal(t, "deep", cfg.ActiveProfile())
+	assert.True(t, cfg.HasProfile("deep"))
+	assert.Empty(t, cfg.GetSelectedTargets(), "new profile starts empty")
+	assert.False(t, cfg.IsExcluded("logs", "/keep"))
+
+	cfg.SetSelectedTargets([]string{"xcode", "docker"})
+	cfg.SetExcludedPaths("xcode", []string{"/archive"})
+	cfg.SetMaxSafety(types.SafetyLevelModerate)
+
+	cfg.UseProfile("")
+	assert.Equal(t, []string{"logs"}, cfg.GetSelectedTargets())
+	assert.True(t, cfg.IsExcluded("logs", "/keep"))
+	assert.Empty(t, cfg.GetMaxSafety())
+
+	cfg.UseProfile("deep")
+	assert.Equal(t, []string{"xcode", "docker"}, cfg.GetSelectedTargets())
+	assert.Equal(t, map[string]map[string]bool{"xcode": {"/archive": true}}, cfg.ExcludedPathsMap())
+	assert.Equal(t, types.SafetyLevelModerate, cfg.GetMaxSafety())
+}
+
+func TestUserConfig_ProfileNames_Sorted(t *testing.T) {
+	cfg := &UserConfig{}
+	cfg.UseProfile("monthly")
+	cfg.UseProfile("daily")
+
+	assert.Equal(t, []string{"daily", "monthly"}, cfg.ProfileNames())
+	assert.False(t, cfg.HasProfile("weekly"))
+}
+
+func TestUserConfig_Profiles_SaveAndLoad(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	cfg.UseProfile("daily")
+	cfg.SetSelectedTargets([]string{"system-cache"})
+	cfg.SetMaxSafety(types.SafetyLevelSafe)
+	require.NoError(t, cfg.Save())
+
+	loaded, err := Load()
+	require.NoError(t, err)
+	assert.Empty(t, loaded.GetSelectedTargets(), "default profile is active after load")
+	require.True(t, loaded.HasProfile("daily"))
+	loaded.UseProfile("daily")
+	assert.Equal(t, []string{"system-cache"}, loaded.GetSelectedTargets())
+	assert.Equal(t, types.SafetyLevelSafe, loaded.GetMaxSafety())
+}
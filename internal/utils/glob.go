@@ -0,0 +1,261 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+)
+
+// GlobMaxDepth limits how many directory levels a single `**` segment may span.
+const GlobMaxDepth = 12
+
+// GlobPaths expands ~ and returns the paths matching pattern. In addition to
+// filepath.Glob syntax, a `**` path segment matches zero or more directories.
+func GlobPaths(pattern string) ([]string, error) {
+	expanded := ExpandPath(pattern)
+	if !hasDoubleStar(expanded) {
+		return filepath.Glob(expanded)
+	}
+
+	segments := splitPattern(expanded)
+	for _, seg := range segments {
+		if _, err := filepath.Match(seg, ""); err != nil {
+			return nil, err
+		}
+	}
+
+	root := "."
+	if filepath.IsAbs(expanded) {
+		root = string(filepath.Separator)
+	}
+
+	g := &globber{
+		seen:    make(map[string]bool),
+		visited: make(map[dirVisit]bool),
+	}
+	g.walk(root, segments, 0)
+
+	sort.Strings(g.matches)
+	return g.matches, nil
+}
+
+// MatchPath reports whether path matches pattern without touching the
+// filesystem. A `**` segment matches zero or more directories.
+func MatchPath(pattern, path string) bool {
+	return matchSegments(splitPattern(pattern), splitPattern(path))
+}
+
+// MatchPathOrAncestor reports whether path, or any directory containing it,
+// matches pattern.
+func MatchPathOrAncestor(pattern, path string) bool {
+	if path == "" {
+		return false
+	}
+	prefix := strings.TrimSuffix(literalPrefix(pattern), "/")
+	if prefix != "" && path != prefix && !strings.HasPrefix(path, prefix+"/") {
+		return false
+	}
+
+	patSegs := splitPattern(pattern)
+	pathSegs := splitPattern(path)
+	for i := len(pathSegs); i > 0; i-- {
+		if matchSegments(patSegs, pathSegs[:i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// PruneNested removes duplicates and any path that lies inside another path
+// of the list, so overlapping matches are not counted twice.
+func PruneNested(paths []string) []string {
+	if len(paths) < 2 {
+		return paths
+	}
+
+	sorted := make([]string, len(paths))
+	copy(sorted, paths)
+	sort.Strings(sorted)
+
+	// Sorting puts every ancestor before its descendants; siblings such as
+	// "foo.bak" may still sort between "foo" and "foo/sub", so each path is
+	// checked against all kept ancestors rather than the last kept path.
+	kept := make(map[string]struct{}, len(sorted))
+	result := sorted[:0]
+	for _, p := range sorted {
+		if hasKeptAncestor(kept, filepath.Clean(p)) {
+			continue
+		}
+		result = append(result, p)
+		kept[filepath.Clean(p)] = struct{}{}
+	}
+	return result
+}
+
+// hasKeptAncestor reports whether path or any directory above it is in kept.
+func hasKeptAncestor(kept map[string]struct{}, path string) bool {
+	for {
+		if _, ok := kept[path]; ok {
+			return true
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return false
+		}
+		path = parent
+	}
+}
+
+type dirVisit struct {
+	dev, ino uint64
+	segment  int
+}
+
+type globber struct {
+	matches []string
+	seen    map[string]bool
+	visited map[dirVisit]bool
+}
+
+func (g *globber) add(path string) {
+	if !g.seen[path] {
+		g.seen[path] = true
+		g.matches = append(g.matches, path)
+	}
+}
+
+// walk matches segs against the tree below dir. depth counts the directories
+// consumed by the current `**` segment.
+func (g *globber) walk(dir string, segs []string, depth int) {
+	if len(segs) == 0 {
+		g.add(dir)
+		return
+	}
+
+	seg := segs[0]
+	switch {
+	case seg == "":
+		g.walk(dir, segs[1:], 0)
+
+	case seg == "**":
+		// Zero directories: continue with the remaining segments here.
+		g.walk(dir, segs[1:], 0)
+		if depth >= GlobMaxDepth || !g.enterDir(dir, len(segs)) {
+			return
+		}
+		entries, err := osReadDir(dir)
+		if err != nil {
+			return
+		}
+		for _, entry := range entries {
+			child := filepath.Join(dir, entry.Name())
+			if isDirFollow(child, entry) {
+				g.walk(child, segs, depth+1)
+			} else if len(segs) == 1 {
+				g.add(child)
+			}
+		}
+
+	case !strings.ContainsAny(seg, "*?[\\"):
+		child := filepath.Join(dir, seg)
+		if _, err := os.Lstat(child); err == nil {
+			g.walk(child, segs[1:], 0)
+		}
+
+	default:
+		entries, err := osReadDir(dir)
+		if err != nil {
+			return
+		}
+		for _, entry := range entries {
+			if ok, _ := filepath.Match(seg, entry.Name()); ok {
+				g.walk(filepath.Join(dir, entry.Name()), segs[1:], 0)
+			}
+		}
+	}
+}
+
+// enterDir records dir as visited for the given remaining-segment count and
+// reports false if it was already visited, which breaks symlink loops.
+func (g *globber) enterDir(dir string, remaining int) bool {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return false
+	}
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return true
+	}
+	key := dirVisit{dev: uint64(st.Dev), ino: st.Ino, segment: remaining}
+	if g.visited[key] {
+		return false
+	}
+	g.visited[key] = true
+	return true
+}
+
+func isDirFollow(path string, entry os.DirEntry) bool {
+	if entry.IsDir() {
+		return true
+	}
+	if entry.Type()&os.ModeSymlink == 0 {
+		return false
+	}
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+func matchSegments(pat, parts []string) bool {
+	for len(pat) > 0 {
+		if pat[0] == "**" {
+			rest := pat[1:]
+			for i := 0; i <= len(parts); i++ {
+				if matchSegments(rest, parts[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(parts) == 0 {
+			return false
+		}
+		if ok, err := filepath.Match(pat[0], parts[0]); err != nil || !ok {
+			return false
+		}
+		pat, parts = pat[1:], parts[1:]
+	}
+	return len(parts) == 0
+}
+
+// literalPrefix returns the leading part of pattern that contains no glob
+// metacharacters, cut at a directory boundary.
+func literalPrefix(pattern string) string {
+	idx := strings.IndexAny(pattern, "*?[\\")
+	if idx < 0 {
+		return pattern
+	}
+	cut := strings.LastIndex(pattern[:idx], "/")
+	if cut < 0 {
+		return ""
+	}
+	return pattern[:cut+1]
+}
+
+func hasDoubleStar(pattern string) bool {
+	for _, seg := range splitPattern(pattern) {
+		if seg == "**" {
+			return true
+		}
+	}
+	return false
+}
+
+func splitPattern(pattern string) []string {
+	cleaned := filepath.Clean(pattern)
+	if cleaned == "/" {
+		return []string{""}
+	}
+	return strings.Split(cleaned, "/")
+}
@@ -0,0 +1,129 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func makeTree(t *testing.T, root string, files ...string) {
+	t.Helper()
+	for _, f := range files {
+		path := filepath.Join(root, f)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte("x"), 0o644))
+	}
+}
+
+func TestGlobPaths_DoubleStarMatchesNestedDirs(t *testing.T) {
+	root := t.TempDir()
+	makeTree(t, root,
+		"Support/AppA/Cache/a.db",
+		"Support/AppB/deep/nested/Cache/b.db",
+		"Support/AppC/Data/c.db",
+	)
+
+	paths, err := GlobPaths(filepath.Join(root, "Support", "*", "**", "Cache"))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		filepath.Join(root, "Support/AppA/Cache"),
+		filepath.Join(root, "Support/AppB/deep/nested/Cache"),
+	}, paths)
+}
+
+func TestGlobPaths_DoubleStarMatchesZeroDirs(t *testing.T) {
+	root := t.TempDir()
+	makeTree(t, root, "a/Cache/x")
+
+	paths, err := GlobPaths(filepath.Join(root, "a", "**", "Cache"))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{filepath.Join(root, "a/Cache")}, paths)
+}
+
+func TestGlobPaths_TrailingDoubleStarIncludesFiles(t *testing.T) {
+	root := t.TempDir()
+	makeTree(t, root, "a/b/file.txt")
+
+	paths, err := GlobPaths(filepath.Join(root, "a", "**"))
+	require.NoError(t, err)
+
+	assert.Contains(t, paths, filepath.Join(root, "a"))
+	assert.Contains(t, paths, filepath.Join(root, "a/b"))
+	assert.Contains(t, paths, filepath.Join(root, "a/b/file.txt"))
+}
+
+func TestGlobPaths_DoubleStarRespectsDepthLimit(t *testing.T) {
+	root := t.TempDir()
+	deep := strings.Repeat("d/", GlobMaxDepth+2) + "Cache/x"
+	makeTree(t, root, "Cache/x", deep)
+
+	paths, err := GlobPaths(filepath.Join(root, "**", "Cache"))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{filepath.Join(root, "Cache")}, paths)
+}
+
+func TestGlobPaths_DoubleStarSurvivesSymlinkLoop(t *testing.T) {
+	root := t.TempDir()
+	makeTree(t, root, "a/Cache/x")
+	require.NoError(t, os.Symlink(root, filepath.Join(root, "a", "loop")))
+
+	paths, err := GlobPaths(filepath.Join(root, "**", "Cache"))
+	require.NoError(t, err)
+
+	assert.Contains(t, paths, filepath.Join(root, "a/Cache"))
+	assert.Less(t, len(paths), 3, "symlink loop should not be walked repeatedly")
+}
+
+func TestGlobPaths_DoubleStarBadPattern(t *testing.T) {
+	_, err := GlobPaths("/tmp/**/[")
+
+	assert.Error(t, err)
+}
+
+func TestMatchPath(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"/a/*/c", "/a/b/c", true},
+		{"/a/*/c", "/a/b/x/c", false},
+		{"/a/**/c", "/a/c", true},
+		{"/a/**/c", "/a/b/x/c", true},
+		{"/a/**", "/a", true},
+		{"/a/**", "/a/b/c", true},
+		{"/a/**/c", "/b/c", false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, MatchPath(tt.pattern, tt.path), "%s ~ %s", tt.pattern, tt.path)
+	}
+}
+
+func TestMatchPathOrAncestor(t *testing.T) {
+	assert.True(t, MatchPathOrAncestor("/a/*/Cache", "/a/b/Cache/file"))
+	assert.True(t, MatchPathOrAncestor("/a/**/Cache", "/a/b/c/Cache"))
+	assert.True(t, MatchPathOrAncestor("/a/App", "/a/App/sub"))
+	assert.False(t, MatchPathOrAncestor("/a/App", "/a/AppOther"))
+	assert.False(t, MatchPathOrAncestor("/a/**/Cache", "/a/b"))
+	assert.False(t, MatchPathOrAncestor("/a/App", ""))
+}
+
+func TestPruneNested(t *testing.T) {
+	paths := PruneNested([]string{"/a/b/c", "/a/b", "/a/bc", "/a/b", "/x"})
+
+	assert.Equal(t, []string{"/a/b", "/a/bc", "/x"}, paths)
+}
+
+func TestPruneNested_SiblingSortsBetweenParentAndChild(t *testing.T) {
+	paths := PruneNested([]string{"/c/foo", "/c/foo/sub", "/c/foo.bak", "/c/foo-old", "/c/foo bar/x"})
+
+	assert.Equal(t, []string{"/c/foo", "/c/foo bar/x", "/c/foo-old", "/c/foo.bak"}, paths)
+}
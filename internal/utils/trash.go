@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -40,7 +41,10 @@ func BatchTrash(items []types.CleanableItem, opts types.BatchTrashOptions) *type
 	}
 
 	paths := make([]string, 0, len(items))
+	// An item with KeepPaths is trashed as the entries around them, so one
+	// item may map to several trashed paths.
 	pathToItem := make(map[string]types.CleanableItem, len(items))
+	pending := make(map[string]int, len(items))
 
 	for _, item := range items {
 		if opts.Filter != nil && opts.Filter(item) {
@@ -55,8 +59,25 @@ func BatchTrash(items []types.CleanableItem, opts types.BatchTrashOptions) *type
 			}
 		}
 
-		paths = append(paths, item.Path)
-		pathToItem[item.Path] = item
+		parts := []string{item.Path}
+		if len(item.KeepPaths) > 0 {
+			around, err := PathsAround(item.Path, item.KeepPaths)
+			if err != nil {
+				result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", item.Path, err))
+				continue
+			}
+			if len(around) == 0 {
+				result.FreedSpace += item.Size
+				result.CleanedItems++
+				continue
+			}
+			parts = around
+		}
+		for _, p := range parts {
+			paths = append(paths, p)
+			pathToItem[p] = item
+		}
+		pending[item.Path] = len(parts)
 	}
 
 	if len(paths) == 0 {
@@ -65,20 +86,65 @@ func BatchTrash(items []types.CleanableItem, opts types.BatchTrashOptions) *type
 
 	batchResult := MoveToTrashBatch(paths)
 
-	for _, p := range batchResult.Succeeded {
+	failed := make(map[string]bool)
+	for p, err := range batchResult.Failed {
 		item := pathToItem[p]
-		result.FreedSpace += item.Size
-		result.CleanedItems++
+		if failed[item.Path] {
+			continue
+		}
+		failed[item.Path] = true
+		result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", item.Path, err))
 	}
 
-	for p, err := range batchResult.Failed {
+	for _, p := range batchResult.Succeeded {
 		item := pathToItem[p]
-		result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", item.Path, err))
+		pending[item.Path]--
+		if pending[item.Path] == 0 && !failed[item.Path] {
+			result.FreedSpace += item.Size
+			result.CleanedItems++
+		}
 	}
 
 	return result
 }
 
+// PathsAround lists what to remove from dir so that every path in keep
+// survives: entries holding no kept path whole, and the contents of those
+// that do, recursively.
+func PathsAround(dir string, keep []string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, e := range entries {
+		path := filepath.Join(dir, e.Name())
+		kept := false
+		var inside []string
+		for _, k := range keep {
+			if k == path {
+				kept = true
+				break
+			}
+			if strings.HasPrefix(k, path+string(filepath.Separator)) {
+				inside = append(inside, k)
+			}
+		}
+		switch {
+		case kept:
+		case len(inside) > 0 && e.IsDir():
+			sub, err := PathsAround(path, inside)
+			if err != nil {
+				return nil, err
+			}
+			paths = append(paths, sub...)
+		default:
+			paths = append(paths, path)
+		}
+	}
+	return paths, nil
+}
+
 // MoveToTrash moves a file or directory to macOS Trash using Finder.
 // It is a variable to allow mocking in tests.
 var MoveToTrash = moveToTrashImpl
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -441,3 +442,44 @@ func TestMoveToTrashBatchImpl_PartialBatchSuccess_FileAlreadyDeleted(t *testing.
 func writeTestFile(path string) error {
 	return os.WriteFile(path, []byte("test"), 0o644)
 }
+
+func TestPathsAround(t *testing.T) {
+	dir := t.TempDir()
+	for _, p := range []string{"a/keep/x", "a/b", "c", "d/e/keep.db"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, filepath.Dir(p)), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, p), nil, 0o644))
+	}
+
+	paths, err := PathsAround(dir, []string{filepath.Join(dir, "a", "keep"), filepath.Join(dir, "d", "e", "keep.db")})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "a", "b"), filepath.Join(dir, "c")}, paths)
+}
+
+func TestBatchTrash_KeepPaths_CountsItemOnceAllPartsSucceed(t *testing.T) {
+	original := MoveToTrashBatch
+	defer func() { MoveToTrashBatch = original }()
+	dir := t.TempDir()
+	for _, name := range []string{"keep", "one", "two"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0o644))
+	}
+	MoveToTrashBatch = func(paths []string) TrashBatchResult {
+		result := TrashBatchResult{Failed: make(map[string]error)}
+		for _, p := range paths {
+			if filepath.Base(p) == "two" {
+				result.Failed[p] = fmt.Errorf("busy")
+			} else {
+				result.Succeeded = append(result.Succeeded, p)
+			}
+		}
+		return result
+	}
+	item := types.CleanableItem{Path: dir, Size: 10, IsDirectory: true, KeepPaths: []string{filepath.Join(dir, "keep")}}
+
+	result := BatchTrash([]types.CleanableItem{item}, types.BatchTrashOptions{})
+
+	assert.Zero(t, result.CleanedItems)
+	assert.Zero(t, result.FreedSpace)
+	require.Len(t, result.Errors, 1)
+	assert.Equal(t, dir+": busy", result.Errors[0])
+}
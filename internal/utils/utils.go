@@ -18,6 +18,7 @@ import (
 var (
 	osUserHomeDir = os.UserHomeDir
 	osReadDir     = os.ReadDir
+	osLookupEnv   = os.LookupEnv
 	execCommand   = exec.Command
 	execLookPath  = exec.LookPath
 )
@@ -46,6 +47,59 @@ func ExpandPath(path string) string {
 	return path
 }
 
+var (
+	envCacheMu sync.Mutex
+	envCache   = make(map[string]envValue)
+)
+
+type envValue struct {
+	value string
+	ok    bool
+}
+
+// lookupEnv returns a non-empty environment variable, caching the result for the rest of the run.
+func lookupEnv(name string) (string, bool) {
+	envCacheMu.Lock()
+	defer envCacheMu.Unlock()
+
+	if v, cached := envCache[name]; cached {
+		return v.value, v.ok
+	}
+	value, ok := osLookupEnv(name)
+	ok = ok && value != ""
+	envCache[name] = envValue{value: value, ok: ok}
+	return value, ok
+}
+
+// ResetEnvCache clears cached environment lookups.
+func ResetEnvCache() {
+	envCacheMu.Lock()
+	defer envCacheMu.Unlock()
+	envCache = make(map[string]envValue)
+}
+
+// ExpandVars expands $VAR, ${VAR} and ${VAR:-default} references in a
+// configured path pattern. It is meant for patterns from config files, not
+// for paths on disk, whose names may contain "$". References to unset
+// variables without a default are left as-is, so the result never points
+// somewhere unintended (like "/" for "$UNSET/*").
+func ExpandVars(pattern string) string {
+	if !strings.Contains(pattern, "$") {
+		return pattern
+	}
+	return os.Expand(pattern, func(ref string) string {
+		name, def, hasDefault := strings.Cut(ref, ":-")
+		if value, ok := lookupEnv(name); ok {
+			return value
+		}
+		if hasDefault {
+			return def
+		}
+		logger.Debug("path variable not set", "name", name)
+		return "${" + ref + "}"
+	})
+}
+
 func FormatSize(bytes int64) string {
 	const (
 		KB = 1024
@@ -269,11 +323,6 @@ func GetFileSize(path string) (int64, error) {
 	return info.Size(), nil
 }
 
-func GlobPaths(pattern string) ([]string, error) {
-	expanded := ExpandPath(pattern)
-	return filepath.Glob(expanded)
-}
-
 // CheckFullDiskAccess checks if the app has Full Disk Access permission
 // by attempting to read the Trash directory
 func CheckFullDiskAccess() bool {
@@ -327,3 +376,17 @@ func OpenInFinder(path string) error {
 	}
 	return execCommand("open", "-R", expanded).Run()
 }
+
+// LastUsedTime returns the later of a file's access and modification times.
+// Access times may be stale on volumes mounted with noatime, so the
+// modification time is the floor.
+func LastUsedTime(path string, info os.FileInfo) time.Time {
+	used := info.ModTime()
+	var stat unix.Stat_t
+	if err := unix.Lstat(path, &stat); err == nil {
+		if atime := time.Unix(stat.Atim.Unix()); atime.After(used) {
+			used = atime
+		}
+	}
+	return used
+}
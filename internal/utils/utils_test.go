@@ -42,6 +42,49 @@ func TestExpandPath_RelativePath(t *testing.T) {
 	assert.Equal(t, "relative/path", result)
 }
 
+func TestExpandPath_LeavesDollarSigns(t *testing.T) {
+	t.Setenv("MCG_TEST_CACHE", "/custom/cache")
+
+	assert.Equal(t, "/tmp/$MCG_TEST_CACHE/a$b", ExpandPath("/tmp/$MCG_TEST_CACHE/a$b"))
+}
+
+func TestExpandVars(t *testing.T) {
+	ResetEnvCache()
+	t.Cleanup(ResetEnvCache)
+	t.Setenv("MCG_TEST_CACHE", "/custom/cache")
+
+	assert.Equal(t, "/custom/cache/go-build/*", ExpandVars("$MCG_TEST_CACHE/go-build/*"))
+	assert.Equal(t, "/custom/cache/x", ExpandVars("${MCG_TEST_CACHE}/x"))
+}
+
+func TestExpandVars_Default(t *testing.T) {
+	ResetEnvCache()
+	t.Cleanup(ResetEnvCache)
+	t.Setenv("MCG_TEST_EMPTY", "")
+
+	assert.Equal(t, "~/.cache/uv/*", ExpandVars("${MCG_TEST_UNSET_VAR:-~/.cache}/uv/*"))
+	assert.Equal(t, "/fallback/x", ExpandVars("${MCG_TEST_EMPTY:-/fallback}/x"), "empty variable uses default")
+}
+
+func TestExpandVars_UnsetStaysLiteral(t *testing.T) {
+	ResetEnvCache()
+	t.Cleanup(ResetEnvCache)
+
+	result := ExpandVars("$MCG_TEST_UNSET_VAR/*")
+
+	assert.Equal(t, "${MCG_TEST_UNSET_VAR}/*", result, "unset variable must not collapse to /*")
+}
+
+func TestExpandVars_CachedForRun(t *testing.T) {
+	ResetEnvCache()
+	t.Cleanup(ResetEnvCache)
+	t.Setenv("MCG_TEST_CACHE", "/first")
+
+	assert.Equal(t, "/first", ExpandVars("$MCG_TEST_CACHE"))
+	t.Setenv("MCG_TEST_CACHE", "/second")
+	assert.Equal(t, "/first", ExpandVars("$MCG_TEST_CACHE"))
+}
+
 func TestFormatSize_Zero(t *testing.T) {
 	assert.Equal(t, "0 B", FormatSize(0))
 }
@@ -0,0 +1,96 @@
+package utils
+
+import (
+	"cmp"
+	"strings"
+	"unicode"
+)
+
+// CompareVersions compares two version strings such as "2024.1", "8.5-rc1"
+// or "17.2 (21C62)". Numeric parts compare by value, other parts lexically.
+// A release sorts after its pre-releases ("1.2" > "1.2-rc1") and before any
+// longer numeric version ("1.2" < "1.2.1"). It returns -1, 0 or 1.
+func CompareVersions(a, b string) int {
+	pa, pb := versionParts(a), versionParts(b)
+	for i := 0; i < len(pa) && i < len(pb); i++ {
+		if c := compareVersionPart(pa[i], pb[i]); c != 0 {
+			return c
+		}
+	}
+	switch {
+	case len(pa) == len(pb):
+		return 0
+	case len(pa) > len(pb):
+		return trailingOrder(pa[len(pb)])
+	default:
+		return -trailingOrder(pb[len(pa)])
+	}
+}
+
+// trailingOrder decides how a version with an extra part compares to the
+// same version without it: an extra number is newer, a suffix is a pre-release.
+func trailingOrder(extra string) int {
+	if isNumeric(extra) {
+		return 1
+	}
+	return -1
+}
+
+func compareVersionPart(a, b string) int {
+	an, bn := isNumeric(a), isNumeric(b)
+	switch {
+	case an && bn:
+		// Compare by magnitude without parsing, so long numbers cannot overflow.
+		a, b = strings.TrimLeft(a, "0"), strings.TrimLeft(b, "0")
+		if len(a) != len(b) {
+			return cmp.Compare(len(a), len(b))
+		}
+		return strings.Compare(a, b)
+	case an:
+		return 1
+	case bn:
+		return -1
+	}
+	return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+}
+
+// versionParts splits a version at separators and at digit/letter boundaries,
+// ignoring a leading "v".
+func versionParts(v string) []string {
+	v = strings.TrimPrefix(strings.TrimPrefix(v, "v"), "V")
+	var parts []string
+	var cur strings.Builder
+	curDigit := false
+	flush := func() {
+		if cur.Len() > 0 {
+			parts = append(parts, cur.String())
+			cur.Reset()
+		}
+	}
+	for _, r := range v {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			flush()
+			continue
+		}
+		digit := unicode.IsDigit(r)
+		if cur.Len() > 0 && digit != curDigit {
+			flush()
+		}
+		curDigit = digit
+		cur.WriteRune(r)
+	}
+	flush()
+	return parts
+}
+
+func isNumeric(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
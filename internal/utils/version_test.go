@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.2.3", "1.2.3", 0},
+		{"1.10", "1.9", 1},
+		{"2023.3", "2024.1", -1},
+		{"v1.2", "1.2", 0},
+		{"1.2.1", "1.2", 1},
+		{"1.2", "1.2-rc1", 1},
+		{"8.5-rc-2", "8.5-rc-1", 1},
+		{"17.2 (21C62)", "17.10 (21A1)", -1},
+		{"007", "7", 0},
+		{"99999999999999999999999", "1", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+"_vs_"+tt.b, func(t *testing.T) {
+			assert.Equal(t, tt.want, CompareVersions(tt.a, tt.b))
+			assert.Equal(t, -tt.want, CompareVersions(tt.b, tt.a))
+		})
+	}
+}
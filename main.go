@@ -13,7 +13,9 @@ import (
 	"github.com/2ykwang/mac-cleanup-go/internal/config"
 	"github.com/2ykwang/mac-cleanup-go/internal/logger"
 	"github.com/2ykwang/mac-cleanup-go/internal/styles"
+	"github.com/2ykwang/mac-cleanup-go/internal/target"
 	"github.com/2ykwang/mac-cleanup-go/internal/tui"
+	"github.com/2ykwang/mac-cleanup-go/internal/types"
 	"github.com/2ykwang/mac-cleanup-go/internal/userconfig"
 	pkgversion "github.com/2ykwang/mac-cleanup-go/internal/version"
 )
@@ -30,6 +32,9 @@ func main() {
 	selectTargets := flag.Bool("select", false, "Select cleanup targets")
 	doClean := flag.Bool("clean", false, "Clean selected targets")
 	dryRun := flag.Bool("dry-run", false, "Show report without deleting (requires --clean)")
+	profile := flag.String("profile", "", "Use a named profile with --select (creates it) or --clean")
+	validateOnly := flag.Bool("validate-config", false, "Validate targets (or the target files given as arguments) and exit")
+	reindex := flag.Bool("reindex", false, "Rebuild the project cache index instead of reusing it")
 	flag.Parse()
 
 	// Initialize logger: --debug flag or DEBUG env var
@@ -54,12 +59,23 @@ func main() {
 		return
 	}
 
-	cfg, err := config.LoadEmbedded()
+	if *validateOnly {
+		os.Exit(runValidateConfig(flag.Args()))
+	}
+
+	cfg, err := config.Load()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "failed to load config: %v\n", err)
 		os.Exit(1)
 	}
 
+	if *reindex {
+		if err := target.ResetProjectCacheIndex(); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to reset project cache index: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
 	if *selectTargets && *doClean {
 		fmt.Fprintln(os.Stderr, "error: --select cannot be combined with --clean")
 		os.Exit(1)
@@ -68,10 +84,14 @@ func main() {
 		fmt.Fprintln(os.Stderr, "error: --dry-run requires --clean")
 		os.Exit(1)
 	}
+	if *profile != "" && !*selectTargets && !*doClean {
+		fmt.Fprintln(os.Stderr, "error: --profile requires --select or --clean")
+		os.Exit(1)
+	}
 
 	if *selectTargets {
 		p := tea.NewProgram(
-			tui.NewConfigModel(cfg),
+			tui.NewConfigModelForProfile(cfg, *profile),
 		)
 
 		if _, err := p.Run(); err != nil {
@@ -90,6 +110,13 @@ func main() {
 			fmt.Fprintf(os.Stderr, "failed to load user config: %v\n", err)
 			os.Exit(1)
 		}
+		if *profile != "" {
+			if !userCfg.HasProfile(*profile) {
+				fmt.Fprintf(os.Stderr, "unknown profile: %s. run `mac-cleanup --select --profile %s` to create it.\n", *profile, *profile)
+				os.Exit(1)
+			}
+			userCfg.UseProfile(*profile)
+		}
 
 		runner, err := cli.NewRunner(cfg, userCfg)
 		if err != nil {
@@ -130,3 +157,49 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// runValidateConfig validates the merged target config and prints every problem found.
+// When files are given, they are checked against the built-in targets instead of targets.d.
+func runValidateConfig(files []string) int {
+	var (
+		cfg *types.Config
+		err error
+	)
+	if len(files) > 0 {
+		cfg, err = config.LoadFiles(files)
+	} else {
+		cfg, err = config.Load()
+	}
+
+	if err != nil {
+		var problems config.ValidationErrors
+		if errors.As(err, &problems) {
+			fmt.Fprintf(os.Stderr, "config invalid: %d problem(s)\n", len(problems))
+			for _, p := range problems {
+				fmt.Fprintln(os.Stderr, "  - "+p.Error())
+			}
+		} else {
+			fmt.Fprintf(os.Stderr, "failed to load config: %v\n", err)
+		}
+		return 1
+	}
+
+	fmt.Printf("config OK: %d categories\n", len(cfg.Categories))
+
+	if registry, err := target.DefaultRegistry(cfg); err == nil {
+		printOverlaps(registry.Overlaps())
+	}
+	return 0
+}
+
+// printOverlaps lists categories whose paths overlap on this machine.
+// Overlaps are not errors: each path is counted only by its owner.
+func printOverlaps(overlaps []target.Overlap) {
+	if len(overlaps) == 0 {
+		return
+	}
+	fmt.Printf("overlapping targets: %d (each path is counted once, by the more specific target)\n", len(overlaps))
+	for _, o := range overlaps {
+		fmt.Printf("  - %s overlaps %s: %d path(s), e.g. %s\n", o.Owner, o.Other, len(o.Paths), o.Paths[0])
+	}
+}